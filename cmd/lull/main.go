@@ -3,15 +3,21 @@ package main
 import (
 	"flag"
 	"fmt"
+	"github.com/mbpolan/lull/internal/config"
 	"github.com/mbpolan/lull/internal/events"
+	"github.com/mbpolan/lull/internal/formats"
+	"github.com/mbpolan/lull/internal/keys"
 	"github.com/mbpolan/lull/internal/logger"
 	"github.com/mbpolan/lull/internal/parsers"
+	"github.com/mbpolan/lull/internal/secrets"
 	"github.com/mbpolan/lull/internal/state"
 	"github.com/mbpolan/lull/internal/system"
+	"github.com/mbpolan/lull/internal/theme"
 	"github.com/mbpolan/lull/internal/ui"
 	"github.com/mbpolan/lull/internal/util"
 	"github.com/rivo/tview"
 	"os"
+	"path/filepath"
 	"time"
 )
 
@@ -22,8 +28,22 @@ var (
 )
 
 func main() {
+	var configPath string
+	var printConfig bool
 	var logLevel string
+	var secretsCommand string
+	var workspaceDir string
+	var workspaceName string
+	var autosave time.Duration
+	var watch time.Duration
+	flag.StringVar(&configPath, "config", "", "path to the configuration file (default is config.yaml in the config directory)")
+	flag.BoolVar(&printConfig, "print-config", false, "print the effective configuration and exit")
 	flag.StringVar(&logLevel, "log-level", "error", "sets the verbosity for logging (debug, info, error)")
+	flag.StringVar(&secretsCommand, "secrets-command", "", "command whose output is the passphrase for stored secrets (ie: \"pass show lull\")")
+	flag.StringVar(&workspaceDir, "workspace", "", "directory to store the collection in as one file per request, suitable for version control")
+	flag.StringVar(&workspaceName, "workspace-name", "", "name of the workspace to open, which is created if it does not exist")
+	flag.DurationVar(&autosave, "autosave", 5*time.Second, "save changes after they have been idle for this long (0 to only save on exit)")
+	flag.DurationVar(&watch, "watch", 2*time.Second, "how often to check for changes made to the collection outside of lull, such as by git (0 to disable)")
 	flag.Parse()
 
 	// initialize supporting modules
@@ -32,7 +52,56 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err := logger.Setup(logLevel); err != nil {
+	cfgDir, err := system.GetConfigDir()
+	if err != nil {
+		fmt.Printf("Could not determine config directory: %s\n", err)
+		os.Exit(1)
+	}
+
+	if configPath == "" {
+		configPath = filepath.Join(cfgDir, config.FileName)
+	}
+
+	if err := config.Setup(system.ExpandPath(configPath)); err != nil {
+		fmt.Printf("Could not load configuration: %s\n", err)
+		os.Exit(1)
+	}
+
+	// settings given on the command line take precedence over the configuration file
+	cfg := config.Get()
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "log-level":
+			cfg.LogLevel = logLevel
+		case "autosave":
+			cfg.Autosave.Duration = autosave
+		case "watch":
+			cfg.Watch.Duration = watch
+		}
+	})
+
+	if err := keys.Setup(cfg.Keys); err != nil {
+		fmt.Printf("Could not set up key bindings: %s\n", err)
+		os.Exit(1)
+	}
+
+	if err := theme.Setup(cfg.Theme, cfg.Themes); err != nil {
+		fmt.Printf("Could not set up theme: %s\n", err)
+		os.Exit(1)
+	}
+
+	if printConfig {
+		// show the keys bound to every action, not only those that were changed
+		cfg.Keys = keys.Get().Bindings()
+		if err := cfg.Write(os.Stdout); err != nil {
+			fmt.Printf("Could not print configuration: %s\n", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	if err := logger.Setup(cfg.LogLevel); err != nil {
 		fmt.Printf("Could not initialize logging: %s\n", err)
 		os.Exit(1)
 	}
@@ -43,38 +112,92 @@ func main() {
 	// populate build information
 	buildMeta := util.NewBuildMeta(version, commit, date)
 
-	// determine the user's home directory to save app state file in
-	stateSavePath, err := os.UserHomeDir()
+	// open a workspace directory if one is given, otherwise a named workspace or the one that was last used
+	ws := newWorkspaces(cfgDir, newPassphraseFunc(secretsCommand))
+	var sess *session
+
+	if workspaceDir != "" {
+		workspaceDir, err = filepath.Abs(system.ExpandPath(workspaceDir))
+		if err == nil {
+			sess, err = ws.openDir(workspaceDir, filepath.Base(workspaceDir))
+			ws.current = workspaceDir
+		}
+	} else {
+		if workspaceName == "" {
+			workspaceName = ws.registry.Last()
+		}
+
+		sess, err = ws.openNamed(workspaceName)
+		ws.current = workspaceName
+	}
+
 	if err != nil {
-		stateSavePath = "."
+		fmt.Printf("Could not open workspace: %s\n", err)
+		os.Exit(1)
 	}
 
-	stateSavePath = fmt.Sprintf("%s/.lull", stateSavePath)
+	if !confirmUnlocked(sess.storage) {
+		os.Exit(1)
+	}
 
-	// attempt to read existing app state from file
-	var st *state.AppState
-	initialSave := true
-	data, err := os.ReadFile(stateSavePath)
-	if err != nil {
-		logger.Infof("initializing new app state due to error: %s", err)
-		st = state.NewAppState()
-	} else {
-		st, err = state.DeserializeAppState(data)
-		if err != nil {
-			st = state.NewAppState()
-		} else {
-			initialSave = false
+	config.UseWorkspace(sess.config)
+
+	if workspaceDir == "" {
+		if err := ws.registry.SetLast(workspaceName); err != nil {
+			logger.Infof("could not record last used workspace: %s", err)
 		}
 	}
 
 	// create a state manager and flag the state as dirty to force an initial save if needed
-	stateManager := state.NewStateManager(st, stateSavePath)
-	if initialSave {
+	stateManager := state.NewStateManager(sess.state, sess.storage)
+	stateManager.SetSecretStore(sess.secrets)
+	if sess.initialSave {
 		stateManager.SetDirty()
 	}
 
+	if err := stateManager.Lock(); err != nil {
+		logger.Infof("could not lock workspace: %s", err)
+	}
+
+	ws.manager = stateManager
+	ws.secrets = sess.secrets
+
 	app := tview.NewApplication()
 	root := ui.NewRoot(app, stateManager, buildMeta)
+	root.SetWorkspaceSwitcher(ws)
+
+	// periodically save changes so that they are not lost if the app exits unexpectedly
+	if cfg.Autosave.Duration > 0 {
+		stateManager.StartAutosave(cfg.Autosave.Duration, func(save func()) {
+			app.QueueUpdate(func() {
+				// groups linked to directories of .http files are saved along with the rest of the app state
+				if err := saveHTTPDirectories(stateManager.Get().Collection); err != nil {
+					logger.Errorf("failed to autosave .http files: %s", err)
+				}
+
+				// suspend the ui in case the passphrase for secrets needs to be entered on the terminal
+				if ws.NeedsPassphrase() {
+					app.Suspend(save)
+				} else {
+					save()
+				}
+			})
+		})
+	}
+
+	// pick up changes made to the stored collection by other programs, such as when pulling changes with git
+	if cfg.Watch.Duration > 0 {
+		stateManager.StartWatching(cfg.Watch.Duration, func(reload func()) {
+			app.QueueUpdateDraw(func() {
+				// suspend the ui in case the passphrase for secrets saved elsewhere needs to be entered on the terminal
+				if ws.NeedsPassphraseToReload() {
+					app.Suspend(reload)
+				} else {
+					reload()
+				}
+			})
+		})
+	}
 
 	app.SetRoot(root.Widget(), true)
 	app.SetFocus(root.Widget())
@@ -83,8 +206,37 @@ func main() {
 		panic(err)
 	}
 
-	// save the app state to file
-	if err := stateManager.Shutdown(); err != nil {
+	// write changes to groups linked to directories of .http files
+	report, err := formats.SaveHTTPDirectories(stateManager.Get().Collection)
+	if err != nil {
+		fmt.Printf("Failed to save .http files: %+v\n", err)
+	}
+
+	for _, w := range report.Warnings {
+		fmt.Printf("Warning: %s\n", w)
+	}
+
+	// save the app state to file, settling any changes made by another instance first
+	err = stateManager.Shutdown()
+	if _, ok := err.(*state.ConflictError); ok {
+		if err = resolveConflict(stateManager); err == nil {
+			err = stateManager.Shutdown()
+		}
+	}
+
+	if err != nil {
 		fmt.Printf("Failed to save data: %+v\n", err)
 	}
 }
+
+// newPassphraseFunc returns a function that obtains the passphrase for stored secrets from an external command if one
+// is given, the environment or by prompting on the terminal, in that order.
+func newPassphraseFunc(command string) secrets.PassphraseFunc {
+	if command != "" {
+		return secrets.CommandPassphrase(command)
+	} else if os.Getenv(secrets.PassphraseEnvVar) != "" {
+		return secrets.EnvPassphrase()
+	}
+
+	return secrets.TerminalPassphrase("Passphrase for lull secrets: ")
+}
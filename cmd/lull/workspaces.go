@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"github.com/mbpolan/lull/internal/config"
+	"github.com/mbpolan/lull/internal/formats"
+	"github.com/mbpolan/lull/internal/logger"
+	"github.com/mbpolan/lull/internal/secrets"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/system"
+	"github.com/mbpolan/lull/internal/workspace"
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// session is an opened workspace along with the means to save it.
+type session struct {
+	state       *state.AppState
+	storage     state.Storage
+	secrets     *secrets.Store
+	config      *config.WorkspaceConfig
+	initialSave bool
+}
+
+// workspaces opens named workspaces and workspace directories, and switches between them while the app is running.
+type workspaces struct {
+	registry   *workspace.Registry
+	configDir  string
+	passphrase secrets.PassphraseFunc
+	manager    *state.Manager
+	secrets    *secrets.Store
+	current    string
+	unlocked   bool
+}
+
+// newWorkspaces returns a workspaces instance that keeps named workspaces and secrets under configDir.
+func newWorkspaces(configDir string, passphrase secrets.PassphraseFunc) *workspaces {
+	w := &workspaces{
+		registry:  workspace.NewRegistry(filepath.Join(configDir, "workspaces")),
+		configDir: configDir,
+	}
+
+	// keep track of when the passphrase has been provided, since it won't need to be obtained again after that
+	cached := secrets.CachedPassphrase(passphrase)
+	w.passphrase = func() (string, error) {
+		p, err := cached()
+		w.unlocked = err == nil
+		return p, err
+	}
+
+	return w
+}
+
+// NeedsPassphrase returns true if saving the current workspace requires obtaining the passphrase for its secrets.
+func (w *workspaces) NeedsPassphrase() bool {
+	return !w.unlocked && len(w.manager.Get().Secrets()) > 0
+}
+
+// NeedsPassphraseToReload returns true if reloading the current workspace after it was changed elsewhere requires
+// obtaining the passphrase to read its secrets.
+func (w *workspaces) NeedsPassphraseToReload() bool {
+	return !w.unlocked && w.secrets != nil && w.secrets.Exists()
+}
+
+// Current returns the name of the workspace that is currently open.
+func (w *workspaces) Current() string {
+	return w.current
+}
+
+// List returns the names of all named workspaces.
+func (w *workspaces) List() ([]string, error) {
+	return w.registry.List()
+}
+
+// Switch saves the current workspace and opens the named workspace in its place.
+func (w *workspaces) Switch(name string) error {
+	s, err := w.openNamed(name)
+	if err != nil {
+		return err
+	}
+
+	// write back .http files linked to the current workspace before leaving it
+	if err := saveHTTPDirectories(w.manager.Get().Collection); err != nil {
+		return err
+	}
+
+	if !confirmUnlocked(s.storage) {
+		return errors.New("workspace is in use by another instance of lull")
+	}
+
+	err = w.manager.Replace(s.state, s.storage, s.secrets)
+	if _, ok := err.(*state.ConflictError); ok {
+		// the current workspace was changed elsewhere, so settle that before leaving it
+		if err = resolveConflict(w.manager); err == nil {
+			err = w.manager.Replace(s.state, s.storage, s.secrets)
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	w.secrets = s.secrets
+	config.UseWorkspace(s.config)
+	if err := w.manager.Lock(); err != nil {
+		logger.Infof("could not lock workspace: %s", err)
+	}
+
+	// save new workspaces right away so that they can be listed
+	if s.initialSave {
+		w.manager.SetDirty()
+		if err := w.manager.Save(); err != nil {
+			return err
+		}
+	}
+
+	w.current = name
+	return w.registry.SetLast(name)
+}
+
+// openNamed opens the workspace with the given name. The default workspace is stored in the legacy app state file
+// in the user's home directory, while all other workspaces are stored in their own directory.
+func (w *workspaces) openNamed(name string) (*session, error) {
+	if name != workspace.DefaultName {
+		dir, err := w.registry.Dir(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return w.openDir(dir, name)
+	}
+
+	storage := state.NewFileStorage(system.ExpandPath(config.Get().StateFile))
+	s := &session{
+		storage:     storage,
+		secrets:     secrets.NewStore(filepath.Join(w.configDir, "secrets"), w.passphrase),
+		initialSave: true,
+	}
+
+	// attempt to read existing app state from file
+	var err error
+	s.state, err = storage.Load()
+	if os.IsNotExist(err) {
+		logger.Infof("initializing new app state due to error: %s", err)
+		s.state = state.NewAppState()
+	} else if _, ok := err.(*state.NewerVersionError); ok {
+		// never offer to replace a file that this version of lull cannot fully understand
+		return nil, err
+	} else if err != nil {
+		if s.state, err = w.recover(storage, err); err != nil {
+			return nil, err
+		}
+	} else {
+		s.initialSave = false
+	}
+
+	return s, w.prepare(s)
+}
+
+// openDir opens the workspace stored in a directory, creating a new workspace with the given name if the directory
+// does not contain one yet.
+func (w *workspaces) openDir(dir string, name string) (*session, error) {
+	// keep secrets and the layout for each workspace separate so that they don't replace each other, and outside of
+	// the workspace so that they are not shared with others
+	sum := sha256.Sum256([]byte(dir))
+	ws := workspace.NewStorage(dir, filepath.Join(w.configDir, fmt.Sprintf("workspace-%x", sum[:8])))
+	s := &session{
+		storage: ws,
+		secrets: secrets.NewStore(filepath.Join(w.configDir, fmt.Sprintf("secrets-%x", sum[:8])), w.passphrase),
+	}
+
+	// settings in the workspace directory are shared along with the workspace
+	cfg, err := config.LoadWorkspace(filepath.Join(dir, config.WorkspaceFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	s.config = cfg
+
+	if ws.Exists() {
+		st, err := ws.Load()
+		if err != nil {
+			return nil, errors.Wrap(err, "could not load workspace")
+		}
+
+		s.state = st
+	} else {
+		s.state = state.NewAppState()
+		s.state.Collection.Name = name
+		s.initialSave = true
+	}
+
+	return s, w.prepare(s)
+}
+
+// saveHTTPDirectories writes back the .http files linked to groups in the collection, logging any warnings.
+func saveHTTPDirectories(collection *state.CollectionItem) error {
+	report, err := formats.SaveHTTPDirectories(collection)
+	for _, warning := range report.Warnings {
+		logger.Infof("saving .http files: %s", warning)
+	}
+
+	return err
+}
+
+// prepare brings a freshly loaded app state up to date with files and secrets stored outside of it.
+func (w *workspaces) prepare(s *session) error {
+	// refresh groups linked to directories of .http files, since those files may have changed since the last run
+	for _, warning := range formats.ReloadHTTPDirectories(s.state.Collection).Warnings {
+		logger.Infof("reloading .http files: %s", warning)
+	}
+
+	s.state.EnsureDefaultItems()
+
+	// restore secrets that are stored separately from the app state
+	storedSecrets, err := s.secrets.Load()
+	if err != nil {
+		return errors.Wrap(err, "could not load secrets")
+	}
+
+	s.state.ApplySecrets(storedSecrets)
+	return nil
+}
+
+// recover offers to restore the app state from the newest backup when the app state file cannot be read. If there is
+// no backup or the user declines, a new app state is created instead. Either way, the unreadable file is set aside
+// so that it is not overwritten.
+func (w *workspaces) recover(storage *state.FileStorage, cause error) (*state.AppState, error) {
+	fmt.Printf("Could not read %s: %s\n", storage.Path(), cause)
+
+	var st *state.AppState
+	backups := storage.Backups()
+	if len(backups) > 0 && confirm(fmt.Sprintf("Recover from the most recent backup %s?", backups[0])) {
+		var err error
+		if st, err = storage.LoadBackup(backups[0]); err != nil {
+			return nil, errors.Wrap(err, "could not read backup")
+		}
+	} else {
+		st = state.NewAppState()
+	}
+
+	path, err := storage.SetAside()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("The unreadable file was moved to %s\n", path)
+	return st, nil
+}
+
+// confirmUnlocked checks if another running instance of lull is using the storage, and if so, asks whether to use it
+// anyway. It returns true if the storage is not in use or the user chooses to use it.
+func confirmUnlocked(storage state.Storage) bool {
+	shared, ok := storage.(state.SharedStorage)
+	if !ok {
+		return true
+	}
+
+	err := state.NewLock(shared.LockPath()).Check()
+	if _, ok := err.(*state.LockedError); !ok {
+		return true
+	}
+
+	fmt.Printf("This workspace is %s.\n", err)
+	return confirm("Changes made by both instances will conflict. Open it anyway?")
+}
+
+// resolveConflict asks how to resolve changes made to the stored app state by another instance of lull, and saves
+// the result.
+func resolveConflict(manager *state.Manager) error {
+	fmt.Println("The app state was changed by another instance of lull since it was loaded.")
+	fmt.Print("[m]erge changes from both, [r]eload and discard your changes, or [o]verwrite their changes? [M/r/o] ")
+
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	resolution := state.ConflictMerge
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "r", "reload":
+		resolution = state.ConflictReload
+	case "o", "overwrite":
+		resolution = state.ConflictOverwrite
+	}
+
+	return manager.Resolve(resolution)
+}
+
+// confirm asks a yes or no question on the terminal, returning true if the answer is yes.
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
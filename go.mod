@@ -9,7 +9,10 @@ require (
 	github.com/rivo/tview v0.0.0-20221217182043-ccce554c3803
 	github.com/stretchr/testify v1.8.1
 	go.uber.org/zap v1.24.0
+	golang.org/x/crypto v0.0.0-20220525230936-793ad666bf5e
 	golang.org/x/exp v0.0.0-20230113213754-f9f960f08ad4
+	golang.org/x/term v0.0.0-20210220032956-6a3ed077a48d
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -22,7 +25,5 @@ require (
 	go.uber.org/atomic v1.7.0 // indirect
 	go.uber.org/multierr v1.6.0 // indirect
 	golang.org/x/sys v0.1.0 // indirect
-	golang.org/x/term v0.0.0-20210220032956-6a3ed077a48d // indirect
 	golang.org/x/text v0.3.7 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
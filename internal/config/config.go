@@ -0,0 +1,291 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mbpolan/lull/internal/keys"
+	"github.com/mbpolan/lull/internal/theme"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// FileName is the name of the configuration file in the config directory.
+const FileName = "config.yaml"
+
+// WorkspaceFileName is the name of the file in a workspace directory with settings that apply only to that workspace.
+const WorkspaceFileName = "settings.yaml"
+
+var global, instance *Config
+
+// Config holds the user's preferences for defaults and behavior.
+type Config struct {
+	Request   RequestConfig          `yaml:"request"`
+	StateFile string                 `yaml:"state_file"`
+	Autosave  Duration               `yaml:"autosave"`
+	Watch     Duration               `yaml:"watch"`
+	LogLevel  string                 `yaml:"log_level"`
+	Theme     string                 `yaml:"theme"`
+	Themes    map[string]theme.Theme `yaml:"themes,omitempty"`
+	Keys      map[string]string      `yaml:"keys"`
+}
+
+// WorkspaceConfig holds the settings that a workspace may change for itself.
+type WorkspaceConfig struct {
+	Request RequestConfig `yaml:"request"`
+}
+
+// RequestConfig holds defaults for sending and creating requests.
+type RequestConfig struct {
+	Timeout Duration          `yaml:"timeout"`
+	Headers map[string]string `yaml:"headers"`
+	Methods []string          `yaml:"methods"`
+}
+
+// Duration is a time.Duration that is written and read as text, such as "5s" or "1m30s".
+type Duration struct {
+	time.Duration
+}
+
+// MarshalYAML writes the duration as text.
+func (d Duration) MarshalYAML() (any, error) {
+	return d.String(), nil
+}
+
+// UnmarshalYAML reads the duration from text.
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+	v, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: %q is not a duration, such as 5s or 1m30s", node.Line, node.Value)
+	}
+
+	d.Duration = v
+	return nil
+}
+
+// ValidationError lists the problems found in a configuration file.
+type ValidationError struct {
+	Path     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid configuration in %s:\n  - %s", e.Path, strings.Join(e.Problems, "\n  - "))
+}
+
+// Setup loads the configuration from the file at path, falling back to the defaults if the file does not exist.
+func Setup(path string) error {
+	c, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	global, instance = c, c
+	return nil
+}
+
+// UseWorkspace applies the settings of a workspace on top of the configuration loaded by Setup, replacing those of
+// the workspace that was used before. A nil workspace configuration restores the configuration loaded by Setup.
+func UseWorkspace(w *WorkspaceConfig) {
+	if global == nil {
+		global = Default()
+	}
+
+	if w == nil {
+		instance = global
+		return
+	}
+
+	c := *global
+	c.Request = w.Request
+	instance = &c
+}
+
+// LoadWorkspace reads the settings of a workspace from the file at path. Settings missing from the file keep the
+// values from the configuration loaded by Setup, and headers are added to those from that configuration. If the file
+// does not exist, nil is returned. A ValidationError is returned if the file contains unknown settings or invalid
+// values.
+func LoadWorkspace(path string) (*WorkspaceConfig, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not read workspace settings")
+	}
+
+	base := global
+	if base == nil {
+		base = Default()
+	}
+
+	w := &WorkspaceConfig{
+		Request: RequestConfig{
+			Timeout: base.Request.Timeout,
+			Headers: map[string]string{},
+			Methods: append([]string{}, base.Request.Methods...),
+		},
+	}
+
+	for k, v := range base.Request.Headers {
+		w.Request.Headers[k] = v
+	}
+
+	if err := decode(data, path, w); err != nil {
+		return nil, err
+	}
+
+	c := *base
+	c.Request = w.Request
+	if problems := c.Validate(); len(problems) > 0 {
+		return nil, &ValidationError{Path: path, Problems: problems}
+	}
+
+	return w, nil
+}
+
+// Get returns the configuration loaded by Setup, or the defaults if no configuration was loaded.
+func Get() *Config {
+	if instance == nil {
+		return Default()
+	}
+
+	return instance
+}
+
+// Default returns the configuration used when the user has not provided their own.
+func Default() *Config {
+	return &Config{
+		Request: RequestConfig{
+			Timeout: Duration{30 * time.Second},
+			Headers: map[string]string{},
+			Methods: []string{"GET", "POST", "PUT", "DELETE", "PATCH"},
+		},
+		StateFile: "~/.lull",
+		Autosave:  Duration{5 * time.Second},
+		Watch:     Duration{2 * time.Second},
+		LogLevel:  "error",
+		Theme:     theme.Default,
+		Keys:      map[string]string{},
+	}
+}
+
+// Load reads the configuration from the file at path. Settings missing from the file keep their default values. If
+// the file does not exist, the defaults are returned. A ValidationError is returned if the file contains unknown
+// settings or invalid values.
+func Load(path string) (*Config, error) {
+	c := Default()
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, errors.Wrap(err, "could not read configuration")
+	}
+
+	if err := decode(data, path, c); err != nil {
+		return nil, err
+	}
+
+	if problems := c.Validate(); len(problems) > 0 {
+		return nil, &ValidationError{Path: path, Problems: problems}
+	}
+
+	return c, nil
+}
+
+// decode reads YAML into v, returning a ValidationError if it contains unknown settings or values of the wrong type.
+func decode(data []byte, path string, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	if err := dec.Decode(v); err != nil && err != io.EOF {
+		// type errors list every offending line, while other errors are about the file as a whole
+		if te, ok := err.(*yaml.TypeError); ok {
+			return &ValidationError{Path: path, Problems: te.Errors}
+		}
+
+		return &ValidationError{Path: path, Problems: []string{err.Error()}}
+	}
+
+	return nil
+}
+
+// Validate returns a description of each setting that has an invalid value.
+func (c *Config) Validate() []string {
+	var problems []string
+
+	if c.Request.Timeout.Duration < 0 {
+		problems = append(problems, "request.timeout: must not be negative")
+	}
+
+	if len(c.Request.Methods) == 0 {
+		problems = append(problems, "request.methods: at least one method is required")
+	}
+
+	seen := map[string]bool{}
+	for _, m := range c.Request.Methods {
+		if !isToken(m) || strings.ToUpper(m) != m {
+			problems = append(problems, fmt.Sprintf("request.methods: %q is not an uppercase HTTP method", m))
+		} else if seen[m] {
+			problems = append(problems, fmt.Sprintf("request.methods: %s is listed more than once", m))
+		}
+
+		seen[m] = true
+	}
+
+	for k := range c.Request.Headers {
+		if !isToken(k) {
+			problems = append(problems, fmt.Sprintf("request.headers: %q is not a valid header name", k))
+		}
+	}
+
+	if strings.TrimSpace(c.StateFile) == "" {
+		problems = append(problems, "state_file: must not be empty")
+	}
+
+	if c.Autosave.Duration < 0 {
+		problems = append(problems, "autosave: must not be negative")
+	}
+
+	if c.Watch.Duration < 0 {
+		problems = append(problems, "watch: must not be negative")
+	}
+
+	if _, err := zap.ParseAtomicLevel(c.LogLevel); err != nil {
+		problems = append(problems, fmt.Sprintf("log_level: %q is not one of debug, info, warn or error", c.LogLevel))
+	}
+
+	problems = append(problems, theme.Validate(c.Theme, c.Themes)...)
+	return append(problems, keys.Validate(c.Keys)...)
+}
+
+// Write writes the configuration as YAML.
+func (c *Config) Write(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// isToken returns true if the text is a valid HTTP token, as used for methods and header names.
+func isToken(text string) bool {
+	if text == "" {
+		return false
+	}
+
+	for _, r := range text {
+		if r > 0x7e || r <= 0x20 || strings.ContainsRune("\"(),/:;<=>?@[\\]{}", r) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_Load_MissingFile(t *testing.T) {
+	c, err := Load(filepath.Join(t.TempDir(), FileName))
+
+	assert.NoError(t, err)
+	assert.Equal(t, Default(), c)
+}
+
+func Test_Load_OverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	assert.NoError(t, os.WriteFile(path, []byte("request:\n  timeout: 1m\n  headers:\n    Accept: application/json\nautosave: 0s\n"), 0600))
+
+	c, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, time.Minute, c.Request.Timeout.Duration)
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, c.Request.Headers)
+	assert.Equal(t, Default().Request.Methods, c.Request.Methods)
+	assert.Equal(t, time.Duration(0), c.Autosave.Duration)
+}
+
+func Test_Load_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	assert.NoError(t, os.WriteFile(path, []byte("request:\n  methods: [get]\nlog_level: loud\nunknown: true\n"), 0600))
+
+	_, err := Load(path)
+
+	assert.IsType(t, &ValidationError{}, err)
+	assert.Len(t, err.(*ValidationError).Problems, 1)
+	assert.Contains(t, err.Error(), "unknown")
+}
+
+func Test_LoadWorkspace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	assert.NoError(t, os.WriteFile(path, []byte("request:\n  headers:\n    Accept: application/json\n"), 0600))
+	assert.NoError(t, Setup(path))
+	t.Cleanup(func() { global, instance = nil, nil })
+
+	wsPath := filepath.Join(t.TempDir(), WorkspaceFileName)
+	assert.NoError(t, os.WriteFile(wsPath, []byte("request:\n  timeout: 5s\n  headers:\n    X-Team: a\n  methods: [GET, HEAD]\n"), 0600))
+
+	w, err := LoadWorkspace(wsPath)
+	assert.NoError(t, err)
+
+	UseWorkspace(w)
+	assert.Equal(t, 5*time.Second, Get().Request.Timeout.Duration)
+	assert.Equal(t, map[string]string{"Accept": "application/json", "X-Team": "a"}, Get().Request.Headers)
+	assert.Equal(t, []string{"GET", "HEAD"}, Get().Request.Methods)
+
+	// the global configuration is restored for workspaces without settings
+	UseWorkspace(nil)
+	assert.Equal(t, 30*time.Second, Get().Request.Timeout.Duration)
+	assert.Equal(t, map[string]string{"Accept": "application/json"}, Get().Request.Headers)
+	assert.Equal(t, Default().Request.Methods, Get().Request.Methods)
+}
+
+func Test_LoadWorkspace_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), WorkspaceFileName)
+	assert.NoError(t, os.WriteFile(path, []byte("request:\n  methods: []\ntheme: dark\n"), 0600))
+
+	_, err := LoadWorkspace(path)
+
+	assert.IsType(t, &ValidationError{}, err)
+}
+
+func Test_LoadWorkspace_MissingFile(t *testing.T) {
+	w, err := LoadWorkspace(filepath.Join(t.TempDir(), WorkspaceFileName))
+
+	assert.NoError(t, err)
+	assert.Nil(t, w)
+}
@@ -8,6 +8,8 @@ const (
 	EventNavigateUp
 	EventNavigateDown
 	EventStatusBarContextChange
+	EventStatusBarSummary
+	EventCollectionItemChanged
 )
 
 // Payload is additional data sent with an event.
@@ -32,3 +34,9 @@ type StatusBarContextChangeSequence struct {
 type Listener interface {
 	HandleEvent(code Code, payload Payload)
 }
+
+// StatusBarSummaryData contains a summary of the selected item to show temporarily in the status bar, unless another
+// message is being shown. An empty summary removes the previous summary.
+type StatusBarSummaryData struct {
+	Text string
+}
@@ -0,0 +1,356 @@
+package formats
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const harVersion = "1.2"
+
+type harDocument struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Pages   []harPage  `json:"pages,omitempty"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harPage struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []harNameValue `json:"cookies"`
+	Headers     []harNameValue `json:"headers"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type harPostData struct {
+	MimeType string         `json:"mimeType"`
+	Text     string         `json:"text"`
+	Params   []harNameValue `json:"params,omitempty"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+// ImportHAR reads an HTTP Archive (HAR) 1.2 document and returns a group containing one request per recorded entry.
+// If withResponses is true, the recorded response of each entry is loaded as the result of its request.
+func ImportHAR(data []byte, path string, withResponses bool) (*state.CollectionItem, *Report, error) {
+	var doc harDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, errors.Wrap(err, "not a valid HAR document")
+	}
+
+	if doc.Log.Version == "" && doc.Log.Entries == nil {
+		return nil, nil, errors.New("not a valid HAR document: missing log")
+	}
+
+	report := NewReport()
+	if doc.Log.Version != "" && doc.Log.Version != harVersion {
+		report.Warnf("archive uses version %s; only %s is fully supported", doc.Log.Version, harVersion)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if len(doc.Log.Pages) > 0 && doc.Log.Pages[0].Title != "" {
+		name = doc.Log.Pages[0].Title
+	}
+
+	group := state.NewCollectionGroup(name, nil)
+	for _, entry := range doc.Log.Entries {
+		item := harImportRequest(entry, group, report)
+		if withResponses {
+			item.Result = harImportResult(entry, item.Name, report)
+		}
+
+		group.AddChild(item)
+	}
+
+	return group, report, nil
+}
+
+// ExportHAR writes the entries of the request history as an HTTP Archive (HAR) 1.2 document.
+func ExportHAR(history []*state.HistoryEntry, version string) ([]byte, *Report, error) {
+	report := NewReport()
+	doc := harDocument{
+		Log: harLog{
+			Version: harVersion,
+			Creator: harCreator{
+				Name:    "lull",
+				Version: version,
+			},
+			Entries: []harEntry{},
+		},
+	}
+
+	for _, h := range history {
+		if h.Request == nil || h.Result == nil || h.Result.Response == nil {
+			report.Warnf("%s has no recorded response and was skipped", h.Name)
+			continue
+		}
+
+		doc.Log.Entries = append(doc.Log.Entries, harExportEntry(h))
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, report, nil
+}
+
+func harImportRequest(entry harEntry, parent *state.CollectionItem, report *Report) *state.CollectionItem {
+	name := fmt.Sprintf("%s %s", entry.Request.Method, entry.Request.URL)
+	if u, err := url.Parse(entry.Request.URL); err == nil && u.Path != "" {
+		name = fmt.Sprintf("%s %s", entry.Request.Method, u.Path)
+	}
+
+	item := state.NewCollectionRequest(name, entry.Request.Method, entry.Request.URL, parent)
+	for _, h := range entry.Request.Headers {
+		// skip http/2 pseudo-headers and headers computed by the client when sending
+		lower := strings.ToLower(h.Name)
+		if strings.HasPrefix(h.Name, ":") || lower == "content-length" || lower == "host" {
+			continue
+		}
+
+		item.AddHeader(h.Name, h.Value)
+	}
+
+	if pd := entry.Request.PostData; pd != nil {
+		item.RequestBody = harImportBody(pd, name, report)
+	}
+
+	return item
+}
+
+func harImportBody(pd *harPostData, name string, report *Report) *state.RequestBody {
+	text := pd.Text
+	if text == "" && len(pd.Params) > 0 {
+		form := url.Values{}
+		for _, p := range pd.Params {
+			form.Add(p.Name, p.Value)
+		}
+
+		text = form.Encode()
+	}
+
+	if text == "" {
+		return nil
+	}
+
+	contentType := "text/plain"
+	if strings.Contains(pd.MimeType, "json") {
+		contentType = "application/json"
+	} else if pd.MimeType != "" && !strings.HasPrefix(pd.MimeType, "text/plain") {
+		report.Warnf("%s body on %s was converted to plain text", pd.MimeType, name)
+	}
+
+	return &state.RequestBody{
+		Payload:     text,
+		ContentType: contentType,
+	}
+}
+
+func harImportResult(entry harEntry, name string, report *Report) *state.HTTPResult {
+	res := entry.Response
+	header := http.Header{}
+	for _, h := range res.Headers {
+		if !strings.HasPrefix(h.Name, ":") {
+			header.Add(h.Name, h.Value)
+		}
+	}
+
+	payload := []byte(res.Content.Text)
+	if res.Content.Encoding == "base64" {
+		decoded, err := base64.StdEncoding.DecodeString(res.Content.Text)
+		if err != nil {
+			report.Warnf("response body on %s could not be decoded: %s", name, err)
+		} else {
+			payload = decoded
+		}
+	}
+
+	proto := res.HTTPVersion
+	major, minor, ok := http.ParseHTTPVersion(strings.ToUpper(proto))
+	if !ok {
+		proto, major, minor = "HTTP/1.1", 1, 1
+	}
+
+	return &state.HTTPResult{
+		Response: &http.Response{
+			Status:        strings.TrimSpace(fmt.Sprintf("%d %s", res.Status, res.StatusText)),
+			StatusCode:    res.Status,
+			Proto:         proto,
+			ProtoMajor:    major,
+			ProtoMinor:    minor,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(payload)),
+			ContentLength: int64(len(payload)),
+		},
+		Payload:  payload,
+		Duration: time.Duration(entry.Time * float64(time.Millisecond)),
+	}
+}
+
+func harExportEntry(h *state.HistoryEntry) harEntry {
+	req := h.Request
+	res := h.Result.Response
+	elapsed := float64(h.Result.Duration) / float64(time.Millisecond)
+
+	query := []harNameValue{}
+	if req.URL != nil {
+		query = harNameValues(req.URL.Query())
+	}
+
+	var postData *harPostData
+	bodySize := 0
+	if h.RequestBody != nil && h.RequestBody.Payload != "" {
+		postData = &harPostData{
+			MimeType: h.RequestBody.ContentType,
+			Text:     h.RequestBody.Payload,
+		}
+
+		bodySize = len(h.RequestBody.Payload)
+	}
+
+	content := harContent{
+		Size:     len(h.Result.Payload),
+		MimeType: res.Header.Get("Content-Type"),
+	}
+
+	// binary payloads cannot be stored as plain text
+	if isText(h.Result.Payload) {
+		content.Text = string(h.Result.Payload)
+	} else {
+		content.Text = base64.StdEncoding.EncodeToString(h.Result.Payload)
+		content.Encoding = "base64"
+	}
+
+	return harEntry{
+		StartedDateTime: h.StartTime,
+		Time:            elapsed,
+		Request: harRequest{
+			Method:      req.Method,
+			URL:         req.URL.String(),
+			HTTPVersion: req.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harNameValues(req.Header),
+			QueryString: query,
+			PostData:    postData,
+			HeadersSize: -1,
+			BodySize:    bodySize,
+		},
+		Response: harResponse{
+			Status:      res.StatusCode,
+			StatusText:  strings.TrimSpace(strings.TrimPrefix(res.Status, fmt.Sprint(res.StatusCode))),
+			HTTPVersion: res.Proto,
+			Cookies:     []harNameValue{},
+			Headers:     harNameValues(res.Header),
+			Content:     content,
+			RedirectURL: res.Header.Get("Location"),
+			HeadersSize: -1,
+			BodySize:    len(h.Result.Payload),
+		},
+		Timings: harTimings{
+			Wait: elapsed,
+		},
+	}
+}
+
+// harNameValues converts a multi-valued map into a list of name/value pairs sorted by name.
+func harNameValues(values map[string][]string) []harNameValue {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	pairs := []harNameValue{}
+	for _, k := range keys {
+		for _, v := range values[k] {
+			pairs = append(pairs, harNameValue{Name: k, Value: v})
+		}
+	}
+
+	return pairs
+}
+
+// isText returns true if the data is valid UTF-8 and contains no control characters other than whitespace.
+func isText(data []byte) bool {
+	if !utf8.Valid(data) {
+		return false
+	}
+
+	for _, b := range data {
+		if b < 0x20 && b != '\n' && b != '\r' && b != '\t' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,114 @@
+package formats
+
+import (
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+const harFixture = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "Browser", "version": "1.0"},
+    "entries": [
+      {
+        "startedDateTime": "2022-06-01T10:00:00.000Z",
+        "time": 120,
+        "request": {
+          "method": "POST",
+          "url": "https://api.example.com/users?page=1",
+          "httpVersion": "HTTP/2.0",
+          "headers": [
+            {"name": ":authority", "value": "api.example.com"},
+            {"name": "Content-Length", "value": "15"},
+            {"name": "Accept", "value": "application/json"}
+          ],
+          "postData": {"mimeType": "application/json", "text": "{\"name\":\"bob\"}"}
+        },
+        "response": {
+          "status": 201,
+          "statusText": "Created",
+          "httpVersion": "HTTP/2.0",
+          "headers": [{"name": "Content-Type", "value": "application/json"}],
+          "content": {"size": 9, "mimeType": "application/json", "text": "eyJpZCI6MX0=", "encoding": "base64"}
+        }
+      }
+    ]
+  }
+}`
+
+func Test_ImportHAR(t *testing.T) {
+	group, report, err := ImportHAR([]byte(harFixture), "/tmp/session.har", false)
+
+	assert.NoError(t, err)
+	assert.True(t, report.Empty())
+	assert.Equal(t, "session", group.Name)
+	assert.Len(t, group.Children, 1)
+
+	req := group.Children[0]
+	assert.Equal(t, "POST /users", req.Name)
+	assert.Equal(t, "https://api.example.com/users?page=1", req.URL)
+	assert.Equal(t, map[string][]string{"Accept": {"application/json"}}, req.Headers)
+	assert.Equal(t, &state.RequestBody{Payload: `{"name":"bob"}`, ContentType: "application/json"}, req.RequestBody)
+	assert.Nil(t, req.Result)
+}
+
+func Test_ImportHAR_WithResponses(t *testing.T) {
+	group, _, err := ImportHAR([]byte(harFixture), "/tmp/session.har", true)
+
+	assert.NoError(t, err)
+
+	result := group.Children[0].Result
+	assert.Equal(t, 201, result.Response.StatusCode)
+	assert.Equal(t, "201 Created", result.Response.Status)
+	assert.Equal(t, `{"id":1}`, string(result.Payload))
+	assert.Equal(t, 120*time.Millisecond, result.Duration)
+}
+
+func Test_ExportHAR(t *testing.T) {
+	u, _ := url.Parse("https://api.example.com/users?page=1")
+	req := &http.Request{
+		Method: "GET",
+		URL:    u,
+		Proto:  "HTTP/1.1",
+		Header: http.Header{"Accept": {"application/json"}},
+	}
+
+	history := []*state.HistoryEntry{
+		{
+			Name:    "List users",
+			Request: req,
+			Result: &state.HTTPResult{
+				Response: &http.Response{
+					Status:     "200 OK",
+					StatusCode: 200,
+					Proto:      "HTTP/1.1",
+					Header:     http.Header{"Content-Type": {"application/json"}},
+				},
+				Payload:  []byte(`[]`),
+				Duration: 50 * time.Millisecond,
+			},
+			StartTime: time.Date(2022, 6, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			Name: "Failed",
+		},
+	}
+
+	data, report, err := ExportHAR(history, "1.0.0")
+	assert.NoError(t, err)
+	assert.Len(t, report.Warnings, 1)
+
+	group, _, err := ImportHAR(data, "/tmp/export.har", true)
+	assert.NoError(t, err)
+	assert.Len(t, group.Children, 1)
+
+	item := group.Children[0]
+	assert.Equal(t, "GET", item.Method)
+	assert.Equal(t, "https://api.example.com/users?page=1", item.URL)
+	assert.Equal(t, "200 OK", item.Result.Response.Status)
+	assert.Equal(t, "[]", string(item.Result.Payload))
+}
@@ -0,0 +1,602 @@
+package formats
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/system"
+	"github.com/pkg/errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	httpDirSourcePrefix    = "http:dir:"
+	httpSubdirSourcePrefix = "http:subdir:"
+	httpFileSourcePrefix   = "http:file:"
+
+	// httpDefaultFileName is the file that requests added directly to a directory group are written to
+	httpDefaultFileName = "requests.http"
+)
+
+var httpMethods = map[string]bool{
+	"GET":     true,
+	"HEAD":    true,
+	"POST":    true,
+	"PUT":     true,
+	"PATCH":   true,
+	"DELETE":  true,
+	"OPTIONS": true,
+	"TRACE":   true,
+	"CONNECT": true,
+}
+
+var (
+	httpNamePattern       = regexp.MustCompile(`^(?:#|//)\s*@name\s+(.+)$`)
+	httpDirectivePattern  = regexp.MustCompile(`^(?:#|//)\s*@(\S+)`)
+	httpIdentifierPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// ImportHTTPFile reads a .http file in the JetBrains/VS Code REST Client format and returns a group containing its
+// requests. Variables defined in the file are stored on the group.
+func ImportHTTPFile(data []byte, name string) (*state.CollectionItem, *Report) {
+	report := NewReport()
+	group := state.NewCollectionGroup(name, nil)
+	parseHTTPFile(data, group, report)
+
+	return group, report
+}
+
+// ExportHTTPFile writes the requests contained in a group in the JetBrains/VS Code REST Client format. Requests in
+// nested groups are flattened into the file.
+func ExportHTTPFile(group *state.CollectionItem) ([]byte, *Report, error) {
+	if !group.IsGroup {
+		return nil, nil, errors.New("only groups can be exported")
+	}
+
+	report := NewReport()
+	return serializeHTTPFile(group, report), report, nil
+}
+
+// OpenHTTPDirectory reads all .http files in a directory and its subdirectories, returning a group that remains
+// linked to the directory so that changes can later be written back with SaveHTTPDirectories.
+func OpenHTTPDirectory(path string) (*state.CollectionItem, *Report, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, err
+	} else if !info.IsDir() {
+		return nil, nil, errors.Errorf("%s is not a directory", path)
+	}
+
+	report := NewReport()
+	group := state.NewCollectionGroup(filepath.Base(path), nil)
+	group.Source = httpDirSourcePrefix + path
+
+	if err := readHTTPDirectory(path, group, report); err != nil {
+		return nil, nil, err
+	}
+
+	return group, report, nil
+}
+
+// ReloadHTTPDirectories replaces the contents of all groups linked to a directory of .http files with the current
+// contents of those directories. Groups whose directory can no longer be read are left unchanged.
+func ReloadHTTPDirectories(root *state.CollectionItem) *Report {
+	report := NewReport()
+
+	walkItems(root, func(item *state.CollectionItem) {
+		path, ok := HTTPDirectoryPath(item)
+		if !ok {
+			return
+		}
+
+		fresh, r, err := OpenHTTPDirectory(path)
+		if err != nil {
+			report.Warnf("could not reload %s: %s", path, err)
+			return
+		}
+
+		report.Warnings = append(report.Warnings, r.Warnings...)
+		keepHTTPIdentities(item.Children, fresh.Children)
+		item.Children = fresh.Children
+		for _, c := range item.Children {
+			c.Parent = item
+		}
+	})
+
+	return report
+}
+
+// keepHTTPIdentities gives items read from .http files the UUIDs of the items previously read from the same place, so
+// that anything keyed by UUID, such as secrets and collapsed groups, still applies to them. Groups are matched by their
+// file or subdirectory, and requests by their name.
+func keepHTTPIdentities(old []*state.CollectionItem, fresh []*state.CollectionItem) {
+	matched := map[*state.CollectionItem]bool{}
+
+	for _, f := range fresh {
+		for _, o := range old {
+			if matched[o] || o.IsGroup != f.IsGroup {
+				continue
+			} else if f.IsGroup && o.Source != f.Source || !f.IsGroup && o.Name != f.Name {
+				continue
+			}
+
+			matched[o] = true
+			f.UUID = o.UUID
+			keepHTTPIdentities(o.Children, f.Children)
+			break
+		}
+	}
+}
+
+// SaveHTTPDirectories writes the contents of all groups linked to a directory of .http files back to disk. Only
+// files whose requests have changed are rewritten.
+func SaveHTTPDirectories(root *state.CollectionItem) (*Report, error) {
+	report := NewReport()
+
+	var err error
+	walkItems(root, func(item *state.CollectionItem) {
+		path, ok := HTTPDirectoryPath(item)
+		if !ok || err != nil {
+			return
+		}
+
+		err = writeHTTPDirectory(path, item, report)
+	})
+
+	return report, err
+}
+
+// HTTPDirectoryPath returns the path of the directory of .http files a group is linked to, if any.
+func HTTPDirectoryPath(item *state.CollectionItem) (string, bool) {
+	if !item.IsGroup || !strings.HasPrefix(item.Source, httpDirSourcePrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(item.Source, httpDirSourcePrefix), true
+}
+
+func readHTTPDirectory(path string, group *state.CollectionItem, report *Report) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if e.IsDir() {
+			sub := state.NewCollectionGroup(name, group)
+			sub.Source = httpSubdirSourcePrefix + name
+
+			if err := readHTTPDirectory(filepath.Join(path, name), sub, report); err != nil {
+				return err
+			}
+
+			// skip directories that don't contain any requests
+			if len(sub.Children) > 0 {
+				group.AddChild(sub)
+			}
+		} else if ext := filepath.Ext(name); ext == ".http" || ext == ".rest" {
+			data, err := os.ReadFile(filepath.Join(path, name))
+			if err != nil {
+				return err
+			}
+
+			file := state.NewCollectionGroup(strings.TrimSuffix(name, ext), group)
+			file.Source = httpFileSourcePrefix + name
+			parseHTTPFile(data, file, report)
+			group.AddChild(file)
+		}
+	}
+
+	return nil
+}
+
+func writeHTTPDirectory(path string, group *state.CollectionItem, report *Report) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	// groups keep their file or subdirectory unless they were added or renamed in lull
+	names := map[*state.CollectionItem]string{}
+	used := map[string]bool{}
+	for _, c := range group.Children {
+		if name, ok := httpLinkedName(c); ok && !used[name] {
+			names[c] = name
+			used[name] = true
+		}
+	}
+
+	// requests that are not in a file's group are written to the default file, together with the requests of the
+	// group already linked to it, if any
+	var loose []*state.CollectionItem
+	for _, c := range group.Children {
+		if !c.IsGroup {
+			loose = append(loose, c)
+		}
+	}
+
+	if len(loose) > 0 && !used[httpDefaultFileName] {
+		used[httpDefaultFileName] = true
+		if err := writeHTTPFile(filepath.Join(path, httpDefaultFileName), serializeHTTPFile(httpRequestsGroup(nil, loose), report)); err != nil {
+			return err
+		}
+
+		loose = nil
+	}
+
+	for _, c := range group.Children {
+		if !c.IsGroup {
+			continue
+		}
+
+		subdir := strings.HasPrefix(c.Source, httpSubdirSourcePrefix)
+
+		name, ok := names[c]
+		if !ok {
+			prefix, ext := httpFileSourcePrefix, ".http"
+			if subdir {
+				prefix, ext = httpSubdirSourcePrefix, ""
+			} else if strings.HasPrefix(c.Source, httpFileSourcePrefix) {
+				ext = filepath.Ext(c.Source)
+			}
+
+			name = httpUniqueName(httpSafeName(c.Name), ext, used)
+			used[name] = true
+			c.Source = prefix + name
+		}
+
+		var err error
+		if subdir {
+			err = writeHTTPDirectory(filepath.Join(path, name), c, report)
+		} else if name == httpDefaultFileName {
+			err = writeHTTPFile(filepath.Join(path, name), serializeHTTPFile(httpRequestsGroup(c, loose), report))
+		} else {
+			err = writeHTTPFile(filepath.Join(path, name), serializeHTTPFile(c, report))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return removeStaleHTTPFiles(path, used)
+}
+
+// httpRequestsGroup returns a group to serialize with the variables and requests of a file's group, if any, followed by
+// additional requests.
+func httpRequestsGroup(file *state.CollectionItem, requests []*state.CollectionItem) *state.CollectionItem {
+	group := state.NewCollectionGroup("", nil)
+	if file != nil {
+		group.Variables = file.Variables
+		group.Children = append(group.Children, file.Children...)
+	}
+
+	group.Children = append(group.Children, requests...)
+	return group
+}
+
+// httpLinkedName returns the name of the file or subdirectory a group was read from, if the group has not been renamed
+// since.
+func httpLinkedName(group *state.CollectionItem) (string, bool) {
+	if name := strings.TrimPrefix(group.Source, httpSubdirSourcePrefix); name != group.Source {
+		return name, name == group.Name
+	} else if name := strings.TrimPrefix(group.Source, httpFileSourcePrefix); name != group.Source {
+		return name, strings.TrimSuffix(name, filepath.Ext(name)) == group.Name
+	}
+
+	return "", false
+}
+
+// removeStaleHTTPFiles removes .http files and subdirectories of them that no longer belong to a group. Other files
+// are left untouched.
+func removeStaleHTTPFiles(path string, used map[string]bool) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		name := e.Name()
+		if used[name] || strings.HasPrefix(name, ".") {
+			continue
+		}
+
+		if e.IsDir() {
+			err = removeStaleHTTPFiles(filepath.Join(path, name), nil)
+			if err == nil {
+				err = removeIfEmpty(filepath.Join(path, name))
+			}
+		} else if ext := filepath.Ext(name); ext == ".http" || ext == ".rest" {
+			err = os.Remove(filepath.Join(path, name))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeIfEmpty removes a directory if it contains no files.
+func removeIfEmpty(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil || len(entries) > 0 {
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+// writeHTTPFile writes data to a file unless the requests it already contains are equivalent. This avoids
+// discarding comments and formatting in files that were not changed.
+func writeHTTPFile(path string, data []byte) error {
+	if existing, err := os.ReadFile(path); err == nil {
+		current := state.NewCollectionGroup("", nil)
+		parseHTTPFile(existing, current, NewReport())
+
+		if bytes.Equal(serializeHTTPFile(current, NewReport()), data) {
+			return nil
+		}
+	}
+
+	return system.WriteFileAtomic(path, data, 0644)
+}
+
+// httpSafeName returns a file or directory name for a group, replacing characters that are not safe in file names.
+func httpSafeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+
+		return r
+	}, strings.TrimSpace(name))
+
+	if name == "" || strings.HasPrefix(name, ".") {
+		name = "requests" + name
+	}
+
+	return name
+}
+
+// httpUniqueName returns a file name with the given extension that is not already used, adding a number to the name
+// if needed.
+func httpUniqueName(name string, ext string, used map[string]bool) string {
+	unique := name + ext
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s %d%s", name, i, ext)
+	}
+
+	return unique
+}
+
+func parseHTTPFile(data []byte, group *state.CollectionItem, report *Report) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+
+	var title string
+	var block []string
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "###") {
+			parseHTTPBlock(title, block, group, report)
+
+			title = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "###"))
+			block = nil
+			continue
+		}
+
+		block = append(block, line)
+	}
+
+	parseHTTPBlock(title, block, group, report)
+}
+
+// parseHTTPBlock parses the lines between two ### separators, adding a request to the group if one is defined.
+func parseHTTPBlock(title string, lines []string, group *state.CollectionItem, report *Report) {
+	const (
+		preamble = iota
+		headers
+		body
+	)
+
+	var item *state.CollectionItem
+	var name string
+	var bodyLines []string
+	section := preamble
+
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+
+		switch section {
+		case preamble:
+			if trimmed == "" {
+				continue
+			} else if strings.HasPrefix(trimmed, "@") {
+				parseHTTPVariable(trimmed, group, report)
+			} else if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+				if m := httpNamePattern.FindStringSubmatch(trimmed); m != nil {
+					name = strings.TrimSpace(m[1])
+				} else if m := httpDirectivePattern.FindStringSubmatch(trimmed); m != nil {
+					report.Warnf("directive @%s is not supported", m[1])
+				}
+			} else {
+				item = parseHTTPRequestLine(trimmed, group)
+				section = headers
+			}
+
+		case headers:
+			if trimmed == "" {
+				section = body
+			} else if (strings.HasPrefix(trimmed, "?") || strings.HasPrefix(trimmed, "&")) && len(item.Headers) == 0 {
+				// query parameters may continue on following lines
+				item.URL += trimmed
+			} else if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "//") {
+				continue
+			} else if k, v, ok := strings.Cut(trimmed, ":"); ok {
+				item.AddHeader(strings.TrimSpace(k), strings.TrimSpace(v))
+			} else {
+				report.Warnf("invalid header line on %s: %s", item.URL, trimmed)
+			}
+
+		case body:
+			if strings.HasPrefix(trimmed, "> ") || strings.HasPrefix(trimmed, ">>") || strings.HasPrefix(trimmed, "<> ") {
+				report.Warnf("response handlers on %s are not supported", item.URL)
+				continue
+			} else if strings.HasPrefix(trimmed, "< ") {
+				report.Warnf("file input %s on %s is not supported", strings.TrimPrefix(trimmed, "< "), item.URL)
+				continue
+			}
+
+			bodyLines = append(bodyLines, line)
+		}
+	}
+
+	if item == nil {
+		return
+	}
+
+	switch {
+	case name != "":
+		item.Name = name
+	case title != "":
+		item.Name = title
+	default:
+		item.Name = fmt.Sprintf("%s %s", item.Method, item.URL)
+		if u, err := url.Parse(item.ExpandVariables(item.URL)); err == nil && u.Path != "" {
+			item.Name = fmt.Sprintf("%s %s", item.Method, u.Path)
+		}
+	}
+
+	payload := strings.TrimRight(strings.Join(bodyLines, "\n"), "\n\t ")
+	if strings.TrimSpace(payload) != "" {
+		item.RequestBody = httpRequestBody(item, payload)
+	}
+
+	group.AddChild(item)
+}
+
+func parseHTTPVariable(line string, group *state.CollectionItem, report *Report) {
+	k, v, ok := strings.Cut(strings.TrimPrefix(line, "@"), "=")
+	if !ok {
+		report.Warnf("invalid variable definition: %s", line)
+		return
+	}
+
+	if group.Variables == nil {
+		group.Variables = map[string]string{}
+	}
+
+	group.Variables[strings.TrimSpace(k)] = strings.TrimSpace(v)
+}
+
+func parseHTTPRequestLine(line string, group *state.CollectionItem) *state.CollectionItem {
+	method := "GET"
+	uri := line
+
+	if m, rest, ok := strings.Cut(line, " "); ok && httpMethods[strings.ToUpper(m)] {
+		method = strings.ToUpper(m)
+		uri = strings.TrimSpace(rest)
+	}
+
+	// drop the protocol version if present
+	if i := strings.LastIndex(uri, " HTTP/"); i > -1 {
+		uri = strings.TrimSpace(uri[:i])
+	}
+
+	return state.NewCollectionRequest("", method, uri, group)
+}
+
+// httpRequestBody returns a request body for the payload, taking its content type from the request's headers.
+func httpRequestBody(item *state.CollectionItem, payload string) *state.RequestBody {
+	contentType := "text/plain"
+
+	for k, v := range item.Headers {
+		if !strings.EqualFold(k, "Content-Type") || len(v) == 0 {
+			continue
+		}
+
+		// the content type is kept as written so that it's not changed when the file is saved again, and is sent
+		// based on the request body
+		contentType = v[0]
+		item.RemoveHeader(k)
+	}
+
+	return &state.RequestBody{
+		Payload:     payload,
+		ContentType: contentType,
+	}
+}
+
+func serializeHTTPFile(group *state.CollectionItem, report *Report) []byte {
+	var b strings.Builder
+
+	names := make([]string, 0, len(group.Variables))
+	for k := range group.Variables {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+	for _, k := range names {
+		fmt.Fprintf(&b, "@%s = %s\n", k, group.Variables[k])
+	}
+
+	first := true
+	walkItems(group, func(item *state.CollectionItem) {
+		if item == group {
+			return
+		} else if item.IsGroup {
+			if len(item.Variables) > 0 {
+				report.Warnf("variables on %s were not saved", item.Name)
+			}
+
+			return
+		}
+
+		if !first || len(names) > 0 {
+			b.WriteString("\n")
+		}
+
+		first = false
+		serializeHTTPRequest(&b, item, report)
+	})
+
+	return []byte(b.String())
+}
+
+func serializeHTTPRequest(b *strings.Builder, item *state.CollectionItem, report *Report) {
+	fmt.Fprintf(b, "### %s\n", item.Name)
+	if httpIdentifierPattern.MatchString(item.Name) {
+		fmt.Fprintf(b, "# @name %s\n", item.Name)
+	}
+
+	fmt.Fprintf(b, "%s %s\n", item.Method, item.URL)
+
+	keys := make([]string, 0, len(item.Headers))
+	for k := range item.Headers {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range item.Headers[k] {
+			fmt.Fprintf(b, "%s: %s\n", k, v)
+		}
+	}
+
+	if !item.Authentication.None() && !item.Authentication.Inherited() {
+		report.Warnf("authentication on %s was not saved", item.Name)
+	}
+
+	if item.RequestBody != nil && item.RequestBody.Payload != "" {
+		fmt.Fprintf(b, "Content-Type: %s\n\n%s\n", item.RequestBody.ContentType, item.RequestBody.Payload)
+	}
+}
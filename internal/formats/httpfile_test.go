@@ -0,0 +1,214 @@
+package formats
+
+import (
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const httpFileFixture = `@baseUrl = https://api.example.com
+@token = abc123
+
+### List users
+GET {{baseUrl}}/users
+    ?page=1
+    &size=10
+Accept: application/json
+
+###
+# @name createUser
+POST {{baseUrl}}/users HTTP/1.1
+Authorization: Bearer {{token}}
+Content-Type: application/json
+
+{
+  "name": "bob"
+}
+
+> {% client.global.set("id", response.body.id); %}
+
+###
+{{baseUrl}}/health
+`
+
+func Test_ImportHTTPFile(t *testing.T) {
+	group, report := ImportHTTPFile([]byte(httpFileFixture), "users")
+
+	assert.Equal(t, "users", group.Name)
+	assert.Equal(t, map[string]string{"baseUrl": "https://api.example.com", "token": "abc123"}, group.Variables)
+	assert.Len(t, report.Warnings, 1)
+	assert.Len(t, group.Children, 3)
+
+	list := group.Children[0]
+	assert.Equal(t, "List users", list.Name)
+	assert.Equal(t, "GET", list.Method)
+	assert.Equal(t, "{{baseUrl}}/users?page=1&size=10", list.URL)
+	assert.Equal(t, map[string][]string{"Accept": {"application/json"}}, list.Headers)
+
+	create := group.Children[1]
+	assert.Equal(t, "createUser", create.Name)
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, "{{baseUrl}}/users", create.URL)
+	assert.Equal(t, map[string][]string{"Authorization": {"Bearer {{token}}"}}, create.Headers)
+	assert.Equal(t, &state.RequestBody{Payload: "{\n  \"name\": \"bob\"\n}", ContentType: "application/json"}, create.RequestBody)
+	assert.Equal(t, "Bearer abc123", create.ExpandVariables(create.Headers["Authorization"][0]))
+
+	health := group.Children[2]
+	assert.Equal(t, "GET /health", health.Name)
+	assert.Equal(t, "GET", health.Method)
+}
+
+func Test_ExportHTTPFile_RoundTrip(t *testing.T) {
+	group, _ := ImportHTTPFile([]byte(httpFileFixture), "users")
+
+	data, _, err := ExportHTTPFile(group)
+	assert.NoError(t, err)
+
+	reimported, report := ImportHTTPFile(data, "users")
+	assert.True(t, report.Empty())
+	assert.Equal(t, group.Variables, reimported.Variables)
+	assert.Len(t, reimported.Children, 3)
+
+	for i, c := range group.Children {
+		assert.Equal(t, c.Name, reimported.Children[i].Name)
+		assert.Equal(t, c.Method, reimported.Children[i].Method)
+		assert.Equal(t, c.URL, reimported.Children[i].URL)
+		assert.Equal(t, c.Headers, reimported.Children[i].Headers)
+		assert.Equal(t, c.RequestBody, reimported.Children[i].RequestBody)
+	}
+}
+
+func Test_SaveHTTPDirectories(t *testing.T) {
+	dir := t.TempDir()
+	original := "# a comment that should survive\n" + httpFileFixture
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "admin"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users.http"), []byte(original), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "admin", "audit.http"), []byte("GET https://example.com/audit\n"), 0644))
+
+	group, _, err := OpenHTTPDirectory(dir)
+	assert.NoError(t, err)
+	assert.Len(t, group.Children, 2)
+
+	root := state.NewCollectionGroup("root", nil)
+	root.AddChild(group)
+	group.Parent = root
+
+	// change a request in a subdirectory only
+	audit := group.Children[0].Children[0].Children[0]
+	audit.URL = "https://example.com/audit/v2"
+
+	_, err = SaveHTTPDirectories(root)
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(dir, "users.http"))
+	assert.NoError(t, err)
+	assert.Equal(t, original, string(data))
+
+	data, err = os.ReadFile(filepath.Join(dir, "admin", "audit.http"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "GET https://example.com/audit/v2\n")
+
+	// reloading picks up the written changes
+	ReloadHTTPDirectories(root)
+	assert.Equal(t, "https://example.com/audit/v2", group.Children[0].Children[0].Children[0].URL)
+}
+
+func Test_SaveHTTPDirectories_RemovesStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "admin"), 0755))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users.http"), []byte(httpFileFixture), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "admin", "audit.http"), []byte("GET https://example.com/audit\n"), 0644))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("keep me"), 0644))
+
+	group, _, err := OpenHTTPDirectory(dir)
+	assert.NoError(t, err)
+
+	root := state.NewCollectionGroup("root", nil)
+	root.AddChild(group)
+
+	// rename the file's group and delete the subdirectory's group
+	users := group.Children[1]
+	users.Name = "accounts"
+	group.RemoveChild(group.Children[0])
+
+	_, err = SaveHTTPDirectories(root)
+	assert.NoError(t, err)
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+
+	assert.Equal(t, []string{"accounts.http", "notes.txt"}, names)
+}
+
+func Test_SaveHTTPDirectories_LooseRequests(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users.http"), []byte(httpFileFixture), 0644))
+
+	group, _, err := OpenHTTPDirectory(dir)
+	assert.NoError(t, err)
+
+	root := state.NewCollectionGroup("root", nil)
+	root.AddChild(group)
+
+	// a request added directly to the directory's group is written to the default file
+	group.AddChild(state.NewCollectionRequest("ping", "GET", "https://example.com/ping", group))
+
+	report, err := SaveHTTPDirectories(root)
+	assert.NoError(t, err)
+	assert.True(t, report.Empty())
+
+	ReloadHTTPDirectories(root)
+	assert.Len(t, group.Children, 2)
+	assert.Equal(t, "requests", group.Children[0].Name)
+	assert.Equal(t, "https://example.com/ping", group.Children[0].Children[0].URL)
+
+	// later requests are added to that file rather than replacing it
+	group.AddChild(state.NewCollectionRequest("pong", "GET", "https://example.com/pong", group))
+
+	_, err = SaveHTTPDirectories(root)
+	assert.NoError(t, err)
+
+	ReloadHTTPDirectories(root)
+	assert.Len(t, group.Children, 2)
+	assert.Len(t, group.Children[0].Children, 2)
+	assert.Equal(t, "pong", group.Children[0].Children[1].Name)
+}
+
+func Test_ReloadHTTPDirectories_KeepsUUIDs(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "users.http"), []byte(httpFileFixture), 0644))
+
+	group, _, err := OpenHTTPDirectory(dir)
+	assert.NoError(t, err)
+
+	root := state.NewCollectionGroup("root", nil)
+	root.AddChild(group)
+
+	file := group.Children[0]
+	request := file.Children[1]
+
+	ReloadHTTPDirectories(root)
+
+	assert.NotSame(t, file, group.Children[0])
+	assert.Equal(t, file.UUID, group.Children[0].UUID)
+	assert.Equal(t, request.UUID, group.Children[0].Children[1].UUID)
+}
+
+func Test_ImportHTTPFile_KeepsContentType(t *testing.T) {
+	group, _ := ImportHTTPFile([]byte("POST https://example.com/soap\nContent-Type: text/xml; charset=utf-8\n\n<a/>\n"), "soap")
+
+	req := group.Children[0]
+	assert.Equal(t, "text/xml; charset=utf-8", req.RequestBody.ContentType)
+	assert.Empty(t, req.Headers)
+
+	data, _, err := ExportHTTPFile(group)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Content-Type: text/xml; charset=utf-8\n\n<a/>\n")
+}
@@ -0,0 +1,798 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// openAPIUntagged is the name of the group containing operations without any tags.
+const openAPIUntagged = "default"
+
+// openAPIMaxSchemaDepth limits how deeply nested schemas are expanded when generating example bodies.
+const openAPIMaxSchemaDepth = 8
+
+// openAPIPathPattern matches a path parameter template in the path of an operation.
+var openAPIPathPattern = regexp.MustCompile(`\{([^{}/]+)\}`)
+
+// openAPIDocument is a superset of the OpenAPI 3 and Swagger 2 document structures.
+type openAPIDocument struct {
+	OpenAPI string `yaml:"openapi"`
+	Swagger string `yaml:"swagger"`
+	Info    struct {
+		Title string `yaml:"title"`
+	} `yaml:"info"`
+	Servers []struct {
+		URL       string `yaml:"url"`
+		Variables map[string]struct {
+			Default string `yaml:"default"`
+		} `yaml:"variables"`
+	} `yaml:"servers"`
+	Host       string                     `yaml:"host"`
+	BasePath   string                     `yaml:"basePath"`
+	Schemes    []string                   `yaml:"schemes"`
+	Consumes   []string                   `yaml:"consumes"`
+	Paths      map[string]openAPIPathItem `yaml:"paths"`
+	Components struct {
+		Schemas         map[string]*openAPISchema         `yaml:"schemas"`
+		Parameters      map[string]*openAPIParameter      `yaml:"parameters"`
+		RequestBodies   map[string]*openAPIRequestBody    `yaml:"requestBodies"`
+		SecuritySchemes map[string]*openAPISecurityScheme `yaml:"securitySchemes"`
+	} `yaml:"components"`
+	Definitions         map[string]*openAPISchema         `yaml:"definitions"`
+	Parameters          map[string]*openAPIParameter      `yaml:"parameters"`
+	SecurityDefinitions map[string]*openAPISecurityScheme `yaml:"securityDefinitions"`
+	Security            []map[string][]string             `yaml:"security"`
+}
+
+type openAPIPathItem struct {
+	Parameters []*openAPIParameter `yaml:"parameters"`
+	Get        *openAPIOperation   `yaml:"get"`
+	Put        *openAPIOperation   `yaml:"put"`
+	Post       *openAPIOperation   `yaml:"post"`
+	Delete     *openAPIOperation   `yaml:"delete"`
+	Options    *openAPIOperation   `yaml:"options"`
+	Head       *openAPIOperation   `yaml:"head"`
+	Patch      *openAPIOperation   `yaml:"patch"`
+}
+
+type openAPIOperation struct {
+	Tags        []string               `yaml:"tags"`
+	Summary     string                 `yaml:"summary"`
+	OperationID string                 `yaml:"operationId"`
+	Parameters  []*openAPIParameter    `yaml:"parameters"`
+	RequestBody *openAPIRequestBody    `yaml:"requestBody"`
+	Consumes    []string               `yaml:"consumes"`
+	Security    *[]map[string][]string `yaml:"security"`
+}
+
+type openAPIParameter struct {
+	Ref     string         `yaml:"$ref"`
+	Name    string         `yaml:"name"`
+	In      string         `yaml:"in"`
+	Schema  *openAPISchema `yaml:"schema"`
+	Example any            `yaml:"example"`
+	Default any            `yaml:"default"`
+}
+
+type openAPIRequestBody struct {
+	Ref     string                      `yaml:"$ref"`
+	Content map[string]openAPIMediaType `yaml:"content"`
+}
+
+type openAPIMediaType struct {
+	Schema   *openAPISchema `yaml:"schema"`
+	Example  any            `yaml:"example"`
+	Examples map[string]struct {
+		Value any `yaml:"value"`
+	} `yaml:"examples"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `yaml:"$ref"`
+	Type       any                       `yaml:"type"`
+	Format     string                    `yaml:"format"`
+	Properties map[string]*openAPISchema `yaml:"properties"`
+	Items      *openAPISchema            `yaml:"items"`
+	Example    any                       `yaml:"example"`
+	Default    any                       `yaml:"default"`
+	Enum       []any                     `yaml:"enum"`
+	AllOf      []*openAPISchema          `yaml:"allOf"`
+	OneOf      []*openAPISchema          `yaml:"oneOf"`
+	AnyOf      []*openAPISchema          `yaml:"anyOf"`
+}
+
+type openAPISecurityScheme struct {
+	Type   string `yaml:"type"`
+	Scheme string `yaml:"scheme"`
+	Name   string `yaml:"name"`
+	In     string `yaml:"in"`
+	Flows  struct {
+		ClientCredentials *struct {
+			TokenURL string            `yaml:"tokenUrl"`
+			Scopes   map[string]string `yaml:"scopes"`
+		} `yaml:"clientCredentials"`
+	} `yaml:"flows"`
+	Flow     string            `yaml:"flow"`
+	TokenURL string            `yaml:"tokenUrl"`
+	Scopes   map[string]string `yaml:"scopes"`
+}
+
+// openAPIOperationRef is an operation along with the path and method it is declared under.
+type openAPIOperationRef struct {
+	method    string
+	path      string
+	operation *openAPIOperation
+	shared    []*openAPIParameter
+}
+
+// ImportOpenAPI reads an OpenAPI 3 or Swagger 2 document, in either JSON or YAML, and returns a group containing a
+// subgroup for each tag with a request for each operation. The path of the document is recorded on the group so
+// that it can later be re-synced with SyncOpenAPI.
+func ImportOpenAPI(data []byte, path string) (*state.CollectionItem, *Report, error) {
+	doc, err := parseOpenAPI(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	name := doc.Info.Title
+	if name == "" {
+		name = "OpenAPI"
+	}
+
+	report := NewReport()
+	group := state.NewCollectionGroup(name, nil)
+	group.Source = openAPIDocumentSource(path)
+	group.Authentication = doc.authentication(doc.Security, report)
+
+	for _, ref := range doc.operations() {
+		tag := ref.tag()
+
+		// operations are grouped by their first tag
+		tagGroup := findBySource(group, openAPITagSource(tag))
+		if tagGroup == nil {
+			tagGroup = state.NewCollectionGroup(tag, group)
+			tagGroup.Source = openAPITagSource(tag)
+			tagGroup.Authentication.Data = auth.NewInheritedAuthentication()
+			group.AddChild(tagGroup)
+		}
+
+		tagGroup.AddChild(doc.request(ref, tagGroup, report))
+	}
+
+	return group, report, nil
+}
+
+// SyncOpenAPI updates a group previously created by ImportOpenAPI with the operations declared in a newer version of
+// the document. New operations are added, the method and URL of existing operations are updated while preserving
+// any other changes made to them, and operations that no longer exist are removed.
+func SyncOpenAPI(group *state.CollectionItem, data []byte) (*Report, error) {
+	path, ok := OpenAPIDocumentPath(group)
+	if !ok {
+		return nil, errors.Errorf("%s was not imported from an OpenAPI document", group.Name)
+	}
+
+	latest, report, err := ImportOpenAPI(data, path)
+	if err != nil {
+		return nil, err
+	}
+
+	declared := map[string]bool{}
+	for _, tagGroup := range latest.Children {
+		existingGroup := findBySource(group, tagGroup.Source)
+		if existingGroup == nil {
+			existingGroup = state.NewCollectionGroup(tagGroup.Name, group)
+			existingGroup.Source = tagGroup.Source
+			existingGroup.Authentication = tagGroup.Authentication
+			group.AddChild(existingGroup)
+		}
+
+		for _, op := range tagGroup.Children {
+			declared[op.Source] = true
+
+			existing := findBySource(group, op.Source)
+			if existing == nil {
+				op.Parent = existingGroup
+				existingGroup.AddChild(op)
+				continue
+			}
+
+			existing.Method = op.Method
+			existing.URL = op.URL
+
+			// add newly declared headers without replacing values that may have been edited
+			for k, v := range op.Headers {
+				if _, ok := existing.Headers[k]; !ok {
+					existing.AddHeaders(k, v)
+				}
+			}
+
+			if existing.RequestBody == nil {
+				existing.RequestBody = op.RequestBody
+			}
+		}
+	}
+
+	// remove operations that are no longer declared in the document
+	var removed []*state.CollectionItem
+	walkItems(group, func(item *state.CollectionItem) {
+		if !item.IsGroup && isOpenAPIOperationSource(item.Source) && !declared[item.Source] {
+			removed = append(removed, item)
+		}
+	})
+
+	for _, item := range removed {
+		report.Warnf("%s was removed since %s is no longer declared", item.Name, item.Source)
+		_ = item.Parent.RemoveChild(item)
+		item.Parent = nil
+	}
+
+	return report, nil
+}
+
+// parseOpenAPI decodes an OpenAPI or Swagger document. Since JSON is a subset of YAML, both are handled by the same
+// decoder.
+func parseOpenAPI(data []byte) (*openAPIDocument, error) {
+	var doc openAPIDocument
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "not a valid OpenAPI document")
+	}
+
+	if strings.HasPrefix(doc.OpenAPI, "3.") || strings.HasPrefix(doc.Swagger, "2.") {
+		return &doc, nil
+	} else if doc.OpenAPI != "" {
+		return nil, errors.Errorf("unsupported OpenAPI version %s", doc.OpenAPI)
+	} else if doc.Swagger != "" {
+		return nil, errors.Errorf("unsupported Swagger version %s", doc.Swagger)
+	}
+
+	return nil, errors.New("not a valid OpenAPI document: missing version")
+}
+
+// isSwagger returns true if the document is a Swagger 2 document.
+func (d *openAPIDocument) isSwagger() bool {
+	return d.Swagger != ""
+}
+
+// baseURL returns the URL that operation paths are relative to.
+func (d *openAPIDocument) baseURL() string {
+	if d.isSwagger() {
+		if d.Host == "" {
+			return strings.TrimSuffix(d.BasePath, "/")
+		}
+
+		scheme := "https"
+		if len(d.Schemes) > 0 {
+			scheme = d.Schemes[0]
+		}
+
+		return strings.TrimSuffix(fmt.Sprintf("%s://%s%s", scheme, d.Host, d.BasePath), "/")
+	}
+
+	if len(d.Servers) == 0 {
+		return ""
+	}
+
+	// substitute server variables with their default values
+	server := d.Servers[0]
+	uri := server.URL
+	for k, v := range server.Variables {
+		uri = strings.ReplaceAll(uri, fmt.Sprintf("{%s}", k), v.Default)
+	}
+
+	return strings.TrimSuffix(uri, "/")
+}
+
+// operations returns all operations in the document, sorted by path and then by method.
+func (d *openAPIDocument) operations() []openAPIOperationRef {
+	paths := make([]string, 0, len(d.Paths))
+	for p := range d.Paths {
+		paths = append(paths, p)
+	}
+
+	sort.Strings(paths)
+
+	var refs []openAPIOperationRef
+	for _, p := range paths {
+		item := d.Paths[p]
+		methods := []struct {
+			method string
+			op     *openAPIOperation
+		}{
+			{"GET", item.Get},
+			{"POST", item.Post},
+			{"PUT", item.Put},
+			{"PATCH", item.Patch},
+			{"DELETE", item.Delete},
+			{"HEAD", item.Head},
+			{"OPTIONS", item.Options},
+		}
+
+		for _, m := range methods {
+			if m.op != nil {
+				refs = append(refs, openAPIOperationRef{
+					method:    m.method,
+					path:      p,
+					operation: m.op,
+					shared:    item.Parameters,
+				})
+			}
+		}
+	}
+
+	return refs
+}
+
+// request creates a collection request for an operation.
+func (d *openAPIDocument) request(ref openAPIOperationRef, parent *state.CollectionItem, report *Report) *state.CollectionItem {
+	op := ref.operation
+
+	name := op.Summary
+	if name == "" {
+		name = op.OperationID
+	}
+	if name == "" {
+		name = fmt.Sprintf("%s %s", ref.method, ref.path)
+	}
+
+	req := state.NewCollectionRequest(name, ref.method, "", parent)
+	req.Source = openAPIOperationSource(ref.method, ref.path)
+	req.InheritHeaders = true
+
+	// operation-level security overrides the document's security, otherwise it is inherited
+	if op.Security == nil {
+		req.Authentication.Data = auth.NewInheritedAuthentication()
+	} else {
+		req.Authentication = d.authentication(*op.Security, report)
+	}
+
+	// collect parameters, with those on the operation taking precedence over those shared by the path
+	params := map[string]*openAPIParameter{}
+	var order []string
+	for _, p := range append(ref.shared, op.Parameters...) {
+		p = d.resolveParameter(p)
+		if p == nil {
+			continue
+		}
+
+		key := fmt.Sprintf("%s:%s", p.In, p.Name)
+		if _, ok := params[key]; !ok {
+			order = append(order, key)
+		}
+
+		params[key] = p
+	}
+
+	// path parameters are written as variables, and then filled in with the values of those that are declared
+	path := openAPIPathPattern.ReplaceAllString(ref.path, "{{$1}}")
+	var query []string
+	for _, key := range order {
+		p := params[key]
+
+		switch p.In {
+		case "path":
+			path = strings.ReplaceAll(path, fmt.Sprintf("{{%s}}", p.Name), d.parameterValue(p))
+		case "query":
+			query = append(query, fmt.Sprintf("%s=%s", p.Name, d.parameterValue(p)))
+		case "header":
+			req.AddHeader(p.Name, d.parameterValue(p))
+		case "body":
+			req.RequestBody = d.bodyFromSchema(p.Schema, d.consumes(op), name, report)
+		case "formData":
+			report.Warnf("form parameter %s on %s is not supported", p.Name, name)
+		}
+	}
+
+	uri := d.baseURL() + path
+	if len(query) > 0 {
+		uri = fmt.Sprintf("%s?%s", uri, strings.Join(query, "&"))
+	}
+
+	req.URL = uri
+
+	if op.RequestBody != nil {
+		req.RequestBody = d.body(d.resolveRequestBody(op.RequestBody), name, report)
+	}
+
+	return req
+}
+
+// parameterValue returns the example or default value of a parameter, or a variable with its name.
+func (d *openAPIDocument) parameterValue(p *openAPIParameter) string {
+	if p.Example != nil {
+		return fmt.Sprint(p.Example)
+	} else if p.Default != nil {
+		return fmt.Sprint(p.Default)
+	} else if p.Schema != nil {
+		if s := d.resolveSchema(p.Schema); s != nil {
+			if s.Example != nil {
+				return fmt.Sprint(s.Example)
+			} else if s.Default != nil {
+				return fmt.Sprint(s.Default)
+			}
+		}
+	}
+
+	return fmt.Sprintf("{{%s}}", p.Name)
+}
+
+// consumes returns the content types an operation accepts in a Swagger 2 document.
+func (d *openAPIDocument) consumes(op *openAPIOperation) []string {
+	if len(op.Consumes) > 0 {
+		return op.Consumes
+	}
+
+	return d.Consumes
+}
+
+// body creates a request body from an OpenAPI 3 request body, preferring JSON content.
+func (d *openAPIDocument) body(rb *openAPIRequestBody, name string, report *Report) *state.RequestBody {
+	if rb == nil || len(rb.Content) == 0 {
+		return nil
+	}
+
+	contentTypes := make([]string, 0, len(rb.Content))
+	for ct := range rb.Content {
+		contentTypes = append(contentTypes, ct)
+	}
+
+	sort.Strings(contentTypes)
+	contentType := contentTypes[0]
+	for _, ct := range contentTypes {
+		if strings.Contains(ct, "json") {
+			contentType = ct
+			break
+		}
+	}
+
+	media := rb.Content[contentType]
+
+	example := media.Example
+	if example == nil && len(media.Examples) > 0 {
+		keys := make([]string, 0, len(media.Examples))
+		for k := range media.Examples {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+		example = media.Examples[keys[0]].Value
+	}
+
+	if example == nil {
+		return d.bodyFromSchema(media.Schema, []string{contentType}, name, report)
+	}
+
+	return d.bodyFromExample(example, contentType, name, report)
+}
+
+// bodyFromSchema creates a request body with an example generated from a schema.
+func (d *openAPIDocument) bodyFromSchema(schema *openAPISchema, contentTypes []string, name string, report *Report) *state.RequestBody {
+	if schema == nil {
+		return nil
+	}
+
+	contentType := "application/json"
+	if len(contentTypes) > 0 {
+		contentType = contentTypes[0]
+		for _, ct := range contentTypes {
+			if strings.Contains(ct, "json") {
+				contentType = ct
+				break
+			}
+		}
+	}
+
+	return d.bodyFromExample(d.example(schema, 0), contentType, name, report)
+}
+
+// bodyFromExample creates a request body containing the example value. Only JSON and plain text bodies are
+// supported, so other content types are imported as plain text.
+func (d *openAPIDocument) bodyFromExample(example any, contentType string, name string, report *Report) *state.RequestBody {
+	if strings.Contains(contentType, "json") {
+		data, err := json.MarshalIndent(normalizeYAML(example), "", "  ")
+		if err != nil {
+			report.Warnf("could not generate example body for %s: %s", name, err)
+			return nil
+		}
+
+		return &state.RequestBody{
+			Payload:     string(data),
+			ContentType: "application/json",
+		}
+	}
+
+	report.Warnf("%s body on %s was imported as plain text", contentType, name)
+
+	payload := ""
+	if s, ok := example.(string); ok {
+		payload = s
+	}
+
+	return &state.RequestBody{
+		Payload:     payload,
+		ContentType: "text/plain",
+	}
+}
+
+// example generates an example value for a schema.
+func (d *openAPIDocument) example(schema *openAPISchema, depth int) any {
+	schema = d.resolveSchema(schema)
+	if schema == nil || depth > openAPIMaxSchemaDepth {
+		return nil
+	}
+
+	if schema.Example != nil {
+		return schema.Example
+	} else if schema.Default != nil {
+		return schema.Default
+	} else if len(schema.Enum) > 0 {
+		return schema.Enum[0]
+	}
+
+	if len(schema.AllOf) > 0 {
+		merged := map[string]any{}
+		for _, s := range schema.AllOf {
+			if obj, ok := d.example(s, depth+1).(map[string]any); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+
+		return merged
+	} else if len(schema.OneOf) > 0 {
+		return d.example(schema.OneOf[0], depth+1)
+	} else if len(schema.AnyOf) > 0 {
+		return d.example(schema.AnyOf[0], depth+1)
+	}
+
+	switch schemaType(schema) {
+	case "object":
+		obj := map[string]any{}
+		for k, v := range schema.Properties {
+			obj[k] = d.example(v, depth+1)
+		}
+
+		return obj
+	case "array":
+		if schema.Items == nil {
+			return []any{}
+		}
+
+		return []any{d.example(schema.Items, depth+1)}
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	case "string":
+		switch schema.Format {
+		case "date":
+			return "2006-01-02"
+		case "date-time":
+			return "2006-01-02T15:04:05Z"
+		case "uuid":
+			return "00000000-0000-0000-0000-000000000000"
+		case "email":
+			return "user@example.com"
+		default:
+			return "string"
+		}
+	default:
+		return nil
+	}
+}
+
+// authentication maps the first supported security requirement to a lull authentication scheme.
+func (d *openAPIDocument) authentication(requirements []map[string][]string, report *Report) state.ItemAuthentication {
+	schemes := d.Components.SecuritySchemes
+	if d.isSwagger() {
+		schemes = d.SecurityDefinitions
+	}
+
+	for _, req := range requirements {
+		names := make([]string, 0, len(req))
+		for k := range req {
+			names = append(names, k)
+		}
+
+		sort.Strings(names)
+		for _, name := range names {
+			scheme, ok := schemes[name]
+			if !ok {
+				report.Warnf("security scheme %s is not declared", name)
+				continue
+			}
+
+			switch {
+			case scheme.Type == "basic" || (scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "basic")):
+				return state.ItemAuthentication{Data: auth.NewBasicAuthentication("", "")}
+			case scheme.Type == "http" && strings.EqualFold(scheme.Scheme, "bearer"):
+				return state.ItemAuthentication{Data: auth.NewBearerAuthentication("")}
+			case scheme.Type == "oauth2":
+				if tokenURL, scopes, ok := scheme.clientCredentials(); ok {
+					return state.ItemAuthentication{
+						Data: auth.NewOAuth2RequestAuthentication(tokenURL, "", "", "client_credentials",
+							strings.Join(scopes, " ")),
+					}
+				}
+
+				report.Warnf("security scheme %s does not support the client credentials flow", name)
+			default:
+				report.Warnf("security scheme %s of type %s is not supported", name, scheme.Type)
+			}
+		}
+	}
+
+	return state.ItemAuthentication{}
+}
+
+// clientCredentials returns the token URL and sorted scopes of an OAuth2 client credentials flow.
+func (s *openAPISecurityScheme) clientCredentials() (string, []string, bool) {
+	var tokenURL string
+	var scopes map[string]string
+
+	if flow := s.Flows.ClientCredentials; flow != nil {
+		tokenURL, scopes = flow.TokenURL, flow.Scopes
+	} else if s.Flow == "application" {
+		tokenURL, scopes = s.TokenURL, s.Scopes
+	} else {
+		return "", nil, false
+	}
+
+	keys := make([]string, 0, len(scopes))
+	for k := range scopes {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	return tokenURL, keys, true
+}
+
+func (d *openAPIDocument) resolveSchema(schema *openAPISchema) *openAPISchema {
+	for i := 0; schema != nil && schema.Ref != "" && i < openAPIMaxSchemaDepth; i++ {
+		name := refName(schema.Ref)
+		if d.isSwagger() {
+			schema = d.Definitions[name]
+		} else {
+			schema = d.Components.Schemas[name]
+		}
+	}
+
+	return schema
+}
+
+func (d *openAPIDocument) resolveParameter(param *openAPIParameter) *openAPIParameter {
+	if param == nil || param.Ref == "" {
+		return param
+	}
+
+	if d.isSwagger() {
+		return d.Parameters[refName(param.Ref)]
+	}
+
+	return d.Components.Parameters[refName(param.Ref)]
+}
+
+func (d *openAPIDocument) resolveRequestBody(body *openAPIRequestBody) *openAPIRequestBody {
+	if body == nil || body.Ref == "" {
+		return body
+	}
+
+	return d.Components.RequestBodies[refName(body.Ref)]
+}
+
+// tag returns the name of the group the operation belongs to.
+func (r openAPIOperationRef) tag() string {
+	if len(r.operation.Tags) == 0 {
+		return openAPIUntagged
+	}
+
+	return r.operation.Tags[0]
+}
+
+// schemaType returns the type of schema. OpenAPI 3.1 allows a list of types, in which case the first non-null type
+// is used.
+func schemaType(schema *openAPISchema) string {
+	switch t := schema.Type.(type) {
+	case string:
+		return t
+	case []any:
+		for _, i := range t {
+			if s, ok := i.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+
+	if len(schema.Properties) > 0 {
+		return "object"
+	}
+
+	return ""
+}
+
+// refName returns the name of the component a local reference, such as "#/components/schemas/User", points to.
+func refName(ref string) string {
+	return ref[strings.LastIndex(ref, "/")+1:]
+}
+
+// normalizeYAML converts maps decoded from YAML with non-string keys so that they can be encoded as JSON.
+func normalizeYAML(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		m := map[string]any{}
+		for k, i := range t {
+			m[k] = normalizeYAML(i)
+		}
+
+		return m
+	case map[any]any:
+		m := map[string]any{}
+		for k, i := range t {
+			m[fmt.Sprint(k)] = normalizeYAML(i)
+		}
+
+		return m
+	case []any:
+		s := make([]any, len(t))
+		for i, e := range t {
+			s[i] = normalizeYAML(e)
+		}
+
+		return s
+	default:
+		return v
+	}
+}
+
+// OpenAPIDocumentPath returns the path of the OpenAPI document a group was imported from, if any.
+func OpenAPIDocumentPath(item *state.CollectionItem) (string, bool) {
+	if !item.IsGroup || !strings.HasPrefix(item.Source, "openapi:file:") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(item.Source, "openapi:file:"), true
+}
+
+func openAPIDocumentSource(path string) string {
+	return fmt.Sprintf("openapi:file:%s", path)
+}
+
+func openAPITagSource(tag string) string {
+	return fmt.Sprintf("openapi:tag:%s", tag)
+}
+
+func openAPIOperationSource(method, path string) string {
+	return fmt.Sprintf("openapi:op:%s %s", method, path)
+}
+
+func isOpenAPIOperationSource(source string) bool {
+	return strings.HasPrefix(source, "openapi:op:")
+}
+
+// findBySource returns the first descendant of the item with the given source.
+func findBySource(item *state.CollectionItem, source string) *state.CollectionItem {
+	var found *state.CollectionItem
+	walkItems(item, func(i *state.CollectionItem) {
+		if found == nil && i != item && i.Source == source {
+			found = i
+		}
+	})
+
+	return found
+}
+
+// walkItems visits the item and all of its descendants.
+func walkItems(item *state.CollectionItem, visitor func(item *state.CollectionItem)) {
+	visitor(item)
+
+	for _, c := range item.Children {
+		walkItems(c, visitor)
+	}
+}
@@ -0,0 +1,225 @@
+package formats
+
+import (
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const openAPIFixture = `
+openapi: 3.0.3
+info:
+  title: Pet Store
+servers:
+  - url: https://{env}.example.com/v1
+    variables:
+      env:
+        default: api
+security:
+  - bearerAuth: []
+paths:
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+    get:
+      tags: [pets]
+      summary: Get a pet
+      parameters:
+        - name: verbose
+          in: query
+          schema:
+            type: boolean
+            default: true
+        - name: X-Trace
+          in: header
+  /pets:
+    post:
+      tags: [pets]
+      operationId: createPet
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+  /health:
+    get:
+      security: []
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        name:
+          type: string
+          example: Rex
+        age:
+          type: integer
+        tags:
+          type: array
+          items:
+            type: string
+  securitySchemes:
+    bearerAuth:
+      type: http
+      scheme: bearer
+`
+
+const swaggerFixture = `{
+  "swagger": "2.0",
+  "info": {"title": "Legacy"},
+  "host": "legacy.example.com",
+  "basePath": "/api",
+  "schemes": ["http"],
+  "securityDefinitions": {
+    "basic": {"type": "basic"}
+  },
+  "security": [{"basic": []}],
+  "paths": {
+    "/orders": {
+      "post": {
+        "tags": ["orders"],
+        "summary": "Create order",
+        "consumes": ["application/json"],
+        "parameters": [
+          {"name": "body", "in": "body", "schema": {"$ref": "#/definitions/Order"}}
+        ]
+      }
+    }
+  },
+  "definitions": {
+    "Order": {
+      "type": "object",
+      "properties": {
+        "id": {"type": "string", "format": "uuid"}
+      }
+    }
+  }
+}`
+
+func Test_ImportOpenAPI_OpenAPI3(t *testing.T) {
+	group, _, err := ImportOpenAPI([]byte(openAPIFixture), "/tmp/pets.yaml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Pet Store", group.Name)
+	assert.Equal(t, auth.NewBearerAuthentication(""), group.Authentication.Data)
+	assert.Len(t, group.Children, 2)
+
+	untagged := group.Children[0]
+	assert.Equal(t, "default", untagged.Name)
+	health := untagged.Children[0]
+	assert.Equal(t, "https://api.example.com/v1/health", health.URL)
+	assert.True(t, health.Authentication.None())
+
+	pets := group.Children[1]
+	assert.Equal(t, "pets", pets.Name)
+	assert.Len(t, pets.Children, 2)
+
+	create := pets.Children[0]
+	assert.Equal(t, "createPet", create.Name)
+	assert.Equal(t, "POST", create.Method)
+	assert.True(t, create.Authentication.Inherited())
+	assert.Equal(t, &state.RequestBody{
+		Payload:     "{\n  \"age\": 0,\n  \"name\": \"Rex\",\n  \"tags\": [\n    \"string\"\n  ]\n}",
+		ContentType: "application/json",
+	}, create.RequestBody)
+
+	get := pets.Children[1]
+	assert.Equal(t, "Get a pet", get.Name)
+	assert.Equal(t, "https://api.example.com/v1/pets/{{petId}}?verbose=true", get.URL)
+	assert.Equal(t, map[string][]string{"X-Trace": {"{{X-Trace}}"}}, get.Headers)
+}
+
+func Test_ImportOpenAPI_PathParameters(t *testing.T) {
+	group, _, err := ImportOpenAPI([]byte(`
+openapi: 3.0.3
+info:
+  title: Orders
+servers:
+  - url: https://api.example.com
+paths:
+  /orders/{orderId}/items/{itemId}/{version}:
+    get:
+      parameters:
+        - name: orderId
+          in: path
+          example: 42
+        - name: itemId
+          in: path
+        - name: version
+          in: path
+          schema:
+            type: string
+            default: v1
+`), "/tmp/orders.yaml")
+	assert.NoError(t, err)
+
+	get := group.Children[0].Children[0]
+	assert.Equal(t, "https://api.example.com/orders/42/items/{{itemId}}/v1", get.URL)
+
+	group.Variables = map[string]string{"itemId": "7"}
+	assert.Equal(t, "https://api.example.com/orders/42/items/7/v1", get.ExpandVariables(get.URL))
+}
+
+func Test_ImportOpenAPI_Swagger2(t *testing.T) {
+	group, _, err := ImportOpenAPI([]byte(swaggerFixture), "/tmp/legacy.json")
+
+	assert.NoError(t, err)
+	assert.Equal(t, auth.NewBasicAuthentication("", ""), group.Authentication.Data)
+
+	create := group.Children[0].Children[0]
+	assert.Equal(t, "http://legacy.example.com/api/orders", create.URL)
+	assert.Equal(t, &state.RequestBody{
+		Payload:     "{\n  \"id\": \"00000000-0000-0000-0000-000000000000\"\n}",
+		ContentType: "application/json",
+	}, create.RequestBody)
+}
+
+func Test_ImportOpenAPI_MissingVersion(t *testing.T) {
+	_, _, err := ImportOpenAPI([]byte(`info: {title: nope}`), "/tmp/nope.yaml")
+
+	assert.Error(t, err)
+}
+
+func Test_SyncOpenAPI(t *testing.T) {
+	group, _, err := ImportOpenAPI([]byte(openAPIFixture), "/tmp/pets.yaml")
+	assert.NoError(t, err)
+
+	// simulate user edits to an existing operation
+	get := group.Children[1].Children[1]
+	get.Name = "Fetch pet"
+	get.AddHeader("X-Trace", "custom")
+
+	updated := `
+openapi: 3.0.3
+info:
+  title: Pet Store
+servers:
+  - url: https://api.example.com/v2
+paths:
+  /pets/{petId}:
+    get:
+      tags: [pets]
+  /owners:
+    get:
+      tags: [owners]
+`
+
+	report, err := SyncOpenAPI(group, []byte(updated))
+
+	assert.NoError(t, err)
+	assert.Len(t, report.Warnings, 2)
+
+	pets := group.Children[1]
+	assert.Len(t, pets.Children, 1)
+	assert.Equal(t, get, pets.Children[0])
+	assert.Equal(t, "Fetch pet", get.Name)
+	assert.Equal(t, "https://api.example.com/v2/pets/{{petId}}", get.URL)
+
+	owners := group.Children[2]
+	assert.Equal(t, "owners", owners.Name)
+	assert.Len(t, owners.Children, 1)
+	assert.Equal(t, owners, owners.Children[0].Parent)
+}
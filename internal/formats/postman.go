@@ -0,0 +1,572 @@
+package formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/pkg/errors"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const postmanSchema = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+type postmanCollection struct {
+	Info     postmanInfo       `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+	Event    []json.RawMessage `json:"event,omitempty"`
+}
+
+type postmanInfo struct {
+	PostmanID string `json:"_postman_id,omitempty"`
+	Name      string `json:"name"`
+	Schema    string `json:"schema"`
+}
+
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item,omitempty"`
+	Request  *postmanRequest   `json:"request,omitempty"`
+	Response []json.RawMessage `json:"response,omitempty"`
+	Auth     *postmanAuth      `json:"auth,omitempty"`
+	Variable []postmanVariable `json:"variable,omitempty"`
+	Event    []json.RawMessage `json:"event,omitempty"`
+}
+
+type postmanRequest struct {
+	Method string          `json:"method"`
+	Header []postmanHeader `json:"header"`
+	Body   *postmanBody    `json:"body,omitempty"`
+	URL    postmanURL      `json:"url"`
+	Auth   *postmanAuth    `json:"auth,omitempty"`
+}
+
+type postmanHeader struct {
+	Key      string `json:"key"`
+	Value    string `json:"value"`
+	Disabled bool   `json:"disabled,omitempty"`
+}
+
+type postmanBody struct {
+	Mode       string              `json:"mode"`
+	Raw        string              `json:"raw,omitempty"`
+	URLEncoded []postmanHeader     `json:"urlencoded,omitempty"`
+	FormData   []postmanHeader     `json:"formdata,omitempty"`
+	GraphQL    *postmanGraphQL     `json:"graphql,omitempty"`
+	Options    *postmanBodyOptions `json:"options,omitempty"`
+}
+
+type postmanGraphQL struct {
+	Query     string `json:"query"`
+	Variables string `json:"variables,omitempty"`
+}
+
+type postmanBodyOptions struct {
+	Raw struct {
+		Language string `json:"language"`
+	} `json:"raw"`
+}
+
+type postmanURL struct {
+	Raw string
+}
+
+type postmanAuth struct {
+	Type   string                 `json:"type"`
+	Basic  []postmanAuthAttribute `json:"basic,omitempty"`
+	Bearer []postmanAuthAttribute `json:"bearer,omitempty"`
+	OAuth2 []postmanAuthAttribute `json:"oauth2,omitempty"`
+}
+
+type postmanAuthAttribute struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+	Type  string `json:"type"`
+}
+
+type postmanVariable struct {
+	Key   string `json:"key"`
+	Value any    `json:"value"`
+}
+
+// MarshalJSON writes the item, ensuring that folders always contain a list of items even if they are empty.
+func (p postmanItem) MarshalJSON() ([]byte, error) {
+	// use an alias type to avoid recursing into this method
+	type item postmanItem
+	if p.Request != nil {
+		return json.Marshal(item(p))
+	}
+
+	return json.Marshal(struct {
+		item
+		Item []postmanItem `json:"item"`
+	}{item(p), p.Item})
+}
+
+// UnmarshalJSON accepts a request given either as an object or as a plain URL string.
+func (r *postmanRequest) UnmarshalJSON(b []byte) error {
+	var uri string
+	if err := json.Unmarshal(b, &uri); err == nil {
+		r.Method = "GET"
+		r.URL.Raw = uri
+		return nil
+	}
+
+	// use an alias type to avoid recursing into this method
+	type request postmanRequest
+	var req request
+	if err := json.Unmarshal(b, &req); err != nil {
+		return err
+	}
+
+	*r = postmanRequest(req)
+	return nil
+}
+
+// UnmarshalJSON accepts a URL given either as a plain string or as an object with its components.
+func (u *postmanURL) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &u.Raw); err == nil {
+		return nil
+	}
+
+	var obj struct {
+		Raw      string   `json:"raw"`
+		Protocol string   `json:"protocol"`
+		Host     []string `json:"host"`
+		Path     []string `json:"path"`
+		Query    []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		} `json:"query"`
+	}
+
+	if err := json.Unmarshal(b, &obj); err != nil {
+		return err
+	}
+
+	if obj.Raw != "" {
+		u.Raw = obj.Raw
+		return nil
+	}
+
+	// reassemble the url from its components if no raw form is provided
+	raw := strings.Join(obj.Host, ".")
+	if obj.Protocol != "" {
+		raw = fmt.Sprintf("%s://%s", obj.Protocol, raw)
+	}
+
+	if len(obj.Path) > 0 {
+		raw = fmt.Sprintf("%s/%s", raw, strings.Join(obj.Path, "/"))
+	}
+
+	var query []string
+	for _, q := range obj.Query {
+		query = append(query, fmt.Sprintf("%s=%s", q.Key, q.Value))
+	}
+
+	if len(query) > 0 {
+		raw = fmt.Sprintf("%s?%s", raw, strings.Join(query, "&"))
+	}
+
+	u.Raw = raw
+	return nil
+}
+
+// MarshalJSON writes the URL in its raw string form.
+func (u postmanURL) MarshalJSON() ([]byte, error) {
+	return json.Marshal(u.Raw)
+}
+
+// ImportPostman reads a Postman Collection v2.1 document and returns a group containing its folders and requests.
+// Collection and folder variables are imported as variables on their groups.
+func ImportPostman(data []byte) (*state.CollectionItem, *Report, error) {
+	var collection postmanCollection
+	if err := json.Unmarshal(data, &collection); err != nil {
+		return nil, nil, errors.Wrap(err, "not a valid Postman collection")
+	}
+
+	if !strings.Contains(collection.Info.Schema, "collection/v2") {
+		return nil, nil, errors.Errorf("unsupported Postman schema: %s", collection.Info.Schema)
+	}
+
+	report := NewReport()
+	if !strings.Contains(collection.Info.Schema, "v2.1") {
+		report.Warnf("collection uses schema %s; only v2.1 is fully supported", collection.Info.Schema)
+	}
+
+	i := &postmanImporter{
+		report: report,
+	}
+
+	if len(collection.Event) > 0 {
+		report.Warnf("collection scripts are not supported")
+	}
+
+	name := collection.Info.Name
+	if name == "" {
+		name = "Postman"
+	}
+
+	group := state.NewCollectionGroup(name, nil)
+	group.Authentication = i.authentication(collection.Auth, name, false)
+	i.importVariables(group, collection.Variable)
+
+	for _, item := range collection.Item {
+		i.importItem(item, group)
+	}
+
+	return group, report, nil
+}
+
+// ExportPostman returns a Postman Collection v2.1 document for the group and all of its descendants.
+func ExportPostman(group *state.CollectionItem) ([]byte, *Report, error) {
+	if !group.IsGroup {
+		return nil, nil, errors.New("only groups can be exported")
+	}
+
+	e := &postmanExporter{
+		report: NewReport(),
+	}
+
+	collection := postmanCollection{
+		Info: postmanInfo{
+			PostmanID: group.UUID.String(),
+			Name:      group.Name,
+			Schema:    postmanSchema,
+		},
+		Item:     []postmanItem{},
+		Auth:     e.authentication(group),
+		Variable: e.variables(group),
+	}
+
+	e.warnGroupHeaders(group)
+	for _, child := range group.Children {
+		collection.Item = append(collection.Item, e.exportItem(child))
+	}
+
+	data, err := json.MarshalIndent(collection, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, e.report, nil
+}
+
+type postmanImporter struct {
+	report *Report
+}
+
+func (i *postmanImporter) importItem(item postmanItem, parent *state.CollectionItem) {
+	if len(item.Event) > 0 {
+		i.report.Warnf("scripts on %s are not supported", item.Name)
+	}
+
+	// items without a request are folders
+	if item.Request == nil {
+		group := state.NewCollectionGroup(item.Name, parent)
+		group.Authentication = i.authentication(item.Auth, item.Name, true)
+		i.importVariables(group, item.Variable)
+		parent.AddChild(group)
+
+		for _, child := range item.Item {
+			i.importItem(child, group)
+		}
+
+		return
+	}
+
+	req := item.Request
+	method := req.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	request := state.NewCollectionRequest(item.Name, strings.ToUpper(method), req.URL.Raw, parent)
+	request.Authentication = i.authentication(req.Auth, item.Name, true)
+
+	for _, h := range req.Header {
+		if h.Disabled {
+			continue
+		}
+
+		request.AddHeader(h.Key, h.Value)
+	}
+
+	request.RequestBody = i.body(req.Body, item.Name)
+
+	if len(item.Response) > 0 {
+		i.report.Warnf("saved responses on %s were not imported", item.Name)
+	}
+
+	parent.AddChild(request)
+}
+
+// authentication maps a Postman auth definition. Items without an auth definition inherit from their parent in
+// Postman, so the same applies to the imported item if inherit is true.
+func (i *postmanImporter) authentication(pa *postmanAuth, name string, inherit bool) state.ItemAuthentication {
+	if pa == nil {
+		if inherit {
+			return state.ItemAuthentication{Data: auth.NewInheritedAuthentication()}
+		}
+
+		return state.ItemAuthentication{}
+	}
+
+	switch pa.Type {
+	case "noauth":
+		return state.ItemAuthentication{}
+	case "basic":
+		attrs := i.attributes(pa.Basic)
+		return state.ItemAuthentication{
+			Data: auth.NewBasicAuthentication(attrs["username"], attrs["password"]),
+		}
+	case "bearer":
+		attrs := i.attributes(pa.Bearer)
+		return state.ItemAuthentication{
+			Data: auth.NewBearerAuthentication(attrs["token"]),
+		}
+	case "oauth2":
+		attrs := i.attributes(pa.OAuth2)
+		grantType := attrs["grant_type"]
+		if grantType == "" {
+			grantType = "client_credentials"
+		}
+
+		if grantType != "client_credentials" {
+			i.report.Warnf("OAuth2 grant type %s on %s may not be supported", grantType, name)
+		}
+
+		return state.ItemAuthentication{
+			Data: auth.NewOAuth2RequestAuthentication(attrs["accessTokenUrl"], attrs["clientId"],
+				attrs["clientSecret"], grantType, attrs["scope"]),
+		}
+	default:
+		i.report.Warnf("%s authentication on %s is not supported", pa.Type, name)
+		return state.ItemAuthentication{}
+	}
+}
+
+func (i *postmanImporter) attributes(attrs []postmanAuthAttribute) map[string]string {
+	m := map[string]string{}
+	for _, a := range attrs {
+		m[a.Key] = fmt.Sprint(a.Value)
+	}
+
+	return m
+}
+
+func (i *postmanImporter) body(body *postmanBody, name string) *state.RequestBody {
+	if body == nil {
+		return nil
+	}
+
+	switch body.Mode {
+	case "raw":
+		contentType := "text/plain"
+		if body.Options != nil && body.Options.Raw.Language == "json" {
+			contentType = "application/json"
+		}
+
+		return &state.RequestBody{
+			Payload:     body.Raw,
+			ContentType: contentType,
+		}
+	case "urlencoded":
+		form := url.Values{}
+		for _, f := range body.URLEncoded {
+			if !f.Disabled {
+				form.Add(f.Key, f.Value)
+			}
+		}
+
+		// references to variables are kept so they can still be expanded when the request is sent
+		i.report.Warnf("url-encoded body on %s was converted to plain text", name)
+		return &state.RequestBody{
+			Payload:     strings.NewReplacer("%7B%7B", "{{", "%7D%7D", "}}").Replace(form.Encode()),
+			ContentType: "text/plain",
+		}
+	case "graphql":
+		if body.GraphQL == nil {
+			return nil
+		}
+
+		payload := map[string]any{"query": body.GraphQL.Query}
+		if body.GraphQL.Variables != "" {
+			payload["variables"] = json.RawMessage(body.GraphQL.Variables)
+		}
+
+		data, err := json.MarshalIndent(payload, "", "  ")
+		if err != nil {
+			i.report.Warnf("GraphQL body on %s could not be converted: %s", name, err)
+			return nil
+		}
+
+		return &state.RequestBody{
+			Payload:     string(data),
+			ContentType: "application/json",
+		}
+	case "":
+		return nil
+	default:
+		i.report.Warnf("%s body on %s is not supported", body.Mode, name)
+		return nil
+	}
+}
+
+// importVariables adds the Postman variables to a group. Variables whose names suggest that they hold secret values
+// are marked as secret.
+func (i *postmanImporter) importVariables(group *state.CollectionItem, vars []postmanVariable) {
+	if len(vars) == 0 {
+		return
+	}
+
+	group.Variables = map[string]string{}
+	for _, v := range vars {
+		group.Variables[v.Key] = fmt.Sprint(v.Value)
+		if state.SuggestSecretVariable(v.Key) {
+			group.SetSecretVariable(v.Key, true)
+		}
+	}
+}
+
+type postmanExporter struct {
+	report *Report
+}
+
+func (e *postmanExporter) exportItem(item *state.CollectionItem) postmanItem {
+	if item.IsGroup {
+		e.warnGroupHeaders(item)
+
+		folder := postmanItem{
+			Name:     item.Name,
+			Item:     []postmanItem{},
+			Auth:     e.authentication(item),
+			Variable: e.variables(item),
+		}
+
+		for _, child := range item.Children {
+			folder.Item = append(folder.Item, e.exportItem(child))
+		}
+
+		return folder
+	}
+
+	// postman does not support inheriting headers, so they are copied into each request instead
+	headers := item.Headers
+	if item.InheritHeaders {
+		headers = item.EffectiveHeaders()
+		if len(item.InheritedHeaders()) > 0 {
+			e.report.Warnf("inherited headers were copied into %s", item.Name)
+		}
+	}
+
+	req := &postmanRequest{
+		Method: item.Method,
+		Header: []postmanHeader{},
+		URL:    postmanURL{Raw: item.URL},
+		Auth:   e.authentication(item),
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range headers[k] {
+			req.Header = append(req.Header, postmanHeader{Key: k, Value: v})
+		}
+	}
+
+	if item.RequestBody != nil {
+		req.Body = &postmanBody{
+			Mode:    "raw",
+			Raw:     item.RequestBody.Payload,
+			Options: &postmanBodyOptions{},
+		}
+
+		if strings.Contains(item.RequestBody.ContentType, "json") {
+			req.Body.Options.Raw.Language = "json"
+		} else {
+			req.Body.Options.Raw.Language = "text"
+		}
+	}
+
+	return postmanItem{
+		Name:     item.Name,
+		Request:  req,
+		Response: []json.RawMessage{},
+	}
+}
+
+// authentication returns the Postman auth definition for an item. Items that inherit authentication have no auth
+// definition, which Postman treats the same way.
+func (e *postmanExporter) authentication(item *state.CollectionItem) *postmanAuth {
+	attr := func(key, value string) postmanAuthAttribute {
+		return postmanAuthAttribute{Key: key, Value: value, Type: "string"}
+	}
+
+	switch data := item.Authentication.Data.(type) {
+	case nil:
+		return &postmanAuth{Type: "noauth"}
+	case *auth.InheritedAuthentication:
+		return nil
+	case *auth.BasicAuthentication:
+		return &postmanAuth{
+			Type: "basic",
+			Basic: []postmanAuthAttribute{
+				attr("username", data.Username),
+				attr("password", data.Password),
+			},
+		}
+	case *auth.BearerAuthentication:
+		return &postmanAuth{
+			Type: "bearer",
+			Bearer: []postmanAuthAttribute{
+				attr("token", data.Token),
+			},
+		}
+	case *auth.OAuth2RequestAuthentication:
+		return &postmanAuth{
+			Type: "oauth2",
+			OAuth2: []postmanAuthAttribute{
+				attr("accessTokenUrl", data.TokenURL),
+				attr("clientId", data.ClientID),
+				attr("clientSecret", data.ClientSecret),
+				attr("grant_type", data.GrantType),
+				attr("scope", data.Scope),
+			},
+		}
+	default:
+		e.report.Warnf("%s authentication on %s is not supported", data.Type(), item.Name)
+		return nil
+	}
+}
+
+// variables returns the Postman variables for the variables defined on a group, sorted by name.
+func (e *postmanExporter) variables(group *state.CollectionItem) []postmanVariable {
+	names := make([]string, 0, len(group.Variables))
+	for k := range group.Variables {
+		names = append(names, k)
+	}
+
+	sort.Strings(names)
+
+	var vars []postmanVariable
+	for _, k := range names {
+		vars = append(vars, postmanVariable{Key: k, Value: group.Variables[k]})
+	}
+
+	return vars
+}
+
+func (e *postmanExporter) warnGroupHeaders(group *state.CollectionItem) {
+	if len(group.Headers) > 0 {
+		e.report.Warnf("headers on group %s can only be exported into requests that inherit them", group.Name)
+	}
+}
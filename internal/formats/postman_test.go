@@ -0,0 +1,133 @@
+package formats
+
+import (
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const postmanFixture = `{
+  "info": {
+    "name": "Partner API",
+    "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+  },
+  "auth": {
+    "type": "basic",
+    "basic": [
+      {"key": "username", "value": "alice", "type": "string"},
+      {"key": "password", "value": "secret", "type": "string"}
+    ]
+  },
+  "variable": [
+    {"key": "baseUrl", "value": "https://api.example.com"}
+  ],
+  "item": [
+    {
+      "name": "Users",
+      "variable": [
+        {"key": "role", "value": "admin"},
+        {"key": "apiKey", "value": "k3y"}
+      ],
+      "item": [
+        {
+          "name": "Create user",
+          "request": {
+            "method": "POST",
+            "header": [
+              {"key": "Accept", "value": "application/json"},
+              {"key": "X-Disabled", "value": "nope", "disabled": true}
+            ],
+            "body": {
+              "mode": "raw",
+              "raw": "{\"name\": \"bob\"}",
+              "options": {"raw": {"language": "json"}}
+            },
+            "url": {"raw": "{{baseUrl}}/users?role={{role}}", "host": ["{{baseUrl}}"], "path": ["users"]}
+          }
+        }
+      ]
+    },
+    {
+      "name": "Health",
+      "request": "{{baseUrl}}/health"
+    },
+    {
+      "name": "Token",
+      "request": {
+        "method": "GET",
+        "url": "{{baseUrl}}/token",
+        "auth": {
+          "type": "bearer",
+          "bearer": [{"key": "token", "value": "abc123", "type": "string"}]
+        }
+      }
+    }
+  ]
+}`
+
+func Test_ImportPostman(t *testing.T) {
+	group, report, err := ImportPostman([]byte(postmanFixture))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Partner API", group.Name)
+	assert.Equal(t, auth.NewBasicAuthentication("alice", "secret"), group.Authentication.Data)
+	assert.Equal(t, map[string]string{"baseUrl": "https://api.example.com"}, group.Variables)
+	assert.Len(t, group.Children, 3)
+	assert.True(t, report.Empty())
+
+	folder := group.Children[0]
+	assert.True(t, folder.IsGroup)
+	assert.True(t, folder.Authentication.Inherited())
+	assert.Equal(t, map[string]string{"role": "admin", "apiKey": "k3y"}, folder.Variables)
+	assert.Equal(t, []string{"apiKey"}, folder.SecretVariables)
+
+	create := folder.Children[0]
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, "{{baseUrl}}/users?role={{role}}", create.URL)
+	assert.Equal(t, "https://api.example.com/users?role=admin", create.ExpandVariables(create.URL))
+	assert.Equal(t, map[string][]string{"Accept": {"application/json"}}, create.Headers)
+	assert.Equal(t, &state.RequestBody{Payload: `{"name": "bob"}`, ContentType: "application/json"}, create.RequestBody)
+	assert.True(t, create.Authentication.Inherited())
+
+	health := group.Children[1]
+	assert.Equal(t, "GET", health.Method)
+	assert.Equal(t, "{{baseUrl}}/health", health.URL)
+
+	token := group.Children[2]
+	assert.Equal(t, auth.NewBearerAuthentication("abc123"), token.Authentication.Data)
+}
+
+func Test_ImportPostman_UnsupportedSchema(t *testing.T) {
+	_, _, err := ImportPostman([]byte(`{"info": {"name": "Old", "schema": "https://schema.getpostman.com/json/collection/v1.0.0/collection.json"}}`))
+
+	assert.Error(t, err)
+}
+
+func Test_ExportPostman_RoundTrip(t *testing.T) {
+	group, _, err := ImportPostman([]byte(postmanFixture))
+	assert.NoError(t, err)
+
+	data, report, err := ExportPostman(group)
+	assert.NoError(t, err)
+	assert.True(t, report.Empty())
+
+	reimported, _, err := ImportPostman(data)
+	assert.NoError(t, err)
+
+	assert.Equal(t, group.Name, reimported.Name)
+	assert.Equal(t, group.Authentication, reimported.Authentication)
+	assert.Equal(t, group.Variables, reimported.Variables)
+	assert.Len(t, reimported.Children, 3)
+	assert.Equal(t, group.Children[0].Variables, reimported.Children[0].Variables)
+	assert.Equal(t, group.Children[0].Children[0].URL, reimported.Children[0].Children[0].URL)
+	assert.Equal(t, group.Children[0].Children[0].RequestBody, reimported.Children[0].Children[0].RequestBody)
+	assert.True(t, reimported.Children[0].Children[0].Authentication.Inherited())
+	assert.Equal(t, group.Children[2].Authentication, reimported.Children[2].Authentication)
+}
+
+func Test_ExportPostman_RejectsRequest(t *testing.T) {
+	_, _, err := ExportPostman(state.NewCollectionRequest("req", "GET", "", nil))
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,25 @@
+package formats
+
+import "fmt"
+
+// Report collects items and features that could not be fully mapped during an import or export.
+type Report struct {
+	Warnings []string
+}
+
+// NewReport returns an empty Report.
+func NewReport() *Report {
+	return &Report{
+		Warnings: []string{},
+	}
+}
+
+// Warnf adds a warning with a format and template args.
+func (r *Report) Warnf(format string, args ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf(format, args...))
+}
+
+// Empty returns true if there are no warnings in the report.
+func (r *Report) Empty() bool {
+	return len(r.Warnings) == 0
+}
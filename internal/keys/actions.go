@@ -0,0 +1,144 @@
+package keys
+
+// Action is the name of something the user can do by pressing a key.
+type Action string
+
+// Context is the part of the user interface in which an action is available.
+type Context string
+
+const (
+	// ContextGlobal actions are available everywhere.
+	ContextGlobal Context = "global"
+
+	// ContextCollection actions are available while the collection has focus.
+	ContextCollection Context = "collection"
+
+	// ContextRequest actions are available while the request has focus.
+	ContextRequest Context = "request"
+
+	// ContextResponse actions are available while the response has focus.
+	ContextResponse Context = "response"
+)
+
+const (
+	About              Action = "about"
+	FocusCollection    Action = "focus-collection"
+	FocusRequest       Action = "focus-request"
+	FocusResponse      Action = "focus-response"
+	FocusLeft          Action = "focus-left"
+	FocusRight         Action = "focus-right"
+	Send               Action = "send"
+	Save               Action = "save"
+	History            Action = "history"
+	Workspaces         Action = "workspaces"
+	Find               Action = "find"
+	Undo               Action = "undo"
+	Redo               Action = "redo"
+	Shrink             Action = "shrink"
+	Grow               Action = "grow"
+	ItemAdd            Action = "item-add"
+	ItemAddGroup       Action = "item-add-group"
+	ItemDelete         Action = "item-delete"
+	ItemRename         Action = "item-rename"
+	ItemClone          Action = "item-clone"
+	ItemAuthentication Action = "item-authentication"
+	ItemHeaders        Action = "item-headers"
+	ItemDocs           Action = "item-docs"
+	ItemVariables      Action = "item-variables"
+	ItemImport         Action = "item-import"
+	ItemExport         Action = "item-export"
+	ItemSync           Action = "item-sync"
+	ItemCut            Action = "item-cut"
+	ItemPaste          Action = "item-paste"
+	ItemMoveUp         Action = "item-move-up"
+	ItemMoveDown       Action = "item-move-down"
+	ItemMoveToGroup    Action = "item-move-to-group"
+	ItemRun            Action = "item-run"
+	Filter             Action = "filter"
+	RequestBody        Action = "request-body"
+	RequestHeaders     Action = "request-headers"
+	RequestAuth        Action = "request-authentication"
+	RequestDocs        Action = "request-docs"
+	Format             Action = "format"
+	Preview            Action = "preview"
+	HeaderAdd          Action = "header-add"
+	HeaderRemove       Action = "header-remove"
+	InheritHeaders     Action = "inherit-headers"
+	RevealSecrets      Action = "reveal-secrets"
+	ResponseBody       Action = "response-body"
+	ResponseHeaders    Action = "response-headers"
+)
+
+// ActionInfo describes an action and the key it is bound to unless the user chooses another.
+type ActionInfo struct {
+	Action  Action
+	Context Context
+	Label   string
+	Default string
+}
+
+// actions lists every action that can be bound to a key.
+var actions = []ActionInfo{
+	{About, ContextGlobal, "About", "ctrl+a"},
+	{FocusCollection, ContextGlobal, "Collection", "ctrl+l"},
+	{FocusRequest, ContextGlobal, "Request", "ctrl+r"},
+	{FocusResponse, ContextGlobal, "Response", "ctrl+y"},
+	{FocusLeft, ContextGlobal, "Focus left", "shift+left"},
+	{FocusRight, ContextGlobal, "Focus right", "shift+right"},
+	{Send, ContextGlobal, "Send", "ctrl+g"},
+	{Save, ContextGlobal, "Save", "ctrl+s"},
+	{History, ContextGlobal, "History", "ctrl+e"},
+	{Workspaces, ContextGlobal, "Workspaces", "ctrl+w"},
+	{Find, ContextGlobal, "Find", "ctrl+p"},
+	{Undo, ContextGlobal, "Undo", "ctrl+z"},
+	{Redo, ContextGlobal, "Redo", "ctrl+o"},
+	{Shrink, ContextGlobal, "Shrink panel", "alt+left"},
+	{Grow, ContextGlobal, "Grow panel", "alt+right"},
+	{ItemAdd, ContextCollection, "New", "+"},
+	{ItemAddGroup, ContextCollection, "New group", "g"},
+	{ItemDelete, ContextCollection, "Delete", "-"},
+	{ItemRename, ContextCollection, "Rename", "r"},
+	{ItemClone, ContextCollection, "Clone", "c"},
+	{ItemAuthentication, ContextCollection, "Group auth", "a"},
+	{ItemHeaders, ContextCollection, "Group headers", "h"},
+	{ItemDocs, ContextCollection, "Group docs", "d"},
+	{ItemVariables, ContextCollection, "Group variables", "v"},
+	{ItemImport, ContextCollection, "Import", "i"},
+	{ItemExport, ContextCollection, "Export", "e"},
+	{ItemSync, ContextCollection, "Sync", "u"},
+	{ItemCut, ContextCollection, "Cut", "x"},
+	{ItemPaste, ContextCollection, "Paste", "p"},
+	{ItemMoveUp, ContextCollection, "Move up", "shift+up"},
+	{ItemMoveDown, ContextCollection, "Move down", "shift+down"},
+	{ItemMoveToGroup, ContextCollection, "Move to group", "m"},
+	{ItemRun, ContextCollection, "Run", "R"},
+	{Filter, ContextCollection, "Filter", "/"},
+	{RequestBody, ContextRequest, "Body", "1"},
+	{RequestHeaders, ContextRequest, "Headers", "2"},
+	{RequestAuth, ContextRequest, "Authentication", "3"},
+	{RequestDocs, ContextRequest, "Docs", "4"},
+	{Format, ContextRequest, "Format", "f"},
+	{Preview, ContextRequest, "Preview", "v"},
+	{HeaderAdd, ContextRequest, "Add header", "+"},
+	{HeaderRemove, ContextRequest, "Remove header", "-"},
+	{InheritHeaders, ContextRequest, "Inherit headers", "i"},
+	{RevealSecrets, ContextRequest, "Reveal secrets", "ctrl+u"},
+	{ResponseBody, ContextResponse, "Body", "1"},
+	{ResponseHeaders, ContextResponse, "Headers", "2"},
+}
+
+// Actions returns a description of every action that can be bound to a key.
+func Actions() []ActionInfo {
+	return actions
+}
+
+// info returns the description of an action.
+func info(action Action) (ActionInfo, bool) {
+	for _, a := range actions {
+		if a.Action == action {
+			return a, true
+		}
+	}
+
+	return ActionInfo{}, false
+}
@@ -0,0 +1,210 @@
+package keys
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// modifiers are the modifier keys that are significant when matching keys that do not produce a character.
+const modifiers = tcell.ModShift | tcell.ModCtrl | tcell.ModAlt
+
+// namedKey describes a key that does not produce a character.
+type namedKey struct {
+	name  string
+	label string
+	code  tcell.Key
+}
+
+var namedKeys = []namedKey{
+	{"up", "↑", tcell.KeyUp},
+	{"down", "↓", tcell.KeyDown},
+	{"left", "←", tcell.KeyLeft},
+	{"right", "→", tcell.KeyRight},
+	{"enter", "⏎", tcell.KeyEnter},
+	{"tab", "⇥", tcell.KeyTab},
+	{"backspace", "⌫", tcell.KeyBackspace2},
+	{"delete", "⌦", tcell.KeyDelete},
+	{"insert", "Ins", tcell.KeyInsert},
+	{"home", "Home", tcell.KeyHome},
+	{"end", "End", tcell.KeyEnd},
+	{"pgup", "PgUp", tcell.KeyPgUp},
+	{"pgdn", "PgDn", tcell.KeyPgDn},
+	{"esc", "Esc", tcell.KeyEscape},
+	{"f1", "F1", tcell.KeyF1},
+	{"f2", "F2", tcell.KeyF2},
+	{"f3", "F3", tcell.KeyF3},
+	{"f4", "F4", tcell.KeyF4},
+	{"f5", "F5", tcell.KeyF5},
+	{"f6", "F6", tcell.KeyF6},
+	{"f7", "F7", tcell.KeyF7},
+	{"f8", "F8", tcell.KeyF8},
+	{"f9", "F9", tcell.KeyF9},
+	{"f10", "F10", tcell.KeyF10},
+	{"f11", "F11", tcell.KeyF11},
+	{"f12", "F12", tcell.KeyF12},
+}
+
+// Key is a key, along with any modifiers, that can be bound to an action.
+type Key struct {
+	Code tcell.Key
+	Rune rune
+	Mod  tcell.ModMask
+}
+
+// ParseKey reads a key written as zero or more modifiers followed by a key, separated by plus signs, such as
+// "ctrl+s", "alt+left", "shift+up", "g" or "f5". Keys that produce a character are given as that character, so
+// "shift+g" and "G" are the same key.
+func ParseKey(text string) (Key, error) {
+	// a plus sign at the end is the key itself rather than a separator
+	parts := strings.Split(text, "+")
+	if text == "+" {
+		parts = []string{"+"}
+	} else if strings.HasSuffix(text, "++") {
+		parts = append(strings.Split(strings.TrimSuffix(text, "++"), "+"), "+")
+	}
+
+	var k Key
+	for _, m := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(m)) {
+		case "ctrl":
+			k.Mod |= tcell.ModCtrl
+		case "alt":
+			k.Mod |= tcell.ModAlt
+		case "shift":
+			k.Mod |= tcell.ModShift
+		default:
+			return Key{}, fmt.Errorf("%q is not a modifier; use ctrl, alt or shift", m)
+		}
+	}
+
+	name := strings.TrimSpace(parts[len(parts)-1])
+	if name == "space" {
+		name = " "
+	}
+
+	if utf8.RuneCountInString(name) == 1 {
+		return k.withRune([]rune(name)[0])
+	}
+
+	for _, n := range namedKeys {
+		if n.name == strings.ToLower(name) {
+			k.Code = n.code
+			return k, nil
+		}
+	}
+
+	return Key{}, fmt.Errorf("%q is not a key", name)
+}
+
+// withRune completes a key that produces a character.
+func (k Key) withRune(r rune) (Key, error) {
+	if k.Mod&tcell.ModCtrl == 0 {
+		// the shift key is implied by the character itself
+		if k.Mod&tcell.ModShift != 0 {
+			r = unicode.ToUpper(r)
+		}
+
+		return Key{Code: tcell.KeyRune, Rune: r, Mod: k.Mod & tcell.ModAlt}, nil
+	}
+
+	// terminals only report control keys combined with letters, and some of those are the same as other keys
+	r = unicode.ToLower(r)
+	if r < 'a' || r > 'z' || k.Mod&tcell.ModShift != 0 {
+		return Key{}, fmt.Errorf("ctrl can only be combined with a letter")
+	} else if r == 'h' || r == 'i' || r == 'm' {
+		return Key{}, fmt.Errorf("ctrl+%c cannot be told apart from backspace, tab or enter", r)
+	}
+
+	return Key{Code: tcell.KeyCtrlA + tcell.Key(r-'a'), Mod: k.Mod}, nil
+}
+
+// Matches returns true if the event was caused by pressing this key.
+func (k Key) Matches(event *tcell.EventKey) bool {
+	switch {
+	case k.Code == tcell.KeyRune:
+		return event.Key() == tcell.KeyRune && event.Rune() == k.Rune && event.Modifiers()&tcell.ModAlt == k.Mod
+	case k.Mod&tcell.ModCtrl != 0 && k.Code >= tcell.KeyCtrlA && k.Code <= tcell.KeyCtrlZ:
+		return event.Key() == k.Code && event.Modifiers()&tcell.ModAlt == k.Mod&tcell.ModAlt
+	default:
+		return event.Key() == k.Code && event.Modifiers()&modifiers == k.Mod
+	}
+}
+
+// String returns the key as it is written in the configuration file.
+func (k Key) String() string {
+	var b strings.Builder
+	if k.Mod&tcell.ModCtrl != 0 {
+		b.WriteString("ctrl+")
+	}
+	if k.Mod&tcell.ModAlt != 0 {
+		b.WriteString("alt+")
+	}
+	if k.Mod&tcell.ModShift != 0 {
+		b.WriteString("shift+")
+	}
+
+	switch {
+	case k.Code == tcell.KeyRune && k.Rune == ' ':
+		b.WriteString("space")
+	case k.Code == tcell.KeyRune:
+		b.WriteRune(k.Rune)
+	case k.Code >= tcell.KeyCtrlA && k.Code <= tcell.KeyCtrlZ:
+		b.WriteRune('a' + rune(k.Code-tcell.KeyCtrlA))
+	default:
+		b.WriteString(k.name(func(n namedKey) string { return n.name }))
+	}
+
+	return b.String()
+}
+
+// Label returns a short form of the key for showing in the status bar, such as "⌃S" or "⇧↑".
+func (k Key) Label() string {
+	return k.modifierLabel() + k.keyLabel()
+}
+
+func (k Key) modifierLabel() string {
+	var b strings.Builder
+	if k.Mod&tcell.ModCtrl != 0 {
+		b.WriteString("⌃")
+	}
+	if k.Mod&tcell.ModAlt != 0 {
+		b.WriteString("⌥")
+	}
+	if k.Mod&tcell.ModShift != 0 {
+		b.WriteString("⇧")
+	}
+
+	return b.String()
+}
+
+func (k Key) keyLabel() string {
+	switch {
+	case k.Code == tcell.KeyRune && k.Rune == ' ':
+		return "Space"
+	case k.Code == tcell.KeyRune:
+		return string(k.Rune)
+	case k.Code >= tcell.KeyCtrlA && k.Code <= tcell.KeyCtrlZ:
+		return string('A' + rune(k.Code-tcell.KeyCtrlA))
+	default:
+		return k.name(func(n namedKey) string { return n.label })
+	}
+}
+
+// name returns the name of a key that does not produce a character.
+func (k Key) name(field func(n namedKey) string) string {
+	for _, n := range namedKeys {
+		if n.code == k.Code {
+			return field(n)
+		}
+	}
+
+	return fmt.Sprintf("key%d", k.Code)
+}
+
+// typed returns true if pressing the key types a character into a text field.
+func (k Key) typed() bool {
+	return k.Code == tcell.KeyRune && k.Mod&tcell.ModAlt == 0
+}
@@ -0,0 +1,197 @@
+package keys
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mbpolan/lull/internal/events"
+	"sort"
+	"strings"
+)
+
+// Unbound is the key given for an action that should not be bound to any key.
+const Unbound = "none"
+
+var instance *Keymap
+
+// Keymap binds keys to actions.
+type Keymap struct {
+	keys map[Action]Key
+}
+
+// Setup binds keys to actions, using the default bindings for all actions that are not given in overrides. Overrides
+// are keyed by action name.
+func Setup(overrides map[string]string) error {
+	k, problems := NewKeymap(overrides)
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid key bindings: %s", strings.Join(problems, "; "))
+	}
+
+	instance = k
+	return nil
+}
+
+// Get returns the keymap created by Setup, or the default keymap if Setup was not called.
+func Get() *Keymap {
+	if instance == nil {
+		instance, _ = NewKeymap(nil)
+	}
+
+	return instance
+}
+
+// Validate returns a description of each problem with the overrides, such as unknown actions, invalid keys and keys
+// bound to more than one action that could be used at the same time.
+func Validate(overrides map[string]string) []string {
+	_, problems := NewKeymap(overrides)
+	return problems
+}
+
+// NewKeymap returns a Keymap using the default bindings for all actions that are not given in overrides, along with a
+// description of each problem with the overrides.
+func NewKeymap(overrides map[string]string) (*Keymap, []string) {
+	var problems []string
+	k := &Keymap{
+		keys: map[Action]Key{},
+	}
+
+	for _, a := range actions {
+		key, _ := ParseKey(a.Default)
+		k.keys[a.Action] = key
+	}
+
+	names := make([]string, 0, len(overrides))
+	for name := range overrides {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		a, ok := info(Action(name))
+		if !ok {
+			problems = append(problems, fmt.Sprintf("keys.%s: not a known action", name))
+			continue
+		}
+
+		text := strings.TrimSpace(overrides[name])
+		if text == Unbound {
+			delete(k.keys, a.Action)
+			continue
+		}
+
+		key, err := ParseKey(text)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("keys.%s: %s", name, err))
+			continue
+		}
+
+		// global actions are checked before any text field sees a key, so they can't use keys that type characters
+		if a.Context == ContextGlobal && key.typed() {
+			problems = append(problems, fmt.Sprintf("keys.%s: %s would prevent typing; add ctrl or alt", name, key))
+			continue
+		} else if key.Mod&tcell.ModCtrl != 0 && key.Code == tcell.KeyCtrlC {
+			problems = append(problems, fmt.Sprintf("keys.%s: ctrl+c is reserved for quitting", name))
+			continue
+		}
+
+		k.keys[a.Action] = key
+	}
+
+	return k, append(problems, k.conflicts()...)
+}
+
+// conflicts returns a description of each key that is bound to more than one action that could be used at the same
+// time.
+func (k *Keymap) conflicts() []string {
+	var problems []string
+	for i, a := range actions {
+		for _, b := range actions[i+1:] {
+			if a.Context != b.Context && a.Context != ContextGlobal && b.Context != ContextGlobal {
+				continue
+			}
+
+			ka, okA := k.keys[a.Action]
+			kb, okB := k.keys[b.Action]
+			if okA && okB && ka == kb {
+				problems = append(problems, fmt.Sprintf("keys: %s is bound to both %s and %s", ka, a.Action, b.Action))
+			}
+		}
+	}
+
+	return problems
+}
+
+// Bindings returns the key bound to each action, keyed by action name. Actions that are not bound are included with
+// Unbound as their key.
+func (k *Keymap) Bindings() map[string]string {
+	bindings := map[string]string{}
+	for _, a := range actions {
+		if key, ok := k.keys[a.Action]; ok {
+			bindings[string(a.Action)] = key.String()
+		} else {
+			bindings[string(a.Action)] = Unbound
+		}
+	}
+
+	return bindings
+}
+
+// Matches returns true if the event was caused by pressing the key bound to the action.
+func (k *Keymap) Matches(action Action, event *tcell.EventKey) bool {
+	key, ok := k.keys[action]
+	return ok && key.Matches(event)
+}
+
+// Action returns the action in the context whose key caused the event.
+func (k *Keymap) Action(context Context, event *tcell.EventKey) (Action, bool) {
+	for _, a := range actions {
+		if a.Context == context && k.Matches(a.Action, event) {
+			return a.Action, true
+		}
+	}
+
+	return "", false
+}
+
+// Label returns a short form of the keys bound to one or more related actions for showing in the status bar. Keys
+// with the same modifiers are combined, so that moving up and down with shift+up and shift+down is shown as "⇧↑↓".
+func (k *Keymap) Label(actions ...Action) string {
+	var parts []string
+	prefix := ""
+
+	for _, a := range actions {
+		key, ok := k.keys[a]
+		if !ok {
+			continue
+		}
+
+		if m := key.modifierLabel(); len(parts) > 0 && m == prefix && key.Code != tcell.KeyRune {
+			parts[len(parts)-1] += key.keyLabel()
+		} else {
+			parts = append(parts, key.Label())
+			prefix = m
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Sequence returns the entry for the status bar describing an action and the key bound to it.
+func (k *Keymap) Sequence(action Action) events.StatusBarContextChangeSequence {
+	a, _ := info(action)
+	return events.StatusBarContextChangeSequence{
+		Label:       a.Label,
+		KeySequence: k.Label(action),
+	}
+}
+
+// Sequences returns the entries for the status bar describing the actions that are bound to keys.
+func (k *Keymap) Sequences(actions ...Action) []events.StatusBarContextChangeSequence {
+	var seq []events.StatusBarContextChangeSequence
+	for _, a := range actions {
+		if _, ok := k.keys[a]; ok {
+			seq = append(seq, k.Sequence(a))
+		}
+	}
+
+	return seq
+}
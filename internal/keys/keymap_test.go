@@ -0,0 +1,51 @@
+package keys
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ParseKey(t *testing.T) {
+	for _, text := range []string{"ctrl+s", "alt+left", "shift+up", "g", "G", "+", "alt++", "f5", "space"} {
+		key, err := ParseKey(text)
+
+		assert.NoError(t, err, text)
+		assert.Equal(t, text, key.String())
+	}
+
+	key, err := ParseKey("shift+g")
+	assert.NoError(t, err)
+	assert.Equal(t, "G", key.String())
+
+	for _, text := range []string{"ctrl+1", "ctrl+i", "hyper+a", "nope"} {
+		_, err := ParseKey(text)
+		assert.Error(t, err, text)
+	}
+}
+
+func Test_Keymap_Matches(t *testing.T) {
+	k, problems := NewKeymap(map[string]string{"save": "alt+s", "send": Unbound})
+
+	assert.Empty(t, problems)
+	assert.True(t, k.Matches(Save, tcell.NewEventKey(tcell.KeyRune, 's', tcell.ModAlt)))
+	assert.False(t, k.Matches(Save, tcell.NewEventKey(tcell.KeyCtrlS, 0, tcell.ModCtrl)))
+	assert.False(t, k.Matches(Send, tcell.NewEventKey(tcell.KeyCtrlG, 0, tcell.ModCtrl)))
+	assert.True(t, k.Matches(Undo, tcell.NewEventKey(tcell.KeyCtrlZ, 0, tcell.ModCtrl)))
+	assert.True(t, k.Matches(ItemMoveUp, tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModShift)))
+	assert.False(t, k.Matches(ItemMoveUp, tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)))
+
+	assert.Equal(t, "⌥s", k.Label(Save))
+	assert.Equal(t, "⇧↑↓", k.Label(ItemMoveUp, ItemMoveDown))
+	assert.Equal(t, "", k.Label(Send))
+}
+
+func Test_Keymap_Conflicts(t *testing.T) {
+	_, problems := NewKeymap(map[string]string{"save": "ctrl+g", "find": "p", "item-cut": "ctrl+o"})
+
+	assert.Equal(t, []string{
+		"keys.find: p would prevent typing; add ctrl or alt",
+		"keys: ctrl+g is bound to both send and save",
+		"keys: ctrl+o is bound to both redo and item-cut",
+	}, problems)
+}
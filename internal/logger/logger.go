@@ -52,10 +52,10 @@ func Setup(level string) error {
 
 // Infof logs an info-level message with a format and template args.
 func Infof(format string, args ...any) {
-	sugar.Infof(format, args)
+	sugar.Infof(format, args...)
 }
 
 // Errorf logs an error-level message with a format and template args.
 func Errorf(format string, args ...any) {
-	sugar.Errorf(format, args)
+	sugar.Errorf(format, args...)
 }
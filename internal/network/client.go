@@ -2,6 +2,7 @@ package network
 
 import (
 	"context"
+	"github.com/mbpolan/lull/internal/config"
 	"github.com/mbpolan/lull/internal/state"
 	"io"
 	"net/http"
@@ -13,39 +14,47 @@ import (
 type AuthFunc func(req *http.Request) error
 
 type Client struct {
-	client *http.Client
-	mutex  sync.Mutex
+	mutex sync.Mutex
 }
 
 func NewClient() *Client {
-	c := new(Client)
-	c.client = http.DefaultClient
+	return new(Client)
+}
 
-	return c
+// httpClient returns a client using the timeout configured for the current workspace.
+func (c *Client) httpClient() *http.Client {
+	return &http.Client{
+		Timeout: config.Get().Request.Timeout.Duration,
+	}
 }
 
 func (c *Client) ExchangeRequest(req *http.Request) (*http.Response, error) {
-	return c.client.Do(req)
+	return c.httpClient().Do(req)
 }
 
 func (c *Client) Exchange(ctx context.Context, item *state.CollectionItem, authFunc AuthFunc) (*http.Response, error) {
-	uri, err := url.Parse(item.URL)
+	uri, err := url.Parse(item.ExpandVariables(item.URL))
 	if err != nil {
 		return nil, err
 	}
 
-	headers := item.Headers
+	headers := http.Header(item.EffectiveHeaders())
+	for _, values := range headers {
+		for i, v := range values {
+			values[i] = item.ExpandVariables(v)
+		}
+	}
 
 	var data io.ReadCloser
 	if item.RequestBody != nil {
-		data = io.NopCloser(strings.NewReader(item.RequestBody.Payload))
+		data = io.NopCloser(strings.NewReader(item.ExpandVariables(item.RequestBody.Payload)))
 		headers["Content-Type"] = []string{item.RequestBody.ContentType}
 	}
 
 	req := &http.Request{
 		Method: item.Method,
 		URL:    uri,
-		Header: item.Headers,
+		Header: headers,
 		Body:   data,
 	}
 
@@ -57,5 +66,5 @@ func (c *Client) Exchange(ctx context.Context, item *state.CollectionItem, authF
 	}
 
 	req = req.WithContext(ctx)
-	return c.client.Do(req)
+	return c.httpClient().Do(req)
 }
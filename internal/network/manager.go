@@ -89,16 +89,18 @@ func (m *Manager) SendRequest(item *state.CollectionItem) error {
 			defer res.Body.Close()
 		}
 
-		m.handler(m.currentItem, &Result{
+		result := &Result{
 			Response:     res,
 			Error:        err,
 			Payload:      payload,
 			PayloadError: payloadErr,
 			StartTime:    m.startTime,
 			EndTime:      time.Now(),
-		})
+		}
 
+		// allow another request to be sent as soon as the handler is invoked
 		m.resetCurrent()
+		m.handler(item, result)
 	}()
 
 	return nil
@@ -118,11 +120,13 @@ func (m *Manager) CancelCurrent() {
 }
 
 func (m *Manager) authenticate(item *state.CollectionItem) (func(req *http.Request) error, error) {
-	if item.Authentication.None() {
+	// resolve authentication inherited from ancestor groups, if any
+	authentication, _ := item.EffectiveAuthentication()
+	if authentication.None() {
 		return nil, nil
 	}
 
-	authReq, err := item.Authentication.Data.Prepare()
+	authReq, err := authentication.Data.Prepare()
 	if err != nil {
 		return nil, err
 	}
@@ -136,7 +140,7 @@ func (m *Manager) authenticate(item *state.CollectionItem) (func(req *http.Reque
 	}
 
 	return func(req *http.Request) error {
-		return item.Authentication.Data.Apply(req, res)
+		return authentication.Data.Apply(req, res)
 	}, nil
 }
 
@@ -1,6 +1,9 @@
 package parsers
 
-import "net/http"
+import (
+	"github.com/mbpolan/lull/internal/theme"
+	"net/http"
+)
 
 // BodyParser is the top-level interface for implementations that parse HTTP response bodies.
 type BodyParser interface {
@@ -9,4 +12,14 @@ type BodyParser interface {
 
 	// ParseBytes formats a raw byte representation of body content, returning a formatted string.
 	ParseBytes(body []byte) (string, error)
+
+	// Highlight formats a raw byte representation of body content like ParseBytes, returning a string with color tags
+	// for showing in a view with dynamic colors. Any text that tview would read as a color tag is escaped.
+	Highlight(body []byte, colors theme.SyntaxColors) (string, error)
+}
+
+// TableParser is implemented by parsers for content that is best shown as a table, such as CSV.
+type TableParser interface {
+	// ParseTable reads a raw byte representation of body content, returning the column headers and the rows.
+	ParseTable(body []byte) ([]string, [][]string, error)
 }
@@ -0,0 +1,85 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"github.com/mbpolan/lull/internal/theme"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+)
+
+// CSVBodyParser is a parser for "text/csv" content types. The first record is taken as the column headers.
+type CSVBodyParser struct {
+}
+
+// NewCSVBodyParser returns an instance of CSVBodyParser.
+func NewCSVBodyParser() *CSVBodyParser {
+	return new(CSVBodyParser)
+}
+
+// Parse returns the CSV response body with its records consistently quoted.
+func (c *CSVBodyParser) Parse(res *http.Response) (string, error) {
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return c.ParseBytes(data)
+}
+
+// ParseBytes returns the CSV response body for the given raw bytes with its records consistently quoted, so the
+// result can still be sent as CSV.
+func (c *CSVBodyParser) ParseBytes(body []byte) (string, error) {
+	headers, rows, err := c.ParseTable(body)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.WriteAll(append([][]string{headers}, rows...)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Highlight returns the CSV response body for the given raw bytes as a table with aligned columns, with the column
+// headers in their own color.
+func (c *CSVBodyParser) Highlight(body []byte, colors theme.SyntaxColors) (string, error) {
+	return c.format(body, syntaxStyle{highlight: true, colors: colors})
+}
+
+// ParseTable returns the column headers and rows of the CSV response body for the given raw bytes.
+func (c *CSVBodyParser) ParseTable(body []byte) ([]string, [][]string, error) {
+	r := csv.NewReader(bytes.NewReader(body))
+
+	// records with a different number of fields than the headers are still shown
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	} else if len(records) == 0 {
+		return nil, nil, errors.New("no CSV records found")
+	}
+
+	return records[0], records[1:], nil
+}
+
+func (c *CSVBodyParser) format(body []byte, style syntaxStyle) (string, error) {
+	headers, rows, err := c.ParseTable(body)
+	if err != nil {
+		return "", err
+	}
+
+	return formatTable(headers, rows, style, func(row int, _ int) string {
+		if row < 0 {
+			return style.colors.Key
+		}
+
+		return style.colors.String
+	}), nil
+}
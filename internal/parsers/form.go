@@ -0,0 +1,91 @@
+package parsers
+
+import (
+	"github.com/mbpolan/lull/internal/theme"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// FormBodyParser is a parser for "application/x-www-form-urlencoded" content types.
+type FormBodyParser struct {
+}
+
+// NewFormBodyParser returns an instance of FormBodyParser.
+func NewFormBodyParser() *FormBodyParser {
+	return new(FormBodyParser)
+}
+
+// Parse returns the form response body with its fields consistently encoded.
+func (f *FormBodyParser) Parse(res *http.Response) (string, error) {
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return f.ParseBytes(data)
+}
+
+// ParseBytes returns the form response body for the given raw bytes with its fields consistently encoded, so the
+// result can still be sent as a form body. Fields keep their order.
+func (f *FormBodyParser) ParseBytes(body []byte) (string, error) {
+	_, rows, err := f.ParseTable(body)
+	if err != nil {
+		return "", err
+	}
+
+	fields := make([]string, len(rows))
+	for i, row := range rows {
+		fields[i] = url.QueryEscape(row[0]) + "=" + url.QueryEscape(row[1])
+	}
+
+	return strings.Join(fields, "&"), nil
+}
+
+// Highlight returns the decoded fields of the form response body for the given raw bytes as a table, one per line,
+// with the names and values in their own colors.
+func (f *FormBodyParser) Highlight(body []byte, colors theme.SyntaxColors) (string, error) {
+	return f.format(body, syntaxStyle{highlight: true, colors: colors})
+}
+
+// ParseTable returns the decoded names and values of the fields in the form response body for the given raw bytes,
+// in the order they are given.
+func (f *FormBodyParser) ParseTable(body []byte) ([]string, [][]string, error) {
+	var rows [][]string
+
+	for _, field := range strings.FieldsFunc(strings.TrimSpace(string(body)), func(r rune) bool { return r == '&' }) {
+		name, value, _ := strings.Cut(field, "=")
+
+		name, err := url.QueryUnescape(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		value, err = url.QueryUnescape(value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rows = append(rows, []string{name, value})
+	}
+
+	return []string{"Name", "Value"}, rows, nil
+}
+
+func (f *FormBodyParser) format(body []byte, style syntaxStyle) (string, error) {
+	headers, rows, err := f.ParseTable(body)
+	if err != nil {
+		return "", err
+	}
+
+	return formatTable(headers, rows, style, func(row int, column int) string {
+		if row < 0 {
+			return style.colors.Punctuation
+		} else if column == 0 {
+			return style.colors.Key
+		}
+
+		return style.colors.String
+	}), nil
+}
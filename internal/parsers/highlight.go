@@ -0,0 +1,103 @@
+package parsers
+
+import (
+	"github.com/mbpolan/lull/internal/theme"
+	"github.com/rivo/tview"
+	"strings"
+)
+
+// jsonPunctuation are the characters that separate values in a JSON document.
+const jsonPunctuation = "{}[],:"
+
+// highlightJSON adds color tags to a valid JSON document. Whitespace is kept as-is, so the document should already be
+// formatted.
+func highlightJSON(text string, colors theme.SyntaxColors) string {
+	var b strings.Builder
+
+	for i := 0; i < len(text); {
+		c := text[i]
+
+		switch {
+		case c == '"':
+			end := jsonStringEnd(text, i)
+
+			// a string followed by a colon is the key of an object member
+			color := colors.String
+			if strings.HasPrefix(strings.TrimLeft(text[end:], " \t\r\n"), ":") {
+				color = colors.Key
+			}
+
+			b.WriteString(theme.Colorize(color, tview.Escape(text[i:end])))
+			i = end
+		case strings.IndexByte(jsonPunctuation, c) > -1:
+			// each character is colored on its own so that brackets never form a color tag with adjacent text
+			b.WriteString(theme.Colorize(colors.Punctuation, string(c)))
+			i++
+		case isSpace(c):
+			b.WriteByte(c)
+			i++
+		default:
+			end := i
+			for end < len(text) && !isSpace(text[end]) && strings.IndexByte(jsonPunctuation, text[end]) == -1 {
+				end++
+			}
+
+			b.WriteString(theme.Colorize(jsonLiteralColor(text[i:end], colors), tview.Escape(text[i:end])))
+			i = end
+		}
+	}
+
+	return b.String()
+}
+
+// jsonStringEnd returns the index just past the closing quote of the string that starts at the given index.
+func jsonStringEnd(text string, start int) int {
+	for i := start + 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+
+	return len(text)
+}
+
+// jsonLiteralColor returns the color for a number, boolean or null.
+func jsonLiteralColor(literal string, colors theme.SyntaxColors) string {
+	switch literal {
+	case "true", "false":
+		return colors.Boolean
+	case "null":
+		return colors.Null
+	default:
+		return colors.Number
+	}
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+// syntaxStyle adds colors to the parts of a document, or leaves them as-is if highlight is false.
+type syntaxStyle struct {
+	highlight bool
+	colors    theme.SyntaxColors
+}
+
+func (s syntaxStyle) paint(color string, text string) string {
+	if !s.highlight {
+		return text
+	}
+
+	return theme.Colorize(color, tview.Escape(text))
+}
+
+func (s syntaxStyle) text(text string) string {
+	if !s.highlight {
+		return text
+	}
+
+	return tview.Escape(text)
+}
@@ -0,0 +1,38 @@
+package parsers
+
+import (
+	"github.com/mbpolan/lull/internal/theme"
+	"io"
+	"net/http"
+)
+
+// HTMLBodyParser is a parser for "text/html" content types. Malformed documents are formatted as well as possible
+// rather than rejected.
+type HTMLBodyParser struct {
+}
+
+// NewHTMLBodyParser returns an instance of HTMLBodyParser.
+func NewHTMLBodyParser() *HTMLBodyParser {
+	return new(HTMLBodyParser)
+}
+
+// Parse returns a formatted and indented HTML response body.
+func (h *HTMLBodyParser) Parse(res *http.Response) (string, error) {
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return h.ParseBytes(data)
+}
+
+// ParseBytes returns a formatted and indented HTML response body for the given raw bytes.
+func (h *HTMLBodyParser) ParseBytes(body []byte) (string, error) {
+	return formatMarkup(scanMarkup(string(body), true), true, syntaxStyle{}), nil
+}
+
+// Highlight returns a formatted and indented HTML response body for the given raw bytes, with tags and attributes in
+// their own colors.
+func (h *HTMLBodyParser) Highlight(body []byte, colors theme.SyntaxColors) (string, error) {
+	return formatMarkup(scanMarkup(string(body), true), true, syntaxStyle{highlight: true, colors: colors}), nil
+}
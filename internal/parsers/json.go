@@ -2,6 +2,7 @@ package parsers
 
 import (
 	"encoding/json"
+	"github.com/mbpolan/lull/internal/theme"
 	"io"
 	"net/http"
 )
@@ -40,3 +41,14 @@ func (j *JSONBodyParser) ParseBytes(body []byte) (string, error) {
 
 	return string(data), nil
 }
+
+// Highlight returns a formatted and prettified JSON response body for the given raw bytes, with keys, values and
+// punctuation in their own colors.
+func (j *JSONBodyParser) Highlight(body []byte, colors theme.SyntaxColors) (string, error) {
+	text, err := j.ParseBytes(body)
+	if err != nil {
+		return "", err
+	}
+
+	return highlightJSON(text, colors), nil
+}
@@ -0,0 +1,24 @@
+package parsers
+
+import (
+	"github.com/mbpolan/lull/internal/theme"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var testSyntaxColors = theme.SyntaxColors{Key: "k", String: "s", Number: "n", Boolean: "b", Null: "z", Punctuation: "p",
+	Tag: "t", Attribute: "a"}
+
+func Test_JSONBodyParser_Highlight(t *testing.T) {
+	text, err := NewJSONBodyParser().Highlight([]byte(`{"a":[1,true,null,"[red]"]}`), testSyntaxColors)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[p]{[-]\n  [k]\"a\"[-][p]:[-] [p][[-]\n    [n]1[-][p],[-]\n    [b]true[-][p],[-]\n"+
+		"    [z]null[-][p],[-]\n    [s]\"[red[]\"[-]\n  [p]][-]\n[p]}[-]", text)
+}
+
+func Test_JSONBodyParser_HighlightInvalid(t *testing.T) {
+	_, err := NewJSONBodyParser().Highlight([]byte(`{"a":`), testSyntaxColors)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,427 @@
+package parsers
+
+import "strings"
+
+const markupIndent = "  "
+
+type markupKind int
+
+const (
+	markupText markupKind = iota
+	markupStart
+	markupEnd
+	markupComment
+	markupDirective
+)
+
+// markupToken is a tag, text or other part of an XML or HTML document.
+type markupToken struct {
+	kind        markupKind
+	name        string
+	attrs       []markupAttr
+	selfClosing bool
+	text        string
+	verbatim    bool
+	implied     bool
+}
+
+// markupAttr is an attribute of a tag. The value is kept as written, including any quotes.
+type markupAttr struct {
+	name  string
+	value string
+}
+
+// htmlVoidElements are the HTML elements that never have content or an end tag.
+var htmlVoidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "param": true, "source": true, "track": true, "wbr": true,
+}
+
+// htmlRawTextElements are the HTML elements whose content is kept as written.
+var htmlRawTextElements = map[string]bool{
+	"script": true, "style": true, "pre": true, "textarea": true,
+}
+
+// htmlBlockElements are the HTML elements whose start tag ends an open paragraph.
+var htmlBlockElements = []string{
+	"address", "article", "aside", "blockquote", "details", "div", "dl", "fieldset", "figcaption", "figure", "footer",
+	"form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "li", "main", "nav", "ol", "p", "pre", "section",
+	"table", "ul",
+}
+
+// htmlOptionalEndElements are the HTML elements whose end tag may be left out, along with the start tags of the
+// elements that end them.
+var htmlOptionalEndElements = map[string][]string{
+	"p":      htmlBlockElements,
+	"li":     {"li"},
+	"dt":     {"dt", "dd"},
+	"dd":     {"dt", "dd"},
+	"option": {"option", "optgroup"},
+	"tr":     {"tr", "tbody", "thead", "tfoot"},
+	"td":     {"td", "th", "tr", "tbody", "thead", "tfoot"},
+	"th":     {"td", "th", "tr", "tbody", "thead", "tfoot"},
+}
+
+// scanMarkup splits an XML or HTML document into tokens. Malformed markup never causes an error: anything that does
+// not look like a tag is treated as text.
+func scanMarkup(text string, html bool) []markupToken {
+	var tokens []markupToken
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		switch {
+		case rest[0] != '<' || len(rest) == 1 || !isNameStart(rest[1]) && strings.IndexByte("!?/", rest[1]) == -1:
+			// a lone angle bracket is text, as it often is in malformed HTML
+			end := strings.IndexByte(rest[1:], '<') + 1
+			if end == 0 {
+				end = len(rest)
+			}
+
+			tokens = appendMarkupText(tokens, rest[:end])
+			i += end
+		case strings.HasPrefix(rest, "<!--"):
+			end := markupEndOf(rest, "-->")
+			tokens = append(tokens, markupToken{kind: markupComment, text: rest[:end]})
+			i += end
+		case strings.HasPrefix(rest, "<![CDATA["):
+			end := markupEndOf(rest, "]]>")
+			tokens = append(tokens, markupToken{kind: markupText, text: rest[:end], verbatim: true})
+			i += end
+		case strings.HasPrefix(rest, "<!") || strings.HasPrefix(rest, "<?"):
+			end := markupEndOf(rest, ">")
+			tokens = append(tokens, markupToken{kind: markupDirective, text: rest[:end]})
+			i += end
+		case strings.HasPrefix(rest, "</"):
+			end := markupEndOf(rest, ">")
+			name := strings.TrimSpace(strings.TrimSuffix(rest[2:end], ">"))
+			tokens = append(tokens, markupToken{kind: markupEnd, name: name})
+			i += end
+		default:
+			t, end := scanMarkupTag(rest)
+			tokens = append(tokens, t)
+			i += end
+
+			// the content of elements such as scripts is not markup, and is kept as-is up to the end tag
+			if html && !t.selfClosing && htmlRawTextElements[strings.ToLower(t.name)] {
+				content := len(text) - i
+				if n := strings.Index(strings.ToLower(text[i:]), "</"+strings.ToLower(t.name)); n > -1 {
+					content = n
+				}
+
+				if content > 0 {
+					tokens = append(tokens, markupToken{kind: markupText, text: text[i : i+content], verbatim: true})
+				}
+
+				i += content
+			}
+		}
+	}
+
+	return tokens
+}
+
+// closeHTMLElements adds the end tags that are left out of HTML documents, such as those of list items and paragraphs.
+// An element is ended by the start tag of a sibling that ends it, or by the end tag of its parent. The end tags that
+// are added are implied, and are never written when formatting the document.
+func closeHTMLElements(tokens []markupToken) []markupToken {
+	var result []markupToken
+	var open []string
+
+	implied := func(name string) {
+		result = append(result, markupToken{kind: markupEnd, name: name, implied: true})
+		open = open[:len(open)-1]
+	}
+
+	for _, t := range tokens {
+		name := strings.ToLower(t.name)
+
+		switch t.kind {
+		case markupStart:
+			for len(open) > 0 && htmlEndedBy(open[len(open)-1], name) {
+				implied(open[len(open)-1])
+			}
+
+			if !t.selfClosing && !htmlVoidElements[name] {
+				open = append(open, name)
+			}
+		case markupEnd:
+			j := len(open) - 1
+			for j >= 0 && open[j] != name {
+				j--
+			}
+
+			// elements left open inside the parent are ended with it, as long as their end tags may be left out
+			if j > -1 {
+				for len(open)-1 > j && htmlOptionalEndElements[open[len(open)-1]] != nil {
+					implied(open[len(open)-1])
+				}
+
+				open = open[:j]
+			}
+		}
+
+		result = append(result, t)
+	}
+
+	return result
+}
+
+// htmlEndedBy returns true if an open element is ended by the start tag of another element.
+func htmlEndedBy(open string, name string) bool {
+	for _, n := range htmlOptionalEndElements[open] {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// appendMarkupText adds text to the tokens, joining it with any text that comes right before it.
+func appendMarkupText(tokens []markupToken, text string) []markupToken {
+	if n := len(tokens) - 1; n > -1 && tokens[n].kind == markupText && !tokens[n].verbatim {
+		tokens[n].text += text
+		return tokens
+	}
+
+	return append(tokens, markupToken{kind: markupText, text: text})
+}
+
+// scanMarkupTag reads the start tag at the beginning of the text, returning it along with its length.
+func scanMarkupTag(text string) (markupToken, int) {
+	t := markupToken{kind: markupStart}
+
+	i := 1
+	for i < len(text) && !isSpace(text[i]) && text[i] != '>' && text[i] != '/' {
+		i++
+	}
+
+	t.name = text[1:i]
+
+	for i < len(text) {
+		for i < len(text) && isSpace(text[i]) {
+			i++
+		}
+
+		switch {
+		case i >= len(text):
+			return t, i
+		case text[i] == '>':
+			return t, i + 1
+		case strings.HasPrefix(text[i:], "/>"):
+			t.selfClosing = true
+			return t, i + 2
+		case text[i] == '/':
+			i++
+			continue
+		}
+
+		start := i
+		for i < len(text) && !isSpace(text[i]) && text[i] != '=' && text[i] != '>' && !strings.HasPrefix(text[i:], "/>") {
+			i++
+		}
+
+		attr := markupAttr{name: text[start:i]}
+
+		// attributes without a value are allowed in HTML
+		if i < len(text) && text[i] == '=' {
+			i++
+			start = i
+
+			if i < len(text) && (text[i] == '"' || text[i] == '\'') {
+				if end := strings.IndexByte(text[i+1:], text[i]); end > -1 {
+					i += end + 2
+				} else {
+					i = len(text)
+				}
+			} else {
+				for i < len(text) && !isSpace(text[i]) && text[i] != '>' {
+					i++
+				}
+			}
+
+			attr.value = text[start:i]
+		}
+
+		t.attrs = append(t.attrs, attr)
+	}
+
+	return t, i
+}
+
+// markupEndOf returns the length of the text up to and including the delimiter, or the length of the whole text if the
+// delimiter is missing.
+func markupEndOf(text string, delim string) int {
+	if n := strings.Index(text, delim); n > -1 {
+		return n + len(delim)
+	}
+
+	return len(text)
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func (s syntaxStyle) token(t markupToken) string {
+	switch t.kind {
+	case markupStart:
+		var b strings.Builder
+		b.WriteString(s.paint(s.colors.Tag, "<"+t.name))
+
+		for _, a := range t.attrs {
+			b.WriteString(" " + s.paint(s.colors.Attribute, a.name))
+			if a.value != "" {
+				b.WriteString(s.paint(s.colors.Punctuation, "=") + s.paint(s.colors.String, a.value))
+			}
+		}
+
+		if t.selfClosing {
+			b.WriteString(s.paint(s.colors.Tag, "/>"))
+		} else {
+			b.WriteString(s.paint(s.colors.Tag, ">"))
+		}
+
+		return b.String()
+	case markupEnd:
+		if t.implied {
+			return ""
+		}
+
+		return s.paint(s.colors.Tag, "</"+t.name+">")
+	case markupComment, markupDirective:
+		return s.paint(s.colors.Null, t.text)
+	default:
+		return s.text(t.text)
+	}
+}
+
+// formatMarkup writes the tokens with each element on its own line, indented by its depth in the document. Only the
+// whitespace between elements is changed: elements that contain text are kept on one line exactly as written, so that
+// formatting never changes the content of the document.
+func formatMarkup(tokens []markupToken, html bool, style syntaxStyle) string {
+	var lines []string
+	var open []string
+
+	line := func(text string) {
+		lines = append(lines, strings.Repeat(markupIndent, len(open))+text)
+	}
+
+	if html {
+		tokens = closeHTMLElements(tokens)
+	}
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+
+		switch t.kind {
+		case markupStart:
+			if t.selfClosing || html && htmlVoidElements[strings.ToLower(t.name)] {
+				line(style.token(t))
+			} else if end := markupInlineEnd(tokens, i, open, html); end > -1 {
+				var b strings.Builder
+				for _, it := range tokens[i : end+1] {
+					b.WriteString(style.token(it))
+				}
+
+				line(b.String())
+				i = end
+			} else {
+				line(style.token(t))
+				open = append(open, t.name)
+			}
+		case markupEnd:
+			// end tags without a matching start tag are shown where they are, but do not change the indentation
+			for j := len(open) - 1; j >= 0; j-- {
+				if markupNamesEqual(open[j], t.name, html) {
+					open = open[:j]
+					break
+				}
+			}
+
+			if !t.implied {
+				line(style.token(t))
+			}
+		case markupText:
+			if t.verbatim {
+				for _, l := range strings.Split(strings.Trim(t.text, "\r\n"), "\n") {
+					lines = append(lines, style.text(strings.TrimRight(l, "\r")))
+				}
+
+				continue
+			}
+
+			// text outside of elements that contain text is only whitespace between elements, unless the markup is
+			// malformed
+			if text := strings.TrimSpace(t.text); text != "" {
+				line(style.text(text))
+			}
+		default:
+			line(style.token(t))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// markupInlineEnd returns the index of the end tag of the element starting at the given index if the element should be
+// kept on one line as written, or -1 if its content can be indented. Elements are kept as written if they contain
+// text other than whitespace, or nothing but text. Elements that are not closed before the end tag of one of their
+// ancestors are never kept on one line.
+func markupInlineEnd(tokens []markupToken, start int, ancestors []string, html bool) int {
+	var open []string
+	text, elements := false, false
+
+	for i := start + 1; i < len(tokens); i++ {
+		t := tokens[i]
+
+		switch t.kind {
+		case markupStart:
+			elements = true
+			if !t.selfClosing && !(html && htmlVoidElements[strings.ToLower(t.name)]) {
+				open = append(open, t.name)
+			}
+		case markupEnd:
+			matched := false
+			for j := len(open) - 1; j >= 0; j-- {
+				if markupNamesEqual(open[j], t.name, html) {
+					open = open[:j]
+					matched = true
+					break
+				}
+			}
+
+			if !matched && markupNamesEqual(tokens[start].name, t.name, html) {
+				if text || !elements {
+					return i
+				}
+
+				return -1
+			} else if !matched {
+				for _, a := range ancestors {
+					if markupNamesEqual(a, t.name, html) {
+						return -1
+					}
+				}
+			}
+		case markupText:
+			if len(open) == 0 && (t.verbatim || strings.TrimSpace(t.text) != "") {
+				text = true
+			}
+		default:
+			elements = true
+		}
+	}
+
+	return -1
+}
+
+// markupNamesEqual returns true if two tag names are the same. HTML tag names are not case-sensitive.
+func markupNamesEqual(a string, b string, html bool) bool {
+	if html {
+		return strings.EqualFold(a, b)
+	}
+
+	return a == b
+}
@@ -0,0 +1,47 @@
+package parsers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_XMLBodyParser_ParseBytes(t *testing.T) {
+	text, err := NewXMLBodyParser().ParseBytes([]byte(`<?xml version="1.0"?><s:Envelope xmlns:s="urn:x"><s:Body>` +
+		`<Get id="1"/><Name>  Ann </Name><Note>a <b>b</b>` + "\n" + ` c</Note><!-- note --></s:Body></s:Envelope>`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<?xml version=\"1.0\"?>\n<s:Envelope xmlns:s=\"urn:x\">\n  <s:Body>\n    <Get id=\"1\"/>\n"+
+		"    <Name>  Ann </Name>\n    <Note>a <b>b</b>\n c</Note>\n    <!-- note -->\n  </s:Body>\n</s:Envelope>", text)
+}
+
+func Test_XMLBodyParser_ParseBytesMalformed(t *testing.T) {
+	_, err := NewXMLBodyParser().ParseBytes([]byte(`<a><b></a>`))
+
+	assert.Error(t, err)
+}
+
+func Test_HTMLBodyParser_ParseBytesMalformed(t *testing.T) {
+	text, err := NewHTMLBodyParser().ParseBytes([]byte(`<html><body><p class=x>1 < 2<br></p><ul><li>a</li>` +
+		`</div><script>if (a<b) {}</script></BODY>`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<html>\n  <body>\n    <p class=x>1 < 2<br></p>\n    <ul>\n      <li>a</li>\n      </div>\n"+
+		"      <script>if (a<b) {}</script>\n  </BODY>", text)
+}
+
+func Test_HTMLBodyParser_ParseBytesUnclosed(t *testing.T) {
+	text, err := NewHTMLBodyParser().ParseBytes([]byte(`<body><ul><li>1<li><b>2</b></ul><p>a<p>b<div>` +
+		`<table><tr><td>x<td>y<tr><td>z</table></div><select><option>o<option>p</select></body>`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "<body>\n  <ul>\n    <li>1\n    <li>\n      <b>2</b>\n  </ul>\n  <p>a\n  <p>b\n  <div>\n"+
+		"    <table>\n      <tr>\n        <td>x\n        <td>y\n      <tr>\n        <td>z\n    </table>\n  </div>\n"+
+		"  <select>\n    <option>o\n    <option>p\n  </select>\n</body>", text)
+}
+
+func Test_XMLBodyParser_Highlight(t *testing.T) {
+	text, err := NewXMLBodyParser().Highlight([]byte(`<a x="[red]">[b]</a>`), testSyntaxColors)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[t]<a[-] [a]x[-][p]=[-][s]\"[red[]\"[-][t]>[-][b[][t]</a>[-]", text)
+}
@@ -1,6 +1,8 @@
 package parsers
 
 import (
+	"github.com/mbpolan/lull/internal/theme"
+	"github.com/rivo/tview"
 	"io"
 	"net/http"
 )
@@ -28,3 +30,8 @@ func (n *NoopBodyParser) Parse(res *http.Response) (string, error) {
 func (n *NoopBodyParser) ParseBytes(body []byte) (string, error) {
 	return string(body), nil
 }
+
+// Highlight returns the original body bytes as-is, escaping any text that would be read as a color tag.
+func (n *NoopBodyParser) Highlight(body []byte, _ theme.SyntaxColors) (string, error) {
+	return tview.Escape(string(body)), nil
+}
@@ -8,6 +8,11 @@ import (
 const (
 	bodyParserNoop string = "noop"
 	bodyParserJson string = "json"
+	bodyParserXML  string = "xml"
+	bodyParserHTML string = "html"
+	bodyParserYAML string = "yaml"
+	bodyParserCSV  string = "csv"
+	bodyParserForm string = "form"
 )
 
 var instance BodyParserProvider
@@ -26,6 +31,11 @@ func Setup() {
 	instance.cache = map[string]BodyParser{
 		bodyParserNoop: noop,
 		bodyParserJson: NewJSONBodyParser(),
+		bodyParserXML:  NewXMLBodyParser(),
+		bodyParserHTML: NewHTMLBodyParser(),
+		bodyParserYAML: NewYAMLBodyParser(),
+		bodyParserCSV:  NewCSVBodyParser(),
+		bodyParserForm: NewFormBodyParser(),
 	}
 }
 
@@ -45,5 +55,34 @@ func GetBodyParserForContentType(contentType string) BodyParser {
 		return instance.cache[bodyParserJson]
 	}
 
+	if IsXMLContentType(contentType) {
+		return instance.cache[bodyParserXML]
+	}
+
+	switch mt := mediaType(contentType); {
+	case mt == "text/html":
+		return instance.cache[bodyParserHTML]
+	case mt == "application/yaml" || mt == "application/x-yaml" || mt == "text/yaml" || mt == "text/x-yaml":
+		return instance.cache[bodyParserYAML]
+	case strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+yaml"):
+		return instance.cache[bodyParserYAML]
+	case mt == "text/csv":
+		return instance.cache[bodyParserCSV]
+	case mt == "application/x-www-form-urlencoded":
+		return instance.cache[bodyParserForm]
+	}
+
 	return instance.cache[bodyParserNoop]
 }
+
+// IsXMLContentType returns true if the content type is "application/xml", "text/xml" or an XML-based type such as
+// "application/soap+xml".
+func IsXMLContentType(contentType string) bool {
+	mt := mediaType(contentType)
+	return mt == "application/xml" || mt == "text/xml" || strings.HasPrefix(mt, "application/") && strings.HasSuffix(mt, "+xml")
+}
+
+// mediaType returns the content type without any parameters, such as the charset.
+func mediaType(contentType string) string {
+	return strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+}
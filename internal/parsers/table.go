@@ -0,0 +1,59 @@
+package parsers
+
+import (
+	"github.com/rivo/tview"
+	"strings"
+)
+
+// tableColumnGap is the space between columns of a table.
+const tableColumnGap = "  "
+
+// formatTable writes the headers and rows as text with each column aligned. The color of each cell is given by
+// cellColor, which is called with a row of -1 for the headers.
+func formatTable(headers []string, rows [][]string, style syntaxStyle, cellColor func(row int, column int) string) string {
+	all := append([][]string{headers}, rows...)
+
+	var widths []int
+	for _, r := range all {
+		for i, c := range r {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+
+			if w := tview.TaggedStringWidth(tview.Escape(tableCell(c))); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+
+	lines := make([]string, len(all))
+	for i, r := range all {
+		// empty cells at the end of a row are left out to avoid trailing spaces
+		last := len(r) - 1
+		for last >= 0 && tableCell(r[last]) == "" {
+			last--
+		}
+
+		var b strings.Builder
+		for j, c := range r[:last+1] {
+			c = tableCell(c)
+			if j > 0 {
+				b.WriteString(tableColumnGap)
+			}
+
+			b.WriteString(style.paint(cellColor(i-1, j), c))
+			if j < last {
+				b.WriteString(strings.Repeat(" ", widths[j]-tview.TaggedStringWidth(tview.Escape(c))))
+			}
+		}
+
+		lines[i] = b.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// tableCell returns the text of a cell on a single line.
+func tableCell(text string) string {
+	return strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ").Replace(text)
+}
@@ -0,0 +1,53 @@
+package parsers
+
+import (
+	"encoding/csv"
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func Test_CSVBodyParser_Format(t *testing.T) {
+	text, err := NewCSVBodyParser().format([]byte("id,name,note\n1,Ann,\n22,\"Bo\nb\",x\n"), syntaxStyle{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "id  name  note\n1   Ann\n22  Bo b  x", text)
+}
+
+func Test_CSVBodyParser_ParseBytes(t *testing.T) {
+	body := "id,name\n1,\"Ann, B\"\n22,\"Bo\nb\"\n"
+	text, err := NewCSVBodyParser().ParseBytes([]byte(body))
+
+	assert.NoError(t, err)
+	assert.Equal(t, body, text)
+
+	records, err := csv.NewReader(strings.NewReader(text)).ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"id", "name"}, {"1", "Ann, B"}, {"22", "Bo\nb"}}, records)
+}
+
+func Test_FormBodyParser_ParseBytes(t *testing.T) {
+	text, err := NewFormBodyParser().ParseBytes([]byte("a=1&b=hello+world&c=%5Bx%5D&d"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "a=1&b=hello+world&c=%5Bx%5D&d=", text)
+
+	values, err := url.ParseQuery(text)
+	assert.NoError(t, err)
+	assert.Equal(t, url.Values{"a": {"1"}, "b": {"hello world"}, "c": {"[x]"}, "d": {""}}, values)
+}
+
+func Test_FormBodyParser_ParseTable(t *testing.T) {
+	headers, rows, err := NewFormBodyParser().ParseTable([]byte("b=hello+world&a=%5Bx%5D&c"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Name", "Value"}, headers)
+	assert.Equal(t, [][]string{{"b", "hello world"}, {"a", "[x]"}, {"c", ""}}, rows)
+}
+
+func Test_FormBodyParser_ParseTableInvalid(t *testing.T) {
+	_, _, err := NewFormBodyParser().ParseTable([]byte("a=%zz"))
+
+	assert.Error(t, err)
+}
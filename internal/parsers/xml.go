@@ -0,0 +1,73 @@
+package parsers
+
+import (
+	"bytes"
+	"encoding/xml"
+	"github.com/mbpolan/lull/internal/theme"
+	"github.com/pkg/errors"
+	"io"
+	"net/http"
+)
+
+// XMLBodyParser is a parser for "application/xml", "text/xml" and other XML content types.
+type XMLBodyParser struct {
+}
+
+// NewXMLBodyParser returns an instance of XMLBodyParser.
+func NewXMLBodyParser() *XMLBodyParser {
+	return new(XMLBodyParser)
+}
+
+// Parse returns a formatted and indented XML response body.
+func (x *XMLBodyParser) Parse(res *http.Response) (string, error) {
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return x.ParseBytes(data)
+}
+
+// ParseBytes returns a formatted and indented XML response body for the given raw bytes.
+func (x *XMLBodyParser) ParseBytes(body []byte) (string, error) {
+	return x.format(body, syntaxStyle{})
+}
+
+// Highlight returns a formatted and indented XML response body for the given raw bytes, with tags and attributes in
+// their own colors.
+func (x *XMLBodyParser) Highlight(body []byte, colors theme.SyntaxColors) (string, error) {
+	return x.format(body, syntaxStyle{highlight: true, colors: colors})
+}
+
+func (x *XMLBodyParser) format(body []byte, style syntaxStyle) (string, error) {
+	if err := x.validate(body); err != nil {
+		return "", err
+	}
+
+	return formatMarkup(scanMarkup(string(body), false), false, style), nil
+}
+
+// validate returns an error if the body is not a well-formed XML document.
+func (x *XMLBodyParser) validate(body []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(body))
+	elements := 0
+
+	for {
+		t, err := dec.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+
+		if _, ok := t.(xml.StartElement); ok {
+			elements++
+		}
+	}
+
+	if elements == 0 {
+		return errors.New("no XML elements found")
+	}
+
+	return nil
+}
@@ -0,0 +1,188 @@
+package parsers
+
+import (
+	"bytes"
+	"github.com/mbpolan/lull/internal/theme"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// yamlKey matches the key of a mapping entry at the start of a line, after any indentation and sequence indicators.
+var yamlKey = regexp.MustCompile(`^("(?:[^"\\]|\\.)*"|'(?:[^']|'')*'|[^\s#'"{\[][^#]*?):(\s|$)`)
+
+// yamlNumber matches the numbers that YAML reads as integers or floats.
+var yamlNumber = regexp.MustCompile(`^[-+]?(\d[\d_]*(\.\d*)?([eE][-+]?\d+)?|\.\d+([eE][-+]?\d+)?|0x[\da-fA-F]+|0o[0-7]+|\.inf|\.Inf|\.nan|\.NaN)$`)
+
+// YAMLBodyParser is a parser for "application/yaml" and other YAML content types.
+type YAMLBodyParser struct {
+}
+
+// NewYAMLBodyParser returns an instance of YAMLBodyParser.
+func NewYAMLBodyParser() *YAMLBodyParser {
+	return new(YAMLBodyParser)
+}
+
+// Parse returns a normalized and prettified YAML response body.
+func (y *YAMLBodyParser) Parse(res *http.Response) (string, error) {
+	data, err := io.ReadAll(res.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return y.ParseBytes(data)
+}
+
+// ParseBytes returns a normalized and prettified YAML response body for the given raw bytes. Collections are written in
+// block style with consistent indentation, while comments and the order of keys are kept.
+func (y *YAMLBodyParser) ParseBytes(body []byte) (string, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(body))
+
+	var out bytes.Buffer
+	enc := yaml.NewEncoder(&out)
+	enc.SetIndent(2)
+
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+
+		yamlBlockStyle(&doc)
+		if err := enc.Encode(&doc); err != nil {
+			return "", err
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// Highlight returns a normalized and prettified YAML response body for the given raw bytes, with keys, values and
+// comments in their own colors.
+func (y *YAMLBodyParser) Highlight(body []byte, colors theme.SyntaxColors) (string, error) {
+	text, err := y.ParseBytes(body)
+	if err != nil {
+		return "", err
+	}
+
+	return highlightYAML(text, syntaxStyle{highlight: true, colors: colors}), nil
+}
+
+// yamlBlockStyle changes collections written in flow style, such as [1, 2], to block style.
+func yamlBlockStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle
+	for _, n := range node.Content {
+		yamlBlockStyle(n)
+	}
+}
+
+// highlightYAML adds color tags to a YAML document written in block style.
+func highlightYAML(text string, style syntaxStyle) string {
+	lines := strings.Split(text, "\n")
+
+	// lines indented past this column are the content of a block scalar, or -1 if there is none
+	block := -1
+
+	for i, line := range lines {
+		content := strings.TrimLeft(line, " ")
+		indent := len(line) - len(content)
+
+		if block > -1 && (indent > block || content == "") {
+			lines[i] = style.paint(style.colors.String, line)
+			continue
+		}
+
+		block = -1
+
+		var b strings.Builder
+		b.WriteString(line[:indent])
+
+		if content == "---" || content == "..." {
+			lines[i] = b.String() + style.paint(style.colors.Punctuation, content)
+			continue
+		}
+
+		// sequence entries may be nested on the same line, as in "- - a"
+		for strings.HasPrefix(content, "- ") || content == "-" {
+			b.WriteString(style.paint(style.colors.Punctuation, "-"))
+			content = strings.TrimPrefix(content, "-")
+			b.WriteString(content[:len(content)-len(strings.TrimLeft(content, " "))])
+			content = strings.TrimLeft(content, " ")
+			indent = len(line) - len(content)
+		}
+
+		if m := yamlKey.FindStringSubmatch(content); m != nil {
+			b.WriteString(style.paint(style.colors.Key, m[1]) + style.paint(style.colors.Punctuation, ":") + m[2])
+			content = content[len(m[0]):]
+		}
+
+		value, comment := yamlSplitComment(content)
+		b.WriteString(yamlValue(value, style))
+
+		if comment != "" {
+			b.WriteString(style.paint(style.colors.Null, comment))
+		}
+
+		// the lines that follow a block scalar indicator, such as | or >-, are its content
+		if v := strings.TrimSpace(value); v != "" && strings.IndexByte("|>", v[0]) > -1 {
+			block = indent
+		}
+
+		lines[i] = b.String()
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// yamlSplitComment separates a value from any comment that follows it on the same line.
+func yamlSplitComment(text string) (string, string) {
+	quote := byte(0)
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || text[i-1] == ' '):
+			return text[:i], text[i:]
+		}
+	}
+
+	return text, ""
+}
+
+// yamlValue returns a scalar value in the color for its type. Any trailing space is kept as-is.
+func yamlValue(text string, style syntaxStyle) string {
+	value := strings.TrimRight(text, " ")
+	space := text[len(value):]
+
+	color := style.colors.String
+	switch {
+	case value == "":
+		return text
+	case value == "true" || value == "false":
+		color = style.colors.Boolean
+	case value == "null" || value == "~":
+		color = style.colors.Null
+	case yamlNumber.MatchString(value):
+		color = style.colors.Number
+	case value == "{}" || value == "[]" || strings.IndexByte("|>", value[0]) > -1:
+		color = style.colors.Punctuation
+	case strings.IndexByte("&*!", value[0]) > -1:
+		color = style.colors.Attribute
+	}
+
+	return style.paint(color, value) + space
+}
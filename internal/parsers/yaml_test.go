@@ -0,0 +1,21 @@
+package parsers
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_YAMLBodyParser_ParseBytes(t *testing.T) {
+	text, err := NewYAMLBodyParser().ParseBytes([]byte("name:   x # note\nlist: [1, true]\nmap: {a: null}\n---\nk: ~\n"))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "name: x # note\nlist:\n  - 1\n  - true\nmap:\n  a: null\n---\nk: ~", text)
+}
+
+func Test_YAMLBodyParser_Highlight(t *testing.T) {
+	text, err := NewYAMLBodyParser().Highlight([]byte("a: '[x]'\nb: |\n  [red]\nc:\n  - d: 1.5 # n\n"), testSyntaxColors)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "[k]a[-][p]:[-] [s]'[x[]'[-]\n[k]b[-][p]:[-] [p]|[-]\n[s]  [red[][-]\n[k]c[-][p]:[-]\n"+
+		"  [p]-[-] [k]d[-][p]:[-] [n]1.5[-] [z]# n[-]", text)
+}
@@ -0,0 +1,80 @@
+package secrets
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/pkg/errors"
+	"golang.org/x/term"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PassphraseEnvVar is the environment variable that can provide the passphrase for the secret store.
+const PassphraseEnvVar = "LULL_PASSPHRASE"
+
+// CommandPassphrase returns a PassphraseFunc that runs an external command, such as "pass show lull", and uses the
+// first line of its output as the passphrase.
+func CommandPassphrase(command string) PassphraseFunc {
+	return func() (string, error) {
+		var stdout bytes.Buffer
+
+		cmd := exec.Command("sh", "-c", command)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = &stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return "", errors.Wrapf(err, "passphrase command failed")
+		}
+
+		line, _, _ := strings.Cut(stdout.String(), "\n")
+		return strings.TrimRight(line, "\r"), nil
+	}
+}
+
+// EnvPassphrase returns a PassphraseFunc that reads the passphrase from the PassphraseEnvVar environment variable.
+func EnvPassphrase() PassphraseFunc {
+	return func() (string, error) {
+		return os.Getenv(PassphraseEnvVar), nil
+	}
+}
+
+// TerminalPassphrase returns a PassphraseFunc that prompts for the passphrase on the terminal without echoing it.
+// If standard input is not a terminal, a line is read from it as-is instead.
+func TerminalPassphrase(prompt string) PassphraseFunc {
+	return func() (string, error) {
+		fmt.Print(prompt)
+		defer fmt.Println()
+
+		fd := int(os.Stdin.Fd())
+		if term.IsTerminal(fd) {
+			data, err := term.ReadPassword(fd)
+			return string(data), err
+		}
+
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err
+	}
+}
+
+// CachedPassphrase returns a PassphraseFunc that obtains the passphrase from fn the first time it is needed and
+// reuses it afterwards. This allows several stores to share a passphrase without prompting for it more than once.
+func CachedPassphrase(fn PassphraseFunc) PassphraseFunc {
+	var cached string
+
+	return func() (string, error) {
+		if cached != "" {
+			return cached, nil
+		}
+
+		passphrase, err := fn()
+		if err != nil {
+			return "", err
+		}
+
+		cached = passphrase
+		return cached, nil
+	}
+}
@@ -0,0 +1,203 @@
+package secrets
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"github.com/mbpolan/lull/internal/system"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+	"io"
+	"os"
+)
+
+const storeVersion = 1
+
+// scrypt parameters used to derive encryption keys from a passphrase.
+const (
+	keyCostN   = 32768
+	keyCostR   = 8
+	keyCostP   = 1
+	keyLength  = 32
+	saltLength = 16
+)
+
+// PassphraseFunc returns the passphrase used to encrypt and decrypt secrets.
+type PassphraseFunc func() (string, error)
+
+// envelope is the on-disk representation of the encrypted secrets.
+type envelope struct {
+	Version int
+	Salt    []byte
+	Nonce   []byte
+	Data    []byte
+}
+
+// Store persists secret values in a file encrypted with a key derived from a passphrase. Deriving the key is
+// deliberately slow, so the key is kept along with its salt and reused for as long as the store is open.
+type Store struct {
+	path       string
+	passphrase PassphraseFunc
+	cached     string
+	salt       []byte
+	key        []byte
+}
+
+// NewStore returns a new instance of Store that saves secrets to a file at path. The passphrase function is invoked
+// at most once, the first time secrets need to be read or written.
+func NewStore(path string, passphrase PassphraseFunc) *Store {
+	s := new(Store)
+	s.path = path
+	s.passphrase = passphrase
+
+	return s
+}
+
+// Exists returns true if the store has previously saved secrets.
+func (s *Store) Exists() bool {
+	_, err := os.Stat(s.path)
+	return err == nil
+}
+
+// Load reads and decrypts all secrets from the store. If no secrets were previously saved, an empty map is returned.
+func (s *Store) Load() (map[string]string, error) {
+	secrets := map[string]string{}
+	if !s.Exists() {
+		return secrets, nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, errors.Wrap(err, "malformed secrets file")
+	}
+
+	if env.Version != storeVersion {
+		return nil, errors.Errorf("unsupported secrets file version %d", env.Version)
+	}
+
+	gcm, err := s.cipher(env.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Data, nil)
+	if err != nil {
+		return nil, errors.New("incorrect passphrase or corrupted secrets file")
+	}
+
+	if err := json.Unmarshal(plaintext, &secrets); err != nil {
+		return nil, errors.Wrap(err, "malformed secrets")
+	}
+
+	return secrets, nil
+}
+
+// Save encrypts and writes the secrets to the store, replacing any previously saved secrets. If there are no
+// secrets to save, the store file is removed instead.
+func (s *Store) Save(secrets map[string]string) error {
+	if len(secrets) == 0 {
+		if s.Exists() {
+			return os.Remove(s.path)
+		}
+
+		return nil
+	}
+
+	plaintext, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+
+	env := envelope{
+		Version: storeVersion,
+		Salt:    s.salt,
+	}
+
+	// keep the salt that the key was derived with, if any, so that the key does not need to be derived again
+	if env.Salt == nil {
+		env.Salt = make([]byte, saltLength)
+		if _, err := io.ReadFull(rand.Reader, env.Salt); err != nil {
+			return err
+		}
+	}
+
+	gcm, err := s.cipher(env.Salt)
+	if err != nil {
+		return err
+	}
+
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, env.Nonce); err != nil {
+		return err
+	}
+
+	env.Data = gcm.Seal(nil, env.Nonce, plaintext, nil)
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return err
+	}
+
+	return system.WriteFileAtomic(s.path, data, 0600)
+}
+
+// cipher returns an AES-GCM cipher keyed from the passphrase and salt.
+func (s *Store) cipher(salt []byte) (cipher.AEAD, error) {
+	key, err := s.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// deriveKey returns the key derived from the passphrase and salt, reusing the most recently derived key if it was
+// derived from the same salt.
+func (s *Store) deriveKey(salt []byte) ([]byte, error) {
+	if s.key != nil && bytes.Equal(s.salt, salt) {
+		return s.key, nil
+	}
+
+	passphrase, err := s.getPassphrase()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := scrypt.Key([]byte(passphrase), salt, keyCostN, keyCostR, keyCostP, keyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	s.salt = salt
+	s.key = key
+	return key, nil
+}
+
+// getPassphrase returns the passphrase for the store, requesting it if it has not yet been provided.
+func (s *Store) getPassphrase() (string, error) {
+	if s.cached != "" {
+		return s.cached, nil
+	}
+
+	passphrase, err := s.passphrase()
+	if err != nil {
+		return "", errors.Wrap(err, "could not obtain passphrase")
+	} else if passphrase == "" {
+		return "", errors.New("no passphrase provided")
+	}
+
+	s.cached = passphrase
+	return passphrase, nil
+}
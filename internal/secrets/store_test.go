@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func staticPassphrase(passphrase string) PassphraseFunc {
+	return func() (string, error) {
+		return passphrase, nil
+	}
+}
+
+func Test_Store_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets")
+	secrets := map[string]string{"item/Password": "hunter2"}
+
+	err := NewStore(path, staticPassphrase("correct horse")).Save(secrets)
+	assert.NoError(t, err)
+
+	loaded, err := NewStore(path, staticPassphrase("correct horse")).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, secrets, loaded)
+}
+
+func Test_Store_NotPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets")
+
+	err := NewStore(path, staticPassphrase("correct horse")).Save(map[string]string{"item/Password": "hunter2"})
+	assert.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "hunter2"))
+}
+
+func Test_Store_WrongPassphrase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets")
+
+	err := NewStore(path, staticPassphrase("correct horse")).Save(map[string]string{"item/Password": "hunter2"})
+	assert.NoError(t, err)
+
+	_, err = NewStore(path, staticPassphrase("battery staple")).Load()
+	assert.Error(t, err)
+}
+
+func Test_Store_EmptyRemovesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets")
+	store := NewStore(path, staticPassphrase("correct horse"))
+
+	err := store.Save(map[string]string{"item/Password": "hunter2"})
+	assert.NoError(t, err)
+
+	err = store.Save(map[string]string{})
+	assert.NoError(t, err)
+	assert.False(t, store.Exists())
+}
+
+func Test_Store_ReusesKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets")
+	prompts := 0
+	s := NewStore(path, func() (string, error) {
+		prompts++
+		return "correct horse", nil
+	})
+
+	assert.NoError(t, s.Save(map[string]string{"item/Password": "hunter2"}))
+	salt := s.salt
+	assert.NoError(t, s.Save(map[string]string{"item/Password": "hunter3"}))
+	assert.Equal(t, salt, s.salt)
+	assert.Equal(t, 1, prompts)
+
+	loaded, err := NewStore(path, staticPassphrase("correct horse")).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"item/Password": "hunter3"}, loaded)
+}
@@ -5,7 +5,7 @@ import "net/http"
 // BasicAuthentication contains username and password parameters for HTTP basic auth.
 type BasicAuthentication struct {
 	Username string
-	Password string
+	Password string `secret:"true"`
 }
 
 // NewBasicAuthentication returns a new instance of BasicAuthentication.
@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// BearerAuthentication contains a static token sent in the Authorization header.
+type BearerAuthentication struct {
+	Token string `secret:"true"`
+}
+
+// NewBearerAuthentication returns a new instance of BearerAuthentication.
+func NewBearerAuthentication(token string) *BearerAuthentication {
+	return &BearerAuthentication{
+		Token: token,
+	}
+}
+
+func (a *BearerAuthentication) Type() string {
+	return "bearer"
+}
+
+func (a *BearerAuthentication) Prepare() (*http.Request, error) {
+	// no additional network request needed
+	return nil, nil
+}
+
+func (a *BearerAuthentication) Apply(req *http.Request, res *http.Response) error {
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", a.Token))
+	return nil
+}
@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+)
+
+// InheritedAuthentication is a placeholder scheme indicating that authentication parameters should be taken from
+// the nearest ancestor group that defines its own authentication.
+type InheritedAuthentication struct {
+}
+
+// NewInheritedAuthentication returns a new instance of InheritedAuthentication.
+func NewInheritedAuthentication() *InheritedAuthentication {
+	return &InheritedAuthentication{}
+}
+
+func (a *InheritedAuthentication) Type() string {
+	return "inherit"
+}
+
+func (a *InheritedAuthentication) Prepare() (*http.Request, error) {
+	return nil, errors.New("inherited authentication must be resolved before use")
+}
+
+func (a *InheritedAuthentication) Apply(req *http.Request, res *http.Response) error {
+	return errors.New("inherited authentication must be resolved before use")
+}
@@ -17,7 +17,7 @@ type oauth2Response struct {
 type OAuth2RequestAuthentication struct {
 	TokenURL     string
 	ClientID     string
-	ClientSecret string
+	ClientSecret string `secret:"true"`
 	GrantType    string
 	Scope        string
 }
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"reflect"
+)
+
+// secretTag is the struct tag used to mark fields containing sensitive values that should not be stored in plaintext.
+const secretTag = "secret"
+
+// Secrets returns the values of all string fields marked as secret in the authentication scheme, keyed by field
+// name. Empty values are not included.
+func Secrets(a RequestAuthentication) map[string]string {
+	secrets := map[string]string{}
+	visitSecretFields(a, func(name string, field reflect.Value) {
+		if v := field.String(); v != "" {
+			secrets[name] = v
+		}
+	})
+
+	return secrets
+}
+
+// SetSecrets applies previously extracted secret values to the authentication scheme. Fields that are not marked as
+// secret are ignored.
+func SetSecrets(a RequestAuthentication, secrets map[string]string) {
+	visitSecretFields(a, func(name string, field reflect.Value) {
+		if v, ok := secrets[name]; ok {
+			field.SetString(v)
+		}
+	})
+}
+
+// ClearSecrets sets all fields marked as secret in the authentication scheme to empty values.
+func ClearSecrets(a RequestAuthentication) {
+	visitSecretFields(a, func(_ string, field reflect.Value) {
+		field.SetString("")
+	})
+}
+
+// visitSecretFields invokes the visitor for each settable string field tagged as a secret.
+func visitSecretFields(a RequestAuthentication, visitor func(name string, field reflect.Value)) {
+	if a == nil {
+		return
+	}
+
+	v := reflect.ValueOf(a)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Tag.Get(secretTag) != "true" || f.Type.Kind() != reflect.String {
+			continue
+		}
+
+		visitor(f.Name, v.Field(i))
+	}
+}
@@ -4,22 +4,36 @@ import (
 	"errors"
 	"github.com/google/uuid"
 	"net/http"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
 )
 
+// maxVariableDepth is the number of times variable references are expanded to resolve nested references.
+const maxVariableDepth = 5
+
+var variablePattern = regexp.MustCompile(`{{[^{}]+}}`)
+
 // CollectionItem is a grouping or a single, saved REST API request with a given name.
 type CollectionItem struct {
-	UUID           uuid.UUID
-	IsGroup        bool
-	Name           string
-	Method         string
-	URL            string
-	Headers        map[string][]string
-	RequestBody    *RequestBody
-	Authentication ItemAuthentication
-	Result         *HTTPResult     `json:"-"` // do not serialize
-	Parent         *CollectionItem `json:"-"` // prepare circular references when serializing
-	Children       []*CollectionItem
+	UUID            uuid.UUID
+	IsGroup         bool
+	Name            string
+	Method          string
+	URL             string
+	Headers         map[string][]string
+	InheritHeaders  bool
+	RequestBody     *RequestBody
+	Authentication  ItemAuthentication
+	Variables       map[string]string `json:",omitempty"`
+	SecretVariables []string          `json:",omitempty"` // names of variables whose values are kept as secrets
+	Tags            []string          `json:",omitempty"`
+	Description     string            `json:",omitempty"` // markdown notes documenting the item
+	Source          string            // identifies the external definition this item was imported from, if any
+	Result          *HTTPResult       `json:"-"` // do not serialize
+	Parent          *CollectionItem   `json:"-"` // prepare circular references when serializing
+	Children        []*CollectionItem
 }
 
 // RequestBody stores the request body and associated content information.
@@ -43,6 +57,7 @@ func NewCollectionGroup(name string, parent *CollectionItem) *CollectionItem {
 	g.UUID = uuid.New()
 	g.IsGroup = true
 	g.Name = name
+	g.Headers = map[string][]string{}
 	g.Parent = parent
 	g.Children = []*CollectionItem{}
 
@@ -64,6 +79,61 @@ func NewCollectionRequest(name, method string, url string, parent *CollectionIte
 	return r
 }
 
+// Clone returns a deep copy of this item and all of its descendents, each with a new UUID. The copy will have the given
+// parent, though it is not added to the parent's children. Responses and the sources of imported items are not copied.
+func (c *CollectionItem) Clone(parent *CollectionItem) *CollectionItem {
+	item := &CollectionItem{
+		UUID:           uuid.New(),
+		IsGroup:        c.IsGroup,
+		Name:           c.Name,
+		Method:         c.Method,
+		URL:            c.URL,
+		Headers:        map[string][]string{},
+		InheritHeaders: c.InheritHeaders,
+		Authentication: c.Authentication.Clone(),
+		Description:    c.Description,
+		Parent:         parent,
+	}
+
+	for k, v := range c.Headers {
+		item.Headers[k] = append([]string{}, v...)
+	}
+
+	if c.Variables != nil {
+		item.Variables = map[string]string{}
+		for k, v := range c.Variables {
+			item.Variables[k] = v
+		}
+	}
+
+	if c.SecretVariables != nil {
+		item.SecretVariables = append([]string{}, c.SecretVariables...)
+	}
+
+	if c.Tags != nil {
+		item.Tags = append([]string{}, c.Tags...)
+	}
+
+	if c.RequestBody != nil {
+		body := *c.RequestBody
+		item.RequestBody = &body
+	}
+
+	if c.IsGroup {
+		item.Children = []*CollectionItem{}
+		for _, child := range c.Children {
+			item.Children = append(item.Children, child.Clone(item))
+		}
+	}
+
+	return item
+}
+
+// Documented returns true if the item has a description or any tags.
+func (c *CollectionItem) Documented() bool {
+	return c.Description != "" || len(c.Tags) > 0
+}
+
 // AddHeader adds a header with the given key and value.
 func (c *CollectionItem) AddHeader(key string, value string) {
 	if _, ok := c.Headers[key]; !ok {
@@ -87,6 +157,142 @@ func (c *CollectionItem) RemoveHeader(key string) {
 	delete(c.Headers, key)
 }
 
+// EffectiveHeaders returns a copy of this item's headers merged with any headers inherited from its ancestors. Headers
+// defined on an item take precedence over those with the same key defined on more distant ancestors. Keys are compared
+// and returned in their canonical form, since header names are not case-sensitive.
+func (c *CollectionItem) EffectiveHeaders() map[string][]string {
+	headers := map[string][]string{}
+	merge := func(item *CollectionItem) {
+		own := map[string]bool{}
+		for k, v := range item.Headers {
+			k = http.CanonicalHeaderKey(k)
+			if _, ok := headers[k]; !ok || own[k] {
+				headers[k] = append(headers[k], v...)
+				own[k] = true
+			}
+		}
+	}
+
+	merge(c)
+	if !c.InheritHeaders {
+		return headers
+	}
+
+	// walk up the tree starting with the nearest ancestor, stopping once an ancestor no longer inherits headers
+	ancestors := c.Ancestors()
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		merge(ancestors[i])
+
+		if !ancestors[i].InheritHeaders {
+			break
+		}
+	}
+
+	return headers
+}
+
+// InheritedHeaders returns headers that this item inherits from its ancestors, excluding those that are overridden
+// by this item's own headers.
+func (c *CollectionItem) InheritedHeaders() map[string][]string {
+	headers := c.EffectiveHeaders()
+	for k := range c.Headers {
+		delete(headers, http.CanonicalHeaderKey(k))
+	}
+
+	return headers
+}
+
+// EffectiveAuthentication returns the authentication that should be used for this item, along with the item that
+// defines it. If this item inherits its authentication, the nearest ancestor that does not inherit its own
+// authentication will be used. If no such ancestor exists, the returned authentication will be empty.
+func (c *CollectionItem) EffectiveAuthentication() (ItemAuthentication, *CollectionItem) {
+	if !c.Authentication.Inherited() {
+		return c.Authentication, c
+	}
+
+	ancestors := c.Ancestors()
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		if !ancestors[i].Authentication.Inherited() {
+			return ancestors[i].Authentication, ancestors[i]
+		}
+	}
+
+	return ItemAuthentication{}, nil
+}
+
+// IsSecretVariable returns true if a variable defined on this item is marked as holding a secret value.
+func (c *CollectionItem) IsSecretVariable(name string) bool {
+	for _, n := range c.SecretVariables {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SetSecretVariable marks or unmarks a variable defined on this item as holding a secret value.
+func (c *CollectionItem) SetSecretVariable(name string, secret bool) {
+	if secret == c.IsSecretVariable(name) {
+		return
+	} else if secret {
+		c.SecretVariables = append(c.SecretVariables, name)
+		sort.Strings(c.SecretVariables)
+		return
+	}
+
+	var names []string
+	for _, n := range c.SecretVariables {
+		if n != name {
+			names = append(names, n)
+		}
+	}
+
+	c.SecretVariables = names
+}
+
+// EffectiveVariables returns a copy of the variables defined on this item and all of its ancestors. Variables defined
+// on an item take precedence over those with the same name defined on more distant ancestors.
+func (c *CollectionItem) EffectiveVariables() map[string]string {
+	variables := map[string]string{}
+	for _, i := range append(c.Ancestors(), c) {
+		for k, v := range i.Variables {
+			variables[k] = v
+		}
+	}
+
+	return variables
+}
+
+// ExpandVariables replaces references to variables in the form {{name}} with their effective values. References to
+// variables that are not defined are left as-is.
+func (c *CollectionItem) ExpandVariables(text string) string {
+	variables := c.EffectiveVariables()
+	if len(variables) == 0 {
+		return text
+	}
+
+	// variables may refer to other variables, so expand a few times to resolve nested references
+	for n := 0; n < maxVariableDepth && strings.Contains(text, "{{"); n++ {
+		expanded := variablePattern.ReplaceAllStringFunc(text, func(ref string) string {
+			name := strings.TrimSpace(ref[2 : len(ref)-2])
+			if v, ok := variables[name]; ok {
+				return v
+			}
+
+			return ref
+		})
+
+		if expanded == text {
+			break
+		}
+
+		text = expanded
+	}
+
+	return text
+}
+
 // AddChild appends an item to the end of this item's children. If this item is not a group (isGroup is false),
 // then this method does nothing.
 func (c *CollectionItem) AddChild(item *CollectionItem) {
@@ -139,6 +345,27 @@ func (c *CollectionItem) RemoveChild(item *CollectionItem) error {
 	return errors.New("child not found under item")
 }
 
+// MoveChild moves a child item by offset positions amongst its siblings. If the item is not a child of this item, or
+// it would be moved past either end of the list of children, then nothing is changed and false is returned.
+func (c *CollectionItem) MoveChild(item *CollectionItem, offset int) bool {
+	for i, child := range c.Children {
+		if child != item {
+			continue
+		}
+
+		j := i + offset
+		if j < 0 || j >= len(c.Children) {
+			return false
+		}
+
+		c.Children = append(c.Children[:i], c.Children[i+1:]...)
+		c.Children = append(c.Children[:j], append([]*CollectionItem{item}, c.Children[j:]...)...)
+		return true
+	}
+
+	return false
+}
+
 // IsDescendentOf returns true if this item is a descendent of the given item.
 func (c *CollectionItem) IsDescendentOf(item *CollectionItem) bool {
 	// TODO: this can probably be more efficient by walking the tree and short-circuiting when we find a matching
@@ -153,7 +380,7 @@ func (c *CollectionItem) IsDescendentOf(item *CollectionItem) bool {
 }
 
 // Ancestors returns the collection items that form a path to this item. The list will be ordered by most distant to
-// most recent ancestor, with the current item being the last element in the list.
+// most recent ancestor, with the current item's parent being the last element in the list.
 func (c *CollectionItem) Ancestors() []*CollectionItem {
 	var ancestors []*CollectionItem
 
@@ -165,7 +392,7 @@ func (c *CollectionItem) Ancestors() []*CollectionItem {
 
 	reversed := make([]*CollectionItem, len(ancestors))
 	for i := len(ancestors) - 1; i >= 0; i-- {
-		reversed[len(ancestors)-i] = ancestors[i]
+		reversed[len(ancestors)-1-i] = ancestors[i]
 	}
 
 	return reversed
@@ -0,0 +1,159 @@
+package state
+
+import (
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Ancestors_OrderedFromRoot(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	group := NewCollectionGroup("group", root)
+	req := NewCollectionRequest("req", "GET", "", group)
+
+	ancestors := req.Ancestors()
+
+	assert.Equal(t, []*CollectionItem{root, group}, ancestors)
+}
+
+func Test_EffectiveHeaders_NoInheritance(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	root.AddHeader("X-Root", "root")
+	req := NewCollectionRequest("req", "GET", "", root)
+	req.AddHeader("X-Req", "req")
+
+	headers := req.EffectiveHeaders()
+
+	assert.Equal(t, map[string][]string{"X-Req": {"req"}}, headers)
+}
+
+func Test_EffectiveHeaders_InheritsFromAncestors(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	root.AddHeader("X-Root", "root")
+	root.AddHeader("X-Override", "root")
+	group := NewCollectionGroup("group", root)
+	group.InheritHeaders = true
+	group.AddHeader("X-Group", "group")
+	req := NewCollectionRequest("req", "GET", "", group)
+	req.InheritHeaders = true
+	req.AddHeader("X-Override", "req")
+
+	headers := req.EffectiveHeaders()
+
+	assert.Equal(t, map[string][]string{
+		"X-Root":     {"root"},
+		"X-Group":    {"group"},
+		"X-Override": {"req"},
+	}, headers)
+}
+
+func Test_EffectiveHeaders_StopsAtNonInheritingGroup(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	root.AddHeader("X-Root", "root")
+	group := NewCollectionGroup("group", root)
+	group.AddHeader("X-Group", "group")
+	req := NewCollectionRequest("req", "GET", "", group)
+	req.InheritHeaders = true
+
+	headers := req.EffectiveHeaders()
+
+	assert.Equal(t, map[string][]string{"X-Group": {"group"}}, headers)
+}
+
+func Test_EffectiveHeaders_CanonicalKeys(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	root.AddHeader("content-type", "text/plain")
+	root.AddHeader("x-trace", "root")
+	req := NewCollectionRequest("req", "GET", "", root)
+	req.InheritHeaders = true
+	req.AddHeader("Content-Type", "application/json")
+
+	assert.Equal(t, map[string][]string{
+		"Content-Type": {"application/json"},
+		"X-Trace":      {"root"},
+	}, req.EffectiveHeaders())
+	assert.Equal(t, map[string][]string{"X-Trace": {"root"}}, req.InheritedHeaders())
+}
+
+func Test_EffectiveAuthentication_Inherited(t *testing.T) {
+	basic := auth.NewBasicAuthentication("user", "pass")
+	root := NewCollectionGroup("root", nil)
+	root.Authentication.Data = basic
+	group := NewCollectionGroup("group", root)
+	group.Authentication.Data = auth.NewInheritedAuthentication()
+	req := NewCollectionRequest("req", "GET", "", group)
+	req.Authentication.Data = auth.NewInheritedAuthentication()
+
+	effective, source := req.EffectiveAuthentication()
+
+	assert.Equal(t, root, source)
+	assert.Equal(t, basic, effective.Data)
+}
+
+func Test_EffectiveAuthentication_AncestorHasNone(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	req := NewCollectionRequest("req", "GET", "", root)
+	req.Authentication.Data = auth.NewInheritedAuthentication()
+
+	effective, source := req.EffectiveAuthentication()
+
+	assert.Equal(t, root, source)
+	assert.True(t, effective.None())
+}
+
+func Test_ExpandVariables_NestedAndOverridden(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	root.Variables = map[string]string{"host": "example.com", "base": "https://{{host}}/v1"}
+	group := NewCollectionGroup("group", root)
+	group.Variables = map[string]string{"host": "api.example.com"}
+	req := NewCollectionRequest("req", "GET", "", group)
+
+	expanded := req.ExpandVariables("{{base}}/users/{{ id }}?q={{missing}}")
+
+	assert.Equal(t, "https://api.example.com/v1/users/{{ id }}?q={{missing}}", expanded)
+}
+
+func Test_MoveChild_WithinBounds(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	a := NewCollectionRequest("a", "GET", "", root)
+	b := NewCollectionRequest("b", "GET", "", root)
+	c := NewCollectionRequest("c", "GET", "", root)
+	root.AddChild(a)
+	root.AddChild(b)
+	root.AddChild(c)
+
+	assert.True(t, root.MoveChild(a, 2))
+	assert.Equal(t, []*CollectionItem{b, c, a}, root.Children)
+
+	assert.False(t, root.MoveChild(a, 1))
+	assert.Equal(t, []*CollectionItem{b, c, a}, root.Children)
+}
+
+func Test_Clone_DeepCopiesDescendents(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	group := NewCollectionGroup("group", root)
+	group.Authentication.Data = auth.NewBasicAuthentication("user", "secret")
+	req := NewCollectionRequest("req", "POST", "http://localhost", group)
+	req.AddHeader("X-Req", "req")
+	req.RequestBody = &RequestBody{Payload: "{}", ContentType: "application/json"}
+	group.AddChild(req)
+
+	clone := group.Clone(root)
+
+	assert.NotEqual(t, group.UUID, clone.UUID)
+	assert.Equal(t, root, clone.Parent)
+	assert.Equal(t, group.Authentication, clone.Authentication)
+	assert.NotSame(t, group.Authentication.Data, clone.Authentication.Data)
+
+	assert.Len(t, clone.Children, 1)
+	child := clone.Children[0]
+	assert.NotEqual(t, req.UUID, child.UUID)
+	assert.Equal(t, clone, child.Parent)
+	assert.Equal(t, req.Headers, child.Headers)
+	assert.Equal(t, req.RequestBody, child.RequestBody)
+
+	child.AddHeader("X-Req", "clone")
+	child.RequestBody.Payload = "[]"
+	assert.Equal(t, []string{"req"}, req.Headers["X-Req"])
+	assert.Equal(t, "{}", req.RequestBody.Payload)
+}
@@ -0,0 +1,105 @@
+package state
+
+import (
+	"strings"
+)
+
+// Filter narrows the requests in a collection to those matching a set of terms. Terms may be qualified with a field
+// name, such as method:POST, url:/users or tag:smoke; unqualified terms match the name or URL of a request.
+type Filter struct {
+	terms []filterTerm
+}
+
+type filterTerm struct {
+	field string
+	value string
+}
+
+// ParseFilter returns a Filter for the given text. If the text contains no terms, nil is returned.
+func ParseFilter(text string) *Filter {
+	f := &Filter{}
+	for _, t := range strings.Fields(text) {
+		term := filterTerm{
+			value: strings.ToLower(t),
+		}
+
+		if field, value, ok := strings.Cut(t, ":"); ok && value != "" {
+			switch strings.ToLower(field) {
+			case "method", "url", "tag":
+				term.field = strings.ToLower(field)
+				term.value = strings.ToLower(value)
+			}
+		}
+
+		f.terms = append(f.terms, term)
+	}
+
+	if len(f.terms) == 0 {
+		return nil
+	}
+
+	return f
+}
+
+// Matches returns true if the item is a request that satisfies all terms in the filter.
+func (f *Filter) Matches(item *CollectionItem) bool {
+	if item.IsGroup {
+		return false
+	}
+
+	for _, t := range f.terms {
+		if !t.matches(item) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// MatchesAny returns true if the item, or any of its descendents, matches the filter.
+func (f *Filter) MatchesAny(item *CollectionItem) bool {
+	if !item.IsGroup {
+		return f.Matches(item)
+	}
+
+	for _, c := range item.Children {
+		if f.MatchesAny(c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Requests returns the requests in a group and its descendents that match the filter, in the order they appear in the
+// collection. A nil filter matches all requests.
+func (f *Filter) Requests(group *CollectionItem) []*CollectionItem {
+	var requests []*CollectionItem
+	visitCollection(group, func(item *CollectionItem) {
+		if !item.IsGroup && (f == nil || f.Matches(item)) {
+			requests = append(requests, item)
+		}
+	})
+
+	return requests
+}
+
+func (t filterTerm) matches(item *CollectionItem) bool {
+	switch t.field {
+	case "method":
+		return strings.ToLower(item.Method) == t.value
+	case "url":
+		return strings.Contains(strings.ToLower(item.URL), t.value)
+	case "tag":
+		for _, tag := range item.Tags {
+			if strings.ToLower(tag) == t.value {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return strings.Contains(strings.ToLower(item.Name), t.value) ||
+			strings.Contains(strings.ToLower(item.URL), t.value)
+	}
+}
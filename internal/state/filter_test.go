@@ -0,0 +1,46 @@
+package state
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_ParseFilter_Empty(t *testing.T) {
+	assert.Nil(t, ParseFilter("  "))
+}
+
+func Test_Filter_QualifiedTerms(t *testing.T) {
+	req := NewCollectionRequest("Create user", "POST", "http://localhost/users", nil)
+	req.Tags = []string{"Smoke"}
+
+	assert.True(t, ParseFilter("method:post url:/users tag:smoke").Matches(req))
+	assert.True(t, ParseFilter("create").Matches(req))
+	assert.False(t, ParseFilter("method:GET").Matches(req))
+	assert.False(t, ParseFilter("tag:destructive").Matches(req))
+}
+
+func Test_Filter_MatchesAnyDescendent(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	group := NewCollectionGroup("group", root)
+	req := NewCollectionRequest("req", "DELETE", "", group)
+	root.AddChild(group)
+	group.AddChild(req)
+
+	assert.True(t, ParseFilter("method:delete").MatchesAny(root))
+	assert.False(t, ParseFilter("method:get").MatchesAny(root))
+}
+
+func Test_Filter_Requests(t *testing.T) {
+	root := NewCollectionGroup("root", nil)
+	group := NewCollectionGroup("group", root)
+	smoke := NewCollectionRequest("smoke", "GET", "", group)
+	smoke.Tags = []string{"smoke"}
+	other := NewCollectionRequest("other", "DELETE", "", root)
+	root.AddChild(group)
+	root.AddChild(other)
+	group.AddChild(smoke)
+
+	assert.Equal(t, []*CollectionItem{smoke}, ParseFilter("tag:smoke").Requests(root))
+	assert.Equal(t, []*CollectionItem{smoke, other}, ParseFilter("").Requests(root))
+	assert.Empty(t, ParseFilter("tag:smoke").Requests(other))
+}
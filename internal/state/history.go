@@ -0,0 +1,27 @@
+package state
+
+import (
+	"net/http"
+	"time"
+)
+
+// maxHistoryEntries is the number of most recent requests kept in the history.
+const maxHistoryEntries = 100
+
+// HistoryEntry records a request that was sent during the current session along with the response it received.
+type HistoryEntry struct {
+	Name        string
+	Request     *http.Request
+	RequestBody *RequestBody
+	Result      *HTTPResult
+	StartTime   time.Time
+}
+
+// AddHistory appends an entry to the history, discarding the oldest entry if the history is full.
+func (a *AppState) AddHistory(entry *HistoryEntry) {
+	a.History = append(a.History, entry)
+
+	if len(a.History) > maxHistoryEntries {
+		a.History = a.History[len(a.History)-maxHistoryEntries:]
+	}
+}
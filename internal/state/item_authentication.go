@@ -14,6 +14,22 @@ func (i *ItemAuthentication) None() bool {
 	return i.Data == nil
 }
 
+// Inherited returns true if the authentication parameters should be taken from an ancestor group.
+func (i *ItemAuthentication) Inherited() bool {
+	_, ok := i.Data.(*auth.InheritedAuthentication)
+	return ok
+}
+
+// Clone returns a deep copy of the authentication parameters.
+func (i *ItemAuthentication) Clone() ItemAuthentication {
+	var c ItemAuthentication
+	if data, err := json.Marshal(i); err == nil {
+		_ = json.Unmarshal(data, &c)
+	}
+
+	return c
+}
+
 func (i *ItemAuthentication) MarshalJSON() ([]byte, error) {
 	m := make(map[string]any)
 
@@ -56,6 +72,16 @@ func (i *ItemAuthentication) UnmarshalJSON(b []byte) error {
 		}
 
 		i.Data = &basic
+	} else if authType == (&auth.BearerAuthentication{}).Type() {
+		var bearer auth.BearerAuthentication
+		err = json.Unmarshal(*raw["Data"], &bearer)
+		if err != nil {
+			return err
+		}
+
+		i.Data = &bearer
+	} else if authType == (&auth.InheritedAuthentication{}).Type() {
+		i.Data = auth.NewInheritedAuthentication()
 	}
 
 	return nil
@@ -0,0 +1,35 @@
+package state
+
+import (
+	"github.com/google/uuid"
+)
+
+// Layout describes the arrangement of the user interface, so that it can be restored in a later session. Zero values
+// indicate that the default arrangement should be used.
+type Layout struct {
+	Collapsed       map[uuid.UUID]bool `json:",omitempty"` // groups that are collapsed in the collection tree
+	RequestPage     string             `json:",omitempty"`
+	ResponsePage    string             `json:",omitempty"`
+	CollectionWidth int                `json:",omitempty"` // width of the collection tree, in cells
+	RequestShare    int                `json:",omitempty"` // percentage of the content width used by the request
+	BodyPreview     bool               `json:",omitempty"` // show a highlighted preview next to the request body
+}
+
+// IsCollapsed returns true if the group with the given UUID is collapsed.
+func (l *Layout) IsCollapsed(id uuid.UUID) bool {
+	return l.Collapsed[id]
+}
+
+// SetCollapsed records whether the group with the given UUID is collapsed.
+func (l *Layout) SetCollapsed(id uuid.UUID, collapsed bool) {
+	if !collapsed {
+		delete(l.Collapsed, id)
+		return
+	}
+
+	if l.Collapsed == nil {
+		l.Collapsed = map[uuid.UUID]bool{}
+	}
+
+	l.Collapsed[id] = true
+}
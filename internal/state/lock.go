@@ -0,0 +1,140 @@
+package state
+
+import (
+	"fmt"
+	"github.com/mbpolan/lull/internal/system"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// LockedError indicates that another running instance of lull holds a lock.
+type LockedError struct {
+	PID  int
+	Host string
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("in use by another instance of lull (pid %d on %s)", e.PID, e.Host)
+}
+
+// Lock is a file that signals that an instance of lull is using the app state stored next to it. The file records the
+// id of the process and the name of the host holding the lock.
+type Lock struct {
+	path string
+	held bool
+}
+
+// NewLock returns a Lock that uses the file at path.
+func NewLock(path string) *Lock {
+	return &Lock{
+		path: path,
+	}
+}
+
+// Check returns a LockedError if another running instance of lull holds the lock.
+func (l *Lock) Check() error {
+	if l.held {
+		return nil
+	}
+
+	pid, host, err := l.read()
+	if err != nil {
+		return nil
+	}
+
+	if l.stale(pid, host) {
+		return nil
+	}
+
+	return &LockedError{PID: pid, Host: host}
+}
+
+// Acquire takes the lock. If another running instance of lull holds the lock, a LockedError is returned. A lock that
+// was left behind by an instance that is no longer running is taken over.
+func (l *Lock) Acquire() error {
+	if l.held {
+		return nil
+	}
+
+	if err := l.Check(); err != nil {
+		return err
+	}
+
+	// remove a stale lock before creating a new one
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if os.IsExist(err) {
+		// another instance took the lock in the meantime
+		return l.Check()
+	} else if err != nil {
+		return err
+	}
+
+	host, _ := os.Hostname()
+	_, err = fmt.Fprintf(f, "%d\n%s\n", os.Getpid(), host)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		_ = os.Remove(l.path)
+		return err
+	}
+
+	l.held = true
+	return nil
+}
+
+// Release removes the lock if it is held by this instance.
+func (l *Lock) Release() error {
+	if !l.held {
+		return nil
+	}
+
+	l.held = false
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// read returns the process id and host name recorded in the lock file.
+func (l *Lock) read() (int, string, error) {
+	data, err := os.ReadFile(l.path)
+	if err != nil {
+		return 0, "", err
+	}
+
+	lines := strings.SplitN(string(data), "\n", 3)
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return 0, "", err
+	}
+
+	host := ""
+	if len(lines) > 1 {
+		host = strings.TrimSpace(lines[1])
+	}
+
+	return pid, host, nil
+}
+
+// stale returns true if the process that created the lock is no longer running. Locks held by processes on other
+// hosts, such as when the app state is on a network drive, are never considered stale.
+func (l *Lock) stale(pid int, host string) bool {
+	if current, err := os.Hostname(); err != nil || current != host {
+		return false
+	}
+
+	return pid == os.Getpid() || !system.ProcessRunning(pid)
+}
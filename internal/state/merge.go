@@ -0,0 +1,232 @@
+package state
+
+import (
+	"encoding/json"
+	"github.com/google/uuid"
+)
+
+// mergeCollections combines the changes made to a collection by this instance of lull (ours) and by another instance
+// (theirs) since both started from the same collection (base). Items are matched by their UUIDs. Items changed, added
+// or moved by this instance take precedence, while items only changed or added by the other instance are kept. Items
+// deleted by this instance are removed, unless the other instance has changed them since. The merged collection is
+// built by modifying theirs in place.
+func mergeCollections(base *CollectionItem, ours *CollectionItem, theirs *CollectionItem) *CollectionItem {
+	baseItems := indexCollection(base)
+	ourItems := indexCollection(ours)
+	merged := indexCollection(theirs)
+
+	// the root of each collection is the same group, even if it was somehow recreated
+	merged[ours.UUID] = theirs
+	if ours.UUID != base.UUID {
+		baseItems[ours.UUID] = base
+	}
+
+	visitCollection(ours, func(item *CollectionItem) {
+		original, inBase := baseItems[item.UUID]
+		changed := !inBase || itemFingerprint(original) != itemFingerprint(item)
+
+		if target, ok := merged[item.UUID]; ok {
+			if changed {
+				copyItemFields(target, item)
+			}
+
+			// follow items that this instance moved to another group
+			if inBase && item.Parent != nil && original.Parent != nil && item.Parent.UUID != original.Parent.UUID {
+				moveMergedItem(target, merged[item.Parent.UUID])
+			}
+
+			return
+		} else if inBase && !changed {
+			// the other instance deleted this item, and this instance did not change it
+			return
+		}
+
+		// add items created by this instance, or changed by it after the other instance deleted them
+		parent := theirs
+		if item.Parent != nil {
+			if p, ok := merged[item.Parent.UUID]; ok && p.IsGroup {
+				parent = p
+			}
+		}
+
+		added := *item
+		added.Parent = parent
+		if added.IsGroup {
+			added.Children = []*CollectionItem{}
+		}
+
+		insertMergedItem(parent, &added, previousSibling(item, merged))
+		merged[item.UUID] = &added
+	})
+
+	// remove items deleted by this instance, unless the other instance changed them
+	for id, original := range baseItems {
+		if _, ok := ourItems[id]; ok {
+			continue
+		}
+
+		if target, ok := merged[id]; ok && target.Parent != nil && itemFingerprint(target) == itemFingerprint(original) {
+			_ = target.Parent.RemoveChild(target)
+			target.Parent = nil
+		}
+	}
+
+	// keep the order of children in groups where this instance reordered them
+	visitCollection(ours, func(item *CollectionItem) {
+		original, inBase := baseItems[item.UUID]
+		target, inMerged := merged[item.UUID]
+		if item.IsGroup && inBase && inMerged && target.IsGroup && childOrderChanged(original, item) {
+			reorderMergedChildren(target, item)
+		}
+	})
+
+	return theirs
+}
+
+// childOrderChanged returns true if the children that two versions of a group have in common are in a different
+// order.
+func childOrderChanged(a *CollectionItem, b *CollectionItem) bool {
+	common := func(group *CollectionItem, other *CollectionItem) []uuid.UUID {
+		in := map[uuid.UUID]bool{}
+		for _, c := range other.Children {
+			in[c.UUID] = true
+		}
+
+		var ids []uuid.UUID
+		for _, c := range group.Children {
+			if in[c.UUID] {
+				ids = append(ids, c.UUID)
+			}
+		}
+
+		return ids
+	}
+
+	x, y := common(a, b), common(b, a)
+	for i := range x {
+		if x[i] != y[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// reorderMergedChildren puts the children of a merged group in the order of the group's children in ours. Children
+// that ours does not have, such as those added by the other instance, stay right before the child they preceded.
+func reorderMergedChildren(group *CollectionItem, ours *CollectionItem) {
+	current := map[uuid.UUID]*CollectionItem{}
+	for _, c := range group.Children {
+		current[c.UUID] = c
+	}
+
+	var ordered []*CollectionItem
+	placed := map[uuid.UUID]bool{}
+	for _, c := range ours.Children {
+		if m, ok := current[c.UUID]; ok && !placed[c.UUID] {
+			ordered = append(ordered, m)
+			placed[c.UUID] = true
+		}
+	}
+
+	for i := len(group.Children) - 1; i >= 0; i-- {
+		c := group.Children[i]
+		if placed[c.UUID] {
+			continue
+		}
+
+		// insert before the nearest following sibling, or last if there is none
+		at := len(ordered)
+		if i < len(group.Children)-1 {
+			for j, o := range ordered {
+				if o == group.Children[i+1] {
+					at = j
+					break
+				}
+			}
+		}
+
+		ordered = append(ordered[:at], append([]*CollectionItem{c}, ordered[at:]...)...)
+		placed[c.UUID] = true
+	}
+
+	group.Children = ordered
+}
+
+// indexCollection returns all items in a collection keyed by their UUIDs.
+func indexCollection(root *CollectionItem) map[uuid.UUID]*CollectionItem {
+	items := map[uuid.UUID]*CollectionItem{}
+	visitCollection(root, func(item *CollectionItem) {
+		items[item.UUID] = item
+	})
+
+	return items
+}
+
+// visitCollection invokes the visitor for an item and all of its descendents, visiting parents before their children.
+func visitCollection(item *CollectionItem, visitor func(item *CollectionItem)) {
+	visitor(item)
+	for _, c := range item.Children {
+		visitCollection(c, visitor)
+	}
+}
+
+// itemFingerprint returns a representation of an item's own properties, excluding its children, that can be compared
+// to determine if an item has changed.
+func itemFingerprint(item *CollectionItem) string {
+	c := *item
+	c.Parent = nil
+	c.Children = nil
+	c.Result = nil
+
+	data, _ := json.Marshal(&c)
+	return string(data)
+}
+
+// copyItemFields replaces the properties of dst with those of src, keeping dst's place in its collection.
+func copyItemFields(dst *CollectionItem, src *CollectionItem) {
+	parent, children, result := dst.Parent, dst.Children, dst.Result
+	*dst = *src
+	dst.Parent, dst.Children, dst.Result = parent, children, result
+}
+
+// moveMergedItem moves an item into another group, unless that would place a group inside itself.
+func moveMergedItem(item *CollectionItem, group *CollectionItem) {
+	if group == nil || !group.IsGroup || item.Parent == nil || item.Parent == group || group == item || group.IsDescendentOf(item) {
+		return
+	}
+
+	_ = item.Parent.RemoveChild(item)
+	item.Parent = group
+	group.AddChild(item)
+}
+
+// previousSibling returns the merged counterpart of the nearest sibling before the item, if any.
+func previousSibling(item *CollectionItem, merged map[uuid.UUID]*CollectionItem) *CollectionItem {
+	if item.Parent == nil {
+		return nil
+	}
+
+	var previous *CollectionItem
+	for _, c := range item.Parent.Children {
+		if c == item {
+			return previous
+		}
+
+		if m, ok := merged[c.UUID]; ok {
+			previous = m
+		}
+	}
+
+	return nil
+}
+
+// insertMergedItem inserts an item into a group after the given sibling, or first if the sibling is not in the group.
+func insertMergedItem(group *CollectionItem, item *CollectionItem, after *CollectionItem) {
+	if after != nil && after.Parent == group {
+		group.InsertChildAfter(item, after)
+		return
+	}
+
+	group.Children = append([]*CollectionItem{item}, group.Children...)
+}
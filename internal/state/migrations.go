@@ -0,0 +1,99 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the app state format written by this version of lull. It must be incremented
+// whenever a change is made to the format that requires existing files to be migrated.
+const SchemaVersion = 1
+
+// migration upgrades a raw app state document from one schema version to the next.
+type migration func(doc map[string]any) error
+
+// migrations upgrade app state documents step by step, where the migration at index i upgrades a document from
+// version i to version i+1.
+var migrations = []migration{
+	migrateV0ToV1,
+}
+
+// NewerVersionError is returned when app state was written by a newer version of lull than this one. Supported is
+// the newest schema version this version of lull can read.
+type NewerVersionError struct {
+	Version   int
+	Supported int
+}
+
+func (e *NewerVersionError) Error() string {
+	return fmt.Sprintf("app state uses schema version %d, but this version of lull only supports up to version %d; "+
+		"please upgrade lull to open it", e.Version, e.Supported)
+}
+
+// SchemaVersionOf returns the schema version of serialized app state. Files written before versioning was
+// introduced are version 0.
+func SchemaVersionOf(data []byte) (int, error) {
+	var doc struct {
+		Version int
+	}
+
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, err
+	}
+
+	return doc.Version, nil
+}
+
+// migrate upgrades serialized app state to the current schema version.
+func migrate(data []byte) ([]byte, error) {
+	version, err := SchemaVersionOf(data)
+	if err != nil {
+		return nil, err
+	} else if version > SchemaVersion {
+		return nil, &NewerVersionError{Version: version, Supported: SchemaVersion}
+	} else if version == SchemaVersion {
+		return data, nil
+	}
+
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v](doc); err != nil {
+			return nil, fmt.Errorf("failed to migrate app state from version %d: %w", v, err)
+		}
+
+		doc["Version"] = v + 1
+	}
+
+	return json.Marshal(doc)
+}
+
+// migrateV0ToV1 adds empty headers to items saved before groups could define headers.
+func migrateV0ToV1(doc map[string]any) error {
+	for _, key := range []string{"Collection", "ActiveItem", "SelectedItem"} {
+		if item, ok := doc[key].(map[string]any); ok {
+			walkRawItems(item, func(item map[string]any) {
+				if item["Headers"] == nil {
+					item["Headers"] = map[string]any{}
+				}
+			})
+		}
+	}
+
+	return nil
+}
+
+// walkRawItems visits a raw collection item and all of its descendants.
+func walkRawItems(item map[string]any, visitor func(item map[string]any)) {
+	visitor(item)
+
+	children, _ := item["Children"].([]any)
+	for _, c := range children {
+		if child, ok := c.(map[string]any); ok {
+			walkRawItems(child, visitor)
+		}
+	}
+}
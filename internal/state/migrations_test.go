@@ -0,0 +1,70 @@
+package state
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const v0StateFixture = `{
+  "Collection": {
+    "UUID": "7a9c2b9e-8a57-4cf5-9c7c-0c1b0c0f2d11",
+    "IsGroup": true,
+    "Name": "Default",
+    "Authentication": {"Type": "none"},
+    "Children": [
+      {
+        "UUID": "1f2d3c4b-5a69-4788-9900-aabbccddeeff",
+        "Name": "Unnamed",
+        "Method": "GET",
+        "URL": "https://example.com",
+        "Headers": {"Accept": ["application/json"]},
+        "Authentication": {"Type": "none"}
+      }
+    ]
+  }
+}`
+
+func Test_DeserializeAppState_MigratesV0(t *testing.T) {
+	st, err := DeserializeAppState([]byte(v0StateFixture))
+
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaVersion, st.Version)
+	assert.Equal(t, map[string][]string{}, st.Collection.Headers)
+	assert.Equal(t, map[string][]string{"Accept": {"application/json"}}, st.Collection.Children[0].Headers)
+	assert.Equal(t, st.Collection.Children[0], st.ActiveItem)
+}
+
+func Test_DeserializeAppState_RejectsNewerVersion(t *testing.T) {
+	_, err := DeserializeAppState([]byte(`{"Version": 999, "Collection": {}}`))
+
+	assert.IsType(t, &NewerVersionError{}, err)
+}
+
+func Test_FileStorage_BacksUpBeforeMigrating(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	assert.NoError(t, os.WriteFile(path, []byte(v0StateFixture), 0600))
+	s := NewFileStorage(path)
+
+	st, err := s.Load()
+	assert.NoError(t, err)
+	assert.FileExists(t, path+".v0")
+
+	assert.NoError(t, s.Save(st))
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	version, err := SchemaVersionOf(data)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
+}
+
+func Test_FileStorage_RefusesToOverwriteNewerVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Version": 999}`), 0600))
+
+	err := NewFileStorage(path).Save(NewAppState())
+
+	assert.IsType(t, &NewerVersionError{}, err)
+}
@@ -0,0 +1,109 @@
+package state
+
+import (
+	"fmt"
+	"github.com/mbpolan/lull/internal/state/auth"
+	"strings"
+)
+
+// secretVariablePrefix is prepended to the names of variables when they are stored as secrets, so that they do not
+// collide with the names of secret authentication fields.
+const secretVariablePrefix = "variables/"
+
+// secretVariableNames are parts of variable names that suggest that a variable holds a secret value.
+var secretVariableNames = []string{"secret", "password", "passwd", "token", "apikey", "api_key", "api-key",
+	"credential", "private"}
+
+// SecretStore persists sensitive values outside the plaintext app state file.
+type SecretStore interface {
+	// Load returns all previously saved secrets.
+	Load() (map[string]string, error)
+
+	// Save persists the given secrets, replacing any that were previously saved.
+	Save(secrets map[string]string) error
+}
+
+// Secrets returns all secret values contained in the collection. Each secret is keyed by the UUID of the item that
+// owns it and the name of the field it belongs to.
+func (a *AppState) Secrets() map[string]string {
+	secrets := map[string]string{}
+
+	a.walkCollection(a.Collection, func(item *CollectionItem) bool {
+		for k, v := range auth.Secrets(item.Authentication.Data) {
+			secrets[secretKey(item, k)] = v
+		}
+
+		for k, v := range item.Variables {
+			if item.IsSecretVariable(k) && v != "" {
+				secrets[secretKey(item, secretVariablePrefix+k)] = v
+			}
+		}
+
+		return false
+	})
+
+	return secrets
+}
+
+// ApplySecrets restores secret values, previously returned by Secrets, to the items in the collection.
+func (a *AppState) ApplySecrets(secrets map[string]string) {
+	a.walkCollection(a.Collection, func(item *CollectionItem) bool {
+		prefix := secretKey(item, "")
+		itemSecrets := map[string]string{}
+
+		for k, v := range secrets {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+
+			field := strings.TrimPrefix(k, prefix)
+			if strings.HasPrefix(field, secretVariablePrefix) {
+				// variables stay marked as secret, including those that were stored before they could be marked
+				name := strings.TrimPrefix(field, secretVariablePrefix)
+				if _, defined := item.Variables[name]; defined {
+					item.Variables[name] = v
+					item.SetSecretVariable(name, true)
+				}
+			} else {
+				itemSecrets[field] = v
+			}
+		}
+
+		auth.SetSecrets(item.Authentication.Data, itemSecrets)
+		return false
+	})
+}
+
+// clearSecrets removes all secret values from the items in the collection.
+func (a *AppState) clearSecrets() {
+	a.walkCollection(a.Collection, func(item *CollectionItem) bool {
+		auth.ClearSecrets(item.Authentication.Data)
+
+		// secret variables are kept without their values, so that they remain defined
+		for k := range item.Variables {
+			if item.IsSecretVariable(k) {
+				item.Variables[k] = ""
+			}
+		}
+
+		return false
+	})
+}
+
+// SuggestSecretVariable returns true if the name of a variable suggests that it holds a secret value, such as a
+// password or an API key. This only decides whether a new variable is marked as secret by default.
+func SuggestSecretVariable(name string) bool {
+	name = strings.ToLower(name)
+	for _, n := range secretVariableNames {
+		if strings.Contains(name, n) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// secretKey returns the key under which a secret field of a collection item is stored.
+func secretKey(item *CollectionItem, field string) string {
+	return fmt.Sprintf("%s/%s", item.UUID.String(), field)
+}
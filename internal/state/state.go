@@ -2,17 +2,21 @@ package state
 
 import (
 	"encoding/json"
+	"errors"
 	"github.com/google/uuid"
 	"github.com/rivo/tview"
 )
 
 // AppState represents the state of the application.
 type AppState struct {
+	Version      int
 	Focused      tview.Primitive
 	LastError    error `json:"-"` // do not serialize
 	Collection   *CollectionItem
 	SelectedItem *CollectionItem
 	ActiveItem   *CollectionItem
+	History      []*HistoryEntry `json:"-"` // do not serialize
+	Layout       Layout
 }
 
 // NewAppState returns a new AppState instance.
@@ -31,10 +35,20 @@ func NewAppState() *AppState {
 	return a
 }
 
-// DeserializeAppState returns an AppState from data.
+// DeserializeAppState returns an AppState from data, migrating it from an older schema version if needed. If the
+// data was written by a newer version of lull, a NewerVersionError is returned.
 func DeserializeAppState(data []byte) (*AppState, error) {
+	data, err := migrate(data)
+	if err != nil {
+		return nil, err
+	}
+
 	appState := &AppState{}
-	err := json.Unmarshal(data, appState)
+	if err := json.Unmarshal(data, appState); err != nil {
+		return nil, err
+	} else if appState.Collection == nil {
+		return nil, errors.New("app state does not contain a collection")
+	}
 
 	// fix parent field pointers
 	appState.updateCollectionTree(appState.Collection, nil)
@@ -55,21 +69,31 @@ func DeserializeAppState(data []byte) (*AppState, error) {
 	// ensure that active and selected items are not nil
 	appState.EnsureDefaultItems()
 
-	return appState, err
+	return appState, nil
 }
 
-// Serialize returns the bytes representing the app state.
+// Serialize returns the bytes representing the app state in the current schema version.
 func (a *AppState) Serialize() ([]byte, error) {
+	a.Version = SchemaVersion
 	return json.Marshal(*a)
 }
 
-// EnsureDefaultItems ensures that both the active and selected item are not nil. If either is nil, they will be set
-// to the first non-group CollectionItem in the collection.
+// EnsureDefaultItems ensures that both the active and selected item are not nil and are part of the collection. If
+// either is not, they will be set to the first non-group CollectionItem in the collection.
 func (a *AppState) EnsureDefaultItems() {
 	nonGroupFilter := func(item *CollectionItem) bool {
 		return !item.IsGroup
 	}
 
+	// items may have been detached if the contents of their group were replaced, in which case the replacement with
+	// the same UUID is used if there is one
+	if a.ActiveItem != nil && !a.ActiveItem.IsDescendentOf(a.Collection) {
+		a.ActiveItem = a.collectionItemByUUID(a.ActiveItem.UUID, a.Collection)
+	}
+	if a.SelectedItem != nil && a.SelectedItem != a.Collection && !a.SelectedItem.IsDescendentOf(a.Collection) {
+		a.SelectedItem = a.collectionItemByUUID(a.SelectedItem.UUID, a.Collection)
+	}
+
 	if a.ActiveItem == nil {
 		a.ActiveItem = a.FirstCollectionItem(nonGroupFilter)
 	}
@@ -110,6 +134,51 @@ func (a *AppState) RemoveCollectionItem(item *CollectionItem) {
 	item.Parent = nil
 }
 
+// MoveCollectionItem moves an item, along with all of its children, to the end of another group. An error is
+// returned if the item is the root of the collection, or if it would be moved into itself or one of its descendents.
+func (a *AppState) MoveCollectionItem(item *CollectionItem, group *CollectionItem) error {
+	if err := a.ValidateMoveCollectionItem(item, group); err != nil {
+		return err
+	}
+
+	if err := item.Parent.RemoveChild(item); err != nil {
+		return err
+	}
+
+	item.Parent = group
+	group.AddChild(item)
+
+	return nil
+}
+
+// ValidateMoveCollectionItem returns an error if an item cannot be moved into a group, for the same reasons as
+// MoveCollectionItem, without moving it.
+func (a *AppState) ValidateMoveCollectionItem(item *CollectionItem, group *CollectionItem) error {
+	if item.Parent == nil {
+		return errors.New("the root group cannot be moved")
+	} else if !group.IsGroup {
+		return errors.New("items can only be moved into groups")
+	} else if group == item || group.IsDescendentOf(item) {
+		return errors.New("a group cannot be moved into itself")
+	}
+
+	return nil
+}
+
+// Groups returns all groups in the collection, ordered as they appear in the collection tree.
+func (a *AppState) Groups() []*CollectionItem {
+	var groups []*CollectionItem
+	a.walkCollection(a.Collection, func(item *CollectionItem) bool {
+		if item.IsGroup {
+			groups = append(groups, item)
+		}
+
+		return false
+	})
+
+	return groups
+}
+
 // FirstCollectionItem returns the first CollectionItem that satisfies the filter predicate.
 func (a *AppState) FirstCollectionItem(filter func(item *CollectionItem) bool) *CollectionItem {
 	return a.walkCollection(a.Collection, filter)
@@ -1,24 +1,84 @@
 package state
 
 import (
-	"os"
+	"encoding/json"
+	"github.com/google/uuid"
+	"github.com/mbpolan/lull/internal/logger"
+	"github.com/pkg/errors"
+	"reflect"
 	"sync"
+	"time"
 )
 
+// autosaveCheckInterval is how often the autosave loop checks for pending changes.
+const autosaveCheckInterval = time.Second
+
+// AutosaveScheduler runs a save on the goroutine that owns the app state, so that the state is not modified while
+// it is being written.
+type AutosaveScheduler func(save func())
+
+// ReloadScheduler runs a reload on the goroutine that owns the app state, so that the state is not replaced while it
+// is being used.
+type ReloadScheduler func(reload func())
+
+// ReloadHandler is invoked after the app state was reloaded due to changes made to it outside of this instance of lull.
+type ReloadHandler func()
+
+// ConflictResolution is a way of resolving a conflict between the app state and changes made to it by another
+// instance of lull.
+type ConflictResolution int
+
+const (
+	// ConflictMerge combines changes from both instances, matching items by their UUIDs.
+	ConflictMerge ConflictResolution = iota
+
+	// ConflictReload discards changes made by this instance in favor of those made by the other instance.
+	ConflictReload
+
+	// ConflictOverwrite discards changes made by the other instance in favor of those made by this instance.
+	ConflictOverwrite
+)
+
+// ConflictError indicates that the stored app state was changed by another instance of lull since it was loaded.
+type ConflictError struct{}
+
+func (e *ConflictError) Error() string {
+	return "the app state was changed by another instance of lull since it was loaded"
+}
+
+// ConflictHandler is invoked when an autosave finds that the stored app state was changed by another instance of lull.
+// No further autosaves are made until the conflict is resolved.
+type ConflictHandler func()
+
 // Manager provides maintenance and lifecycle handling for AppState changes.
 type Manager struct {
-	state    *AppState
-	dirty    bool
-	savePath string
-	mutex    sync.Mutex
+	state        *AppState
+	dirty        bool
+	changedAt    time.Time
+	storage      Storage
+	secrets      SecretStore
+	savedSecrets map[string]string
+	stopAutosave chan bool
+	undo         []*snapshot
+	redo         []*snapshot
+	lock         *Lock
+	revision     string
+	base         []byte
+	conflicted   bool
+	onConflict   ConflictHandler
+	stopWatching chan bool
+	onReload     ReloadHandler
+	mutex        sync.Mutex
 }
 
-// NewStateManager returns an instance of Manager that handles an instance of AppState.
-func NewStateManager(state *AppState, savePath string) *Manager {
+// NewStateManager returns an instance of Manager that handles an instance of AppState, saving it to storage. The app
+// state is assumed to have been just loaded from storage.
+func NewStateManager(state *AppState, storage Storage) *Manager {
 	m := new(Manager)
 	m.state = state
 	m.dirty = false
-	m.savePath = savePath
+	m.storage = storage
+	m.track()
 
 	return m
 }
@@ -30,19 +90,411 @@ func (m *Manager) Get() *AppState {
 
 // SetDirty flags that the current app state has changed and should be saved to disk.
 func (m *Manager) SetDirty() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	m.dirty = true
+	m.changedAt = time.Now()
 }
 
-// Shutdown flushes any pending state updates to disk.
-func (m *Manager) Shutdown() error {
+// SetSecretStore sets the store used to persist secret values. When set, secrets are removed from the app state
+// before it is written to disk and saved in the store instead.
+func (m *Manager) SetSecretStore(store SecretStore) {
+	m.secrets = store
+	m.savedSecrets = nil
+}
+
+// SetConflictHandler sets the callback to invoke when an autosave finds that another instance of lull has changed the
+// stored app state.
+func (m *Manager) SetConflictHandler(handler ConflictHandler) {
+	m.onConflict = handler
+}
+
+// SetReloadHandler sets the callback to invoke after the app state was reloaded due to changes made to it outside of
+// this instance of lull.
+func (m *Manager) SetReloadHandler(handler ReloadHandler) {
+	m.onReload = handler
+}
+
+// Lock signals to other instances of lull that the storage is in use. If another running instance is already using
+// it, a LockedError is returned; the app state can still be used, but changes made by both instances will conflict.
+func (m *Manager) Lock() error {
+	shared, ok := m.storage.(SharedStorage)
+	if !ok {
+		return nil
+	}
+
+	m.lock = NewLock(shared.LockPath())
+	return m.lock.Acquire()
+}
+
+// StartAutosave periodically saves the app state once no further changes have been made to it for the debounce
+// interval. Saves are run through the scheduler.
+func (m *Manager) StartAutosave(debounce time.Duration, schedule AutosaveScheduler) {
+	m.stopAutosave = make(chan bool)
+	ticker := time.NewTicker(autosaveCheckInterval)
+
+	go func(stop chan bool) {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if m.pendingSince(debounce) {
+					schedule(m.autosave)
+				}
+			}
+		}
+	}(m.stopAutosave)
+}
+
+// StartWatching periodically checks if the stored app state was changed outside of this instance of lull, such as
+// when pulling changes to a workspace with git, and reloads it. Unsaved changes are merged with the reloaded app
+// state. Reloads are run through the scheduler. Only storage that can be shared between instances is watched.
+func (m *Manager) StartWatching(interval time.Duration, schedule ReloadScheduler) {
+	m.stopWatching = make(chan bool)
+	ticker := time.NewTicker(interval)
+
+	go func(stop chan bool) {
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				if m.changedElsewhere() {
+					schedule(m.reloadChanges)
+				}
+			}
+		}
+	}(m.stopWatching)
+}
+
+// Save writes the app state to disk if it has changed since it was last saved.
+func (m *Manager) Save() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
 	if !m.dirty {
 		return nil
 	}
 
-	data, err := m.state.Serialize()
+	if err := m.save(); err != nil {
+		return err
+	}
+
+	m.dirty = false
+	return nil
+}
+
+// Resolve resolves a conflict with changes made to the stored app state by another instance of lull, and saves the
+// result.
+func (m *Manager) Resolve(resolution ConflictResolution) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if resolution == ConflictOverwrite {
+		if err := m.write(); err != nil {
+			return err
+		}
+
+		m.dirty = false
+		m.conflicted = false
+		return nil
+	}
+
+	if err := m.reload(resolution == ConflictMerge); err != nil {
+		return err
+	}
+
+	if resolution == ConflictMerge {
+		if err := m.write(); err != nil {
+			return err
+		}
+	}
+
+	m.dirty = false
+	m.conflicted = false
+	return nil
+}
+
+// Shutdown stops autosaving, flushes any pending state updates to disk and releases the lock on the storage. If the
+// state cannot be saved, the lock is kept so that the caller can resolve the problem and save again.
+func (m *Manager) Shutdown() error {
+	if m.stopAutosave != nil {
+		close(m.stopAutosave)
+		m.stopAutosave = nil
+	}
+
+	if m.stopWatching != nil {
+		close(m.stopWatching)
+		m.stopWatching = nil
+	}
+
+	if err := m.Save(); err != nil {
+		return err
+	}
+
+	return m.unlock()
+}
+
+// Replace flushes any pending updates to the current app state, then replaces it with another app state that is
+// saved to the given storage and secret store.
+func (m *Manager) Replace(state *AppState, storage Storage, secrets SecretStore) error {
+	if err := m.Save(); err != nil {
+		return err
+	}
+
+	if err := m.unlock(); err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.state = state
+	m.storage = storage
+	m.secrets = secrets
+	m.savedSecrets = nil
+	m.dirty = false
+	m.conflicted = false
+	m.clearUndo()
+	m.track()
+
+	return nil
+}
+
+// pendingSince returns true if the app state has unsaved changes and has not changed for the given duration.
+func (m *Manager) pendingSince(debounce time.Duration) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.dirty && time.Since(m.changedAt) >= debounce
+}
+
+func (m *Manager) autosave() {
+	// wait for a conflict to be resolved before trying again
+	if m.isConflicted() {
+		return
+	}
+
+	err := m.Save()
+	if _, ok := err.(*ConflictError); ok {
+		m.mutex.Lock()
+		m.conflicted = true
+		m.mutex.Unlock()
+
+		if m.onConflict != nil {
+			m.onConflict()
+		}
+	} else if err != nil {
+		logger.Errorf("failed to autosave app state: %s", err)
+	}
+}
+
+func (m *Manager) isConflicted() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	return m.conflicted
+}
+
+// changedElsewhere returns true if the stored app state was changed outside of this instance of lull since it was
+// loaded or last saved by this instance. While a conflict is pending, changes are not reported.
+func (m *Manager) changedElsewhere() bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	shared, ok := m.storage.(SharedStorage)
+	if !ok || m.conflicted {
+		return false
+	}
+
+	revision, err := shared.Revision()
+	return err == nil && revision != m.revision
+}
+
+// reloadChanges reloads the stored app state after it was changed outside of this instance of lull, keeping any
+// unsaved changes.
+func (m *Manager) reloadChanges() {
+	m.mutex.Lock()
+	if m.conflicted {
+		m.mutex.Unlock()
+		return
+	}
+
+	err := m.reload(m.dirty)
+	m.mutex.Unlock()
+
+	if err != nil {
+		logger.Errorf("failed to reload app state: %s", err)
+	} else if m.onReload != nil {
+		m.onReload()
+	}
+}
+
+// reload replaces the app state with the stored app state. If merge is true, changes made by this instance since the
+// app state was loaded or last saved are merged into the stored app state.
+func (m *Manager) reload(merge bool) error {
+	revision := ""
+	if shared, ok := m.storage.(SharedStorage); ok {
+		r, err := shared.Revision()
+		if err != nil {
+			return err
+		}
+
+		revision = r
+	}
+
+	theirs, err := m.storage.Load()
+	if err != nil {
+		return errors.Wrap(err, "failed to load changes made elsewhere")
+	}
+
+	// secrets are stored separately, so carry over those known to this instance
+	if m.secrets != nil {
+		if stored, err := m.secrets.Load(); err == nil {
+			theirs.ApplySecrets(stored)
+		}
+	}
+
+	theirs.ApplySecrets(m.state.Secrets())
+
+	// the stored collection is the starting point for detecting changes made by this instance from now on
+	stored, err := json.Marshal(theirs.Collection)
+	if err != nil {
+		return err
+	}
+
+	if merge {
+		base := &CollectionItem{}
+		if err := json.Unmarshal(m.base, base); err != nil {
+			return errors.Wrap(err, "failed to read the app state as it was loaded")
+		}
+
+		theirs.updateCollectionTree(base, nil)
+		theirs.Collection = mergeCollections(base, m.state.Collection, theirs.Collection)
+	}
+
+	m.adopt(theirs)
+	m.revision = revision
+	m.base = stored
+
+	return nil
+}
+
+// save writes the app state, unless the stored app state was changed by another instance of lull since it was
+// loaded or last saved by this instance.
+func (m *Manager) save() error {
+	if shared, ok := m.storage.(SharedStorage); ok {
+		revision, err := shared.Revision()
+		if err != nil {
+			return err
+		} else if revision != m.revision {
+			return &ConflictError{}
+		}
+	}
+
+	return m.write()
+}
+
+// write writes the app state to storage, with secrets saved to the secret store if there is one.
+func (m *Manager) write() error {
+	if m.secrets == nil {
+		if err := m.storage.Save(m.state); err != nil {
+			return err
+		}
+
+		m.track()
+		return nil
+	}
+
+	// temporarily remove secrets from the app state so that they are not written in plaintext
+	secrets := m.state.Secrets()
+	m.state.clearSecrets()
+	err := m.storage.Save(m.state)
+	m.state.ApplySecrets(secrets)
+
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(m.savePath, data, 0644)
+	m.track()
+
+	// encrypting secrets is slow, so they are only saved when they have changed
+	if m.savedSecrets != nil && reflect.DeepEqual(m.savedSecrets, secrets) {
+		return nil
+	}
+
+	if err := m.secrets.Save(secrets); err != nil {
+		return errors.Wrap(err, "failed to save secrets")
+	}
+
+	m.savedSecrets = secrets
+	return nil
+}
+
+// track records the revision of the stored app state and the collection as it was stored, so that changes made by
+// other instances of lull can later be detected and merged.
+func (m *Manager) track() {
+	if shared, ok := m.storage.(SharedStorage); ok {
+		if revision, err := shared.Revision(); err == nil {
+			m.revision = revision
+		}
+	}
+
+	if data, err := json.Marshal(m.state.Collection); err == nil {
+		m.base = data
+	}
+}
+
+// adopt replaces the app state with another, keeping the layout, history, responses, undo steps and the active and
+// selected items.
+func (m *Manager) adopt(a *AppState) {
+	a.Layout = m.state.Layout
+	a.History = m.state.History
+
+	results := map[uuid.UUID]*HTTPResult{}
+	visitCollection(m.state.Collection, func(item *CollectionItem) {
+		results[item.UUID] = item.Result
+	})
+
+	visitCollection(a.Collection, func(item *CollectionItem) {
+		if item.Result == nil {
+			item.Result = results[item.UUID]
+		}
+	})
+
+	if m.state.ActiveItem != nil {
+		a.ActiveItem = a.collectionItemByUUID(m.state.ActiveItem.UUID, a.Collection)
+	}
+
+	if m.state.SelectedItem != nil {
+		a.SelectedItem = a.collectionItemByUUID(m.state.SelectedItem.UUID, a.Collection)
+	}
+
+	a.EnsureDefaultItems()
+
+	previous, err := json.Marshal(m.state.Collection)
+	m.state = a
+
+	if err != nil {
+		m.clearUndo()
+	} else {
+		m.rebaseUndo(previous)
+	}
+}
+
+// unlock releases the lock on the storage, if this instance holds it.
+func (m *Manager) unlock() error {
+	if m.lock == nil {
+		return nil
+	}
+
+	err := m.lock.Release()
+	m.lock = nil
+	return err
 }
@@ -0,0 +1,90 @@
+package state
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_MoveCollectionItem_IntoGroup(t *testing.T) {
+	a := NewAppState()
+	req := a.Collection.Children[0]
+	group := NewCollectionGroup("group", a.Collection)
+	a.Collection.AddChild(group)
+
+	err := a.MoveCollectionItem(req, group)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*CollectionItem{group}, a.Collection.Children)
+	assert.Equal(t, []*CollectionItem{req}, group.Children)
+	assert.Equal(t, group, req.Parent)
+}
+
+func Test_MoveCollectionItem_IntoDescendent(t *testing.T) {
+	a := NewAppState()
+	group := NewCollectionGroup("group", a.Collection)
+	sub := NewCollectionGroup("sub", group)
+	a.Collection.AddChild(group)
+	group.AddChild(sub)
+
+	err := a.MoveCollectionItem(group, sub)
+
+	assert.Error(t, err)
+	assert.Equal(t, a.Collection, group.Parent)
+	assert.Equal(t, []*CollectionItem{sub}, group.Children)
+}
+
+func Test_AppState_SecretVariables(t *testing.T) {
+	a := NewAppState()
+	item := a.Collection.Children[0]
+	item.Variables = map[string]string{"host": "localhost", "tokenUrl": "https://auth", "sig": "s3cret"}
+	item.SetSecretVariable("sig", true)
+
+	secrets := a.Secrets()
+	assert.Equal(t, map[string]string{secretKey(item, "variables/sig"): "s3cret"}, secrets)
+
+	a.clearSecrets()
+	assert.Equal(t, map[string]string{"host": "localhost", "tokenUrl": "https://auth", "sig": ""}, item.Variables)
+
+	a.ApplySecrets(secrets)
+	assert.Equal(t, map[string]string{"host": "localhost", "tokenUrl": "https://auth", "sig": "s3cret"}, item.Variables)
+
+	// variables stored as secrets before they could be marked stay secret
+	item.SetSecretVariable("sig", false)
+	a.ApplySecrets(secrets)
+	assert.Equal(t, []string{"sig"}, item.SecretVariables)
+}
+
+func Test_SuggestSecretVariable(t *testing.T) {
+	assert.True(t, SuggestSecretVariable("apiToken"))
+	assert.True(t, SuggestSecretVariable("DB_PASSWORD"))
+	assert.False(t, SuggestSecretVariable("host"))
+}
+
+func Test_MergeCollections_KeepsReorderedChildren(t *testing.T) {
+	a := NewAppState()
+	a.Collection.AddChild(NewCollectionRequest("Second", "GET", "", a.Collection))
+	base, err := a.snapshot()
+	assert.NoError(t, err)
+
+	load := func() *CollectionItem {
+		c := &CollectionItem{}
+		assert.NoError(t, json.Unmarshal(base.collection, c))
+		a.updateCollectionTree(c, nil)
+		return c
+	}
+
+	// this instance moves the second request up, while the other instance adds a request at the end
+	ours := load()
+	assert.True(t, ours.MoveChild(ours.Children[1], -1))
+
+	theirs := load()
+	theirs.AddChild(NewCollectionRequest("Third", "GET", "", theirs))
+
+	var names []string
+	for _, c := range mergeCollections(load(), ours, theirs).Children {
+		names = append(names, c.Name)
+	}
+
+	assert.Equal(t, []string{"Second", "Unnamed", "Third"}, names)
+}
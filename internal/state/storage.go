@@ -0,0 +1,175 @@
+package state
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/mbpolan/lull/internal/system"
+	"github.com/pkg/errors"
+	"os"
+	"time"
+)
+
+const (
+	// backupCount is the number of previous versions of the app state file that are kept.
+	backupCount = 3
+
+	// backupInterval is the minimum time between two backups of the app state file, so that frequent saves don't
+	// replace all backups with nearly identical copies.
+	backupInterval = 10 * time.Minute
+)
+
+// Storage reads and writes the app state to a persistent location.
+type Storage interface {
+	// Load returns the previously saved app state.
+	Load() (*AppState, error)
+
+	// Save persists the app state, replacing any that was previously saved.
+	Save(a *AppState) error
+}
+
+// SharedStorage is a Storage that other instances of lull may use at the same time.
+type SharedStorage interface {
+	Storage
+
+	// Revision returns a value that changes whenever the stored app state changes. An empty revision means that
+	// nothing has been stored yet.
+	Revision() (string, error)
+
+	// LockPath returns the path to the file used to signal that an instance of lull is using the storage.
+	LockPath() string
+}
+
+// FileStorage stores the entire app state in a single file, keeping a few rotated backups of previous versions.
+type FileStorage struct {
+	path string
+}
+
+// NewFileStorage returns a FileStorage that reads and writes the app state at path.
+func NewFileStorage(path string) *FileStorage {
+	return &FileStorage{
+		path: path,
+	}
+}
+
+// Path returns the path to the app state file.
+func (s *FileStorage) Path() string {
+	return s.path
+}
+
+// Revision returns a hash of the contents of the app state file.
+func (s *FileStorage) Revision() (string, error) {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(data)), nil
+}
+
+// LockPath returns the path to the lock file kept next to the app state file.
+func (s *FileStorage) LockPath() string {
+	return s.path + ".lock"
+}
+
+// Load reads the app state from the file. If the file uses an older schema version, a copy of it is kept before
+// it is migrated.
+func (s *FileStorage) Load() (*AppState, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	if version, err := SchemaVersionOf(data); err == nil && version < SchemaVersion {
+		backup := fmt.Sprintf("%s.v%d", s.path, version)
+		if _, err := os.Stat(backup); os.IsNotExist(err) {
+			if err := system.WriteFileAtomic(backup, data, 0600); err != nil {
+				return nil, errors.Wrap(err, "failed to back up app state before migrating")
+			}
+		}
+	}
+
+	return DeserializeAppState(data)
+}
+
+// Save atomically writes the app state to the file, readable only by the current user. A file written by a newer
+// version of lull is never overwritten.
+func (s *FileStorage) Save(a *AppState) error {
+	if current, err := os.ReadFile(s.path); err == nil {
+		if version, err := SchemaVersionOf(current); err == nil && version > SchemaVersion {
+			return &NewerVersionError{Version: version, Supported: SchemaVersion}
+		}
+	}
+
+	data, err := a.Serialize()
+	if err != nil {
+		return err
+	}
+
+	if err := s.backup(); err != nil {
+		return errors.Wrap(err, "failed to back up app state")
+	}
+
+	return system.WriteFileAtomic(s.path, data, 0600)
+}
+
+// Backups returns the paths to existing backups of the app state file, ordered from newest to oldest.
+func (s *FileStorage) Backups() []string {
+	var backups []string
+	for i := 1; i <= backupCount; i++ {
+		if _, err := os.Stat(s.backupPath(i)); err == nil {
+			backups = append(backups, s.backupPath(i))
+		}
+	}
+
+	return backups
+}
+
+// LoadBackup reads the app state from a backup returned by Backups.
+func (s *FileStorage) LoadBackup(path string) (*AppState, error) {
+	return s.load(path)
+}
+
+// SetAside renames the app state file so that it is kept for inspection but no longer loaded, returning its new
+// path. This is used when the file cannot be read.
+func (s *FileStorage) SetAside() (string, error) {
+	path := fmt.Sprintf("%s.broken-%s", s.path, time.Now().Format("20060102-150405"))
+	return path, os.Rename(s.path, path)
+}
+
+func (s *FileStorage) load(path string) (*AppState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return DeserializeAppState(data)
+}
+
+// backup copies the current app state file to the newest backup, rotating older backups out. Nothing is done if
+// the newest backup was made recently.
+func (s *FileStorage) backup() error {
+	current, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info, err := os.Stat(s.backupPath(1)); err == nil && time.Since(info.ModTime()) < backupInterval {
+		return nil
+	}
+
+	for i := backupCount - 1; i >= 1; i-- {
+		if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	return system.WriteFileAtomic(s.backupPath(1), current, 0600)
+}
+
+func (s *FileStorage) backupPath(n int) string {
+	return fmt.Sprintf("%s.bak.%d", s.path, n)
+}
@@ -0,0 +1,215 @@
+package state
+
+import (
+	"fmt"
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/stretchr/testify/assert"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func Test_FileStorage_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	s := NewFileStorage(path)
+
+	assert.NoError(t, s.Save(NewAppState()))
+
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	loaded, err := s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "Default", loaded.Collection.Name)
+	assert.Empty(t, s.Backups())
+}
+
+func Test_FileStorage_RotatesBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	s := NewFileStorage(path)
+
+	for i := 0; i < backupCount+2; i++ {
+		assert.NoError(t, s.Save(NewAppState()))
+
+		// age the newest backup so that the next save makes a new one
+		old := time.Now().Add(-2 * backupInterval)
+		_ = os.Chtimes(s.backupPath(1), old, old)
+	}
+
+	assert.Equal(t, []string{s.backupPath(1), s.backupPath(2), s.backupPath(3)}, s.Backups())
+
+	_, err := s.LoadBackup(s.Backups()[0])
+	assert.NoError(t, err)
+}
+
+func Test_FileStorage_LoadCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	assert.NoError(t, os.WriteFile(path, []byte(`{"Collection": {"Name": "trunc`), 0600))
+	s := NewFileStorage(path)
+
+	_, err := s.Load()
+	assert.Error(t, err)
+
+	moved, err := s.SetAside()
+	assert.NoError(t, err)
+	assert.FileExists(t, moved)
+	assert.NoFileExists(t, path)
+}
+
+func Test_Manager_Autosave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	m := NewStateManager(NewAppState(), NewFileStorage(path))
+
+	saved := make(chan bool, 1)
+	m.StartAutosave(10*time.Millisecond, func(save func()) {
+		save()
+		saved <- true
+	})
+	defer m.Shutdown()
+
+	m.SetDirty()
+
+	select {
+	case <-saved:
+		assert.FileExists(t, path)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "state was not autosaved")
+	}
+}
+
+func Test_Manager_SaveConflict(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	s := NewFileStorage(path)
+	assert.NoError(t, s.Save(NewAppState()))
+
+	first, err := s.Load()
+	assert.NoError(t, err)
+	second, err := s.Load()
+	assert.NoError(t, err)
+
+	ours := NewStateManager(first, NewFileStorage(path))
+	theirs := NewStateManager(second, NewFileStorage(path))
+
+	// each instance renames the same request and adds a request of its own
+	first.Collection.Children[0].Name = "Ours"
+	first.Collection.AddChild(NewCollectionRequest("Mine", "GET", "", first.Collection))
+	ours.SetDirty()
+
+	second.Collection.Children[0].Name = "Theirs"
+	second.Collection.AddChild(NewCollectionRequest("Other", "POST", "", second.Collection))
+	theirs.SetDirty()
+
+	assert.NoError(t, theirs.Save())
+
+	err = ours.Save()
+	assert.IsType(t, &ConflictError{}, err)
+	assert.NoError(t, ours.Resolve(ConflictMerge))
+
+	loaded, err := s.Load()
+	assert.NoError(t, err)
+
+	var names []string
+	for _, c := range loaded.Collection.Children {
+		names = append(names, c.Name)
+	}
+
+	assert.Equal(t, []string{"Ours", "Mine", "Other"}, names)
+}
+
+// countingSecretStore is a SecretStore that keeps secrets in memory and counts how often they are saved.
+type countingSecretStore struct {
+	secrets map[string]string
+	saves   int
+}
+
+func (s *countingSecretStore) Load() (map[string]string, error) {
+	return s.secrets, nil
+}
+
+func (s *countingSecretStore) Save(secrets map[string]string) error {
+	s.secrets = secrets
+	s.saves++
+	return nil
+}
+
+func Test_Manager_SavesChangedSecrets(t *testing.T) {
+	a := NewAppState()
+	item := a.Collection.Children[0]
+	item.Authentication = ItemAuthentication{Data: auth.NewBearerAuthentication("token")}
+
+	store := &countingSecretStore{}
+	m := NewStateManager(a, NewFileStorage(filepath.Join(t.TempDir(), "state")))
+	m.SetSecretStore(store)
+
+	for i := 0; i < 2; i++ {
+		item.URL = fmt.Sprintf("http://localhost/%d", i)
+		m.SetDirty()
+		assert.NoError(t, m.Save())
+	}
+
+	assert.Equal(t, 1, store.saves)
+
+	item.Authentication = ItemAuthentication{Data: auth.NewBearerAuthentication("other")}
+	m.SetDirty()
+	assert.NoError(t, m.Save())
+	assert.Equal(t, 2, store.saves)
+}
+
+func Test_Lock_Acquire(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.lock")
+	lock := NewLock(path)
+	assert.NoError(t, lock.Acquire())
+	assert.NoError(t, lock.Release())
+	assert.NoFileExists(t, path)
+
+	// a lock held by a process that is still running cannot be taken
+	host, _ := os.Hostname()
+	assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("%d\n%s\n", os.Getppid(), host)), 0600))
+	assert.IsType(t, &LockedError{}, NewLock(path).Acquire())
+
+	// a lock left behind by a process that has exited is taken over
+	assert.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf("%d\n%s\n", math.MaxInt32, host)), 0600))
+	assert.NoError(t, NewLock(path).Acquire())
+}
+
+func Test_Manager_Watch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	s := NewFileStorage(path)
+	assert.NoError(t, s.Save(NewAppState()))
+
+	loaded, err := s.Load()
+	assert.NoError(t, err)
+
+	m := NewStateManager(loaded, NewFileStorage(path))
+	reloaded := make(chan bool, 1)
+	m.SetReloadHandler(func() {
+		reloaded <- true
+	})
+
+	m.StartWatching(10*time.Millisecond, func(reload func()) {
+		reload()
+	})
+	defer m.Shutdown()
+
+	// make an unsaved change, then change the file as another program would
+	loaded.Collection.Children[0].URL = "http://localhost"
+	m.SetDirty()
+
+	external, err := s.Load()
+	assert.NoError(t, err)
+	external.Collection.AddChild(NewCollectionRequest("Pulled", "GET", "", external.Collection))
+	assert.NoError(t, s.Save(external))
+
+	select {
+	case <-reloaded:
+		c := m.Get().Collection
+		assert.Len(t, c.Children, 2)
+		assert.Equal(t, "http://localhost", c.Children[0].URL)
+		assert.Equal(t, c.Children[0], m.Get().ActiveItem)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "state was not reloaded")
+	}
+}
@@ -0,0 +1,202 @@
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/google/uuid"
+	"time"
+)
+
+const (
+	// maxUndoSteps is the number of changes that can be undone.
+	maxUndoSteps = 100
+
+	// undoCoalesceWindow is the time within which consecutive changes of the same kind are undone as a single step.
+	undoCoalesceWindow = 2 * time.Second
+)
+
+// snapshot captures the collection and the active and selected items at a point in time.
+type snapshot struct {
+	key         string
+	description string
+	at          time.Time
+	collection  []byte
+	active      uuid.UUID
+	selected    uuid.UUID
+}
+
+// Checkpoint records the current state of the collection before a change is made, so that the change can later be
+// undone. The description is shown to the user when the change is undone or redone. Consecutive changes with the
+// same non-empty key made in quick succession, such as typing into a field, are grouped into a single step.
+func (m *Manager) Checkpoint(key string, description string) {
+	now := time.Now()
+	if n := len(m.undo); n > 0 {
+		last := m.undo[n-1]
+		if key != "" && last.key == key && now.Sub(last.at) < undoCoalesceWindow {
+			last.at = now
+			return
+		}
+	}
+
+	s, err := m.state.snapshot()
+	if err != nil {
+		return
+	}
+
+	// discard the previous step if it turned out not to change anything
+	if n := len(m.undo); n > 0 && bytes.Equal(m.undo[n-1].collection, s.collection) {
+		m.undo = m.undo[:n-1]
+	}
+
+	s.key = key
+	s.description = description
+	s.at = now
+
+	m.undo = append(m.undo, s)
+	if len(m.undo) > maxUndoSteps {
+		m.undo = m.undo[len(m.undo)-maxUndoSteps:]
+	}
+
+	m.redo = nil
+}
+
+// Undo reverts the most recent change, returning its description. If there is nothing to undo, false is returned.
+func (m *Manager) Undo() (string, bool) {
+	return m.step(&m.undo, &m.redo)
+}
+
+// Redo reapplies the most recently undone change, returning its description. If there is nothing to redo, false is
+// returned.
+func (m *Manager) Redo() (string, bool) {
+	return m.step(&m.redo, &m.undo)
+}
+
+// step restores the most recent snapshot from one stack, recording the current state on the other stack so that the
+// step can be reversed.
+func (m *Manager) step(from *[]*snapshot, to *[]*snapshot) (string, bool) {
+	current, err := m.state.snapshot()
+	if err != nil {
+		return "", false
+	}
+
+	for len(*from) > 0 {
+		n := len(*from)
+		s := (*from)[n-1]
+		*from = (*from)[:n-1]
+
+		// skip steps that did not change anything
+		if bytes.Equal(s.collection, current.collection) {
+			continue
+		}
+
+		if err := m.state.restore(s); err != nil {
+			return "", false
+		}
+
+		current.description = s.description
+		*to = append(*to, current)
+		m.SetDirty()
+
+		return s.description, true
+	}
+
+	return "", false
+}
+
+// clearUndo discards all undo and redo steps.
+func (m *Manager) clearUndo() {
+	m.undo = nil
+	m.redo = nil
+}
+
+// rebaseUndo updates the undo and redo steps after the collection was replaced with one that includes changes made
+// elsewhere, so that undoing a step only reverts that step instead of also discarding those changes. The previous
+// collection is the one the steps were recorded against.
+func (m *Manager) rebaseUndo(previous []byte) {
+	current, err := json.Marshal(m.state.Collection)
+	if err != nil {
+		m.clearUndo()
+		return
+	}
+
+	m.undo = m.state.rebaseSnapshots(m.undo, previous, current)
+	m.redo = m.state.rebaseSnapshots(m.redo, previous, current)
+}
+
+// rebaseSnapshots applies the differences between each snapshot and the previous collection to the current collection,
+// matching items by their UUIDs. Snapshots that cannot be read are discarded.
+func (a *AppState) rebaseSnapshots(steps []*snapshot, previous []byte, current []byte) []*snapshot {
+	var rebased []*snapshot
+
+	for _, s := range steps {
+		base, ours, theirs := &CollectionItem{}, &CollectionItem{}, &CollectionItem{}
+		if json.Unmarshal(previous, base) != nil || json.Unmarshal(s.collection, ours) != nil ||
+			json.Unmarshal(current, theirs) != nil {
+			continue
+		}
+
+		a.updateCollectionTree(base, nil)
+		a.updateCollectionTree(ours, nil)
+		a.updateCollectionTree(theirs, nil)
+
+		data, err := json.Marshal(mergeCollections(base, ours, theirs))
+		if err != nil {
+			continue
+		}
+
+		s.collection = data
+		rebased = append(rebased, s)
+	}
+
+	return rebased
+}
+
+// snapshot returns a snapshot of the current collection and the active and selected items.
+func (a *AppState) snapshot() (*snapshot, error) {
+	data, err := json.Marshal(a.Collection)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &snapshot{
+		collection: data,
+	}
+
+	if a.ActiveItem != nil {
+		s.active = a.ActiveItem.UUID
+	}
+	if a.SelectedItem != nil {
+		s.selected = a.SelectedItem.UUID
+	}
+
+	return s, nil
+}
+
+// restore replaces the collection and the active and selected items with those in a snapshot. Responses received
+// for items are not part of snapshots, so they are carried over from the current collection.
+func (a *AppState) restore(s *snapshot) error {
+	collection := &CollectionItem{}
+	if err := json.Unmarshal(s.collection, collection); err != nil {
+		return err
+	}
+
+	a.updateCollectionTree(collection, nil)
+
+	results := map[uuid.UUID]*HTTPResult{}
+	a.walkCollection(a.Collection, func(item *CollectionItem) bool {
+		results[item.UUID] = item.Result
+		return false
+	})
+
+	a.walkCollection(collection, func(item *CollectionItem) bool {
+		item.Result = results[item.UUID]
+		return false
+	})
+
+	a.Collection = collection
+	a.ActiveItem = a.collectionItemByUUID(s.active, collection)
+	a.SelectedItem = a.collectionItemByUUID(s.selected, collection)
+	a.EnsureDefaultItems()
+
+	return nil
+}
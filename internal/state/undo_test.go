@@ -0,0 +1,77 @@
+package state
+
+import (
+	"github.com/stretchr/testify/assert"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Manager_UndoRedo(t *testing.T) {
+	m := NewStateManager(NewAppState(), nil)
+	item := m.Get().Collection.Children[0]
+
+	m.Checkpoint("", "rename item")
+	item.Name = "Renamed"
+
+	description, ok := m.Undo()
+	assert.True(t, ok)
+	assert.Equal(t, "rename item", description)
+	assert.Equal(t, "Unnamed", m.Get().Collection.Children[0].Name)
+	assert.Equal(t, item.UUID, m.Get().ActiveItem.UUID)
+
+	_, ok = m.Undo()
+	assert.False(t, ok)
+
+	description, ok = m.Redo()
+	assert.True(t, ok)
+	assert.Equal(t, "rename item", description)
+	assert.Equal(t, "Renamed", m.Get().Collection.Children[0].Name)
+}
+
+func Test_Manager_CheckpointCoalesces(t *testing.T) {
+	m := NewStateManager(NewAppState(), nil)
+	item := m.Get().Collection.Children[0]
+
+	m.Checkpoint("url", "edit URL")
+	item.URL = "h"
+	m.Checkpoint("url", "edit URL")
+	item.URL = "http"
+
+	_, ok := m.Undo()
+	assert.True(t, ok)
+	assert.Equal(t, "", m.Get().Collection.Children[0].URL)
+
+	_, ok = m.Undo()
+	assert.False(t, ok)
+}
+
+func Test_Manager_UndoAfterReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state")
+	s := NewFileStorage(path)
+	assert.NoError(t, s.Save(NewAppState()))
+
+	loaded, err := s.Load()
+	assert.NoError(t, err)
+
+	m := NewStateManager(loaded, NewFileStorage(path))
+	m.Checkpoint("", "rename item")
+	loaded.Collection.Children[0].Name = "Renamed"
+	m.SetDirty()
+	assert.NoError(t, m.Save())
+
+	// another program adds a request, which is reloaded
+	external, err := s.Load()
+	assert.NoError(t, err)
+	external.Collection.AddChild(NewCollectionRequest("Pulled", "GET", "", external.Collection))
+	assert.NoError(t, s.Save(external))
+	m.reloadChanges()
+
+	description, ok := m.Undo()
+	assert.True(t, ok)
+	assert.Equal(t, "rename item", description)
+
+	c := m.Get().Collection
+	assert.Len(t, c.Children, 2)
+	assert.Equal(t, "Unnamed", c.Children[0].Name)
+	assert.Equal(t, "Pulled", c.Children[1].Name)
+}
@@ -1,8 +1,12 @@
 package system
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
 )
 
 // Setup prepares necessary system-level constructs before the app can run.
@@ -41,4 +45,72 @@ func CreateDir(path string) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// ExpandPath replaces a leading "~" in the path with the user's home directory.
+func ExpandPath(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
+
+// WriteFileAtomic writes data to a file by first writing it to a temporary file in the same directory, and then
+// renaming it over the original. Readers will either see the previous or the new contents, but never a partially
+// written file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), fmt.Sprintf(".%s-*.tmp", filepath.Base(path)))
+	if err != nil {
+		return err
+	}
+
+	// clean up the temporary file if anything goes wrong
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err = tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), path)
+	return err
+}
+
+// ProcessRunning returns true if a process with the given id is running on this machine.
+func ProcessRunning(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	// finding a process only succeeds on windows if it is running, while other platforms need to probe it
+	if runtime.GOOS == "windows" {
+		return true
+	}
+
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
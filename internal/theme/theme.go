@@ -0,0 +1,400 @@
+package theme
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Default is the name of the theme used unless the user chooses another.
+const Default = "dark"
+
+var instance *Theme
+
+// Theme holds the colors used throughout the user interface. Colors are given as names, such as "yellow", or as hex
+// values, such as "#ffaa00". The color "default" is the terminal's own foreground or background color.
+type Theme struct {
+	// Extends is the name of the theme that provides the colors not given by a user-defined theme.
+	Extends string `yaml:"extends,omitempty"`
+
+	// Background is the background of all panels.
+	Background string `yaml:"background,omitempty"`
+
+	// Contrast is the background of input fields, drop downs and buttons.
+	Contrast string `yaml:"contrast,omitempty"`
+
+	// MoreContrast is the background of focused buttons and selected options.
+	MoreContrast string `yaml:"more_contrast,omitempty"`
+
+	Border string `yaml:"border,omitempty"`
+	Title  string `yaml:"title,omitempty"`
+
+	// Text is the color of most text.
+	Text string `yaml:"text,omitempty"`
+
+	// SecondaryText is the color of labels and groups in the collection.
+	SecondaryText string `yaml:"secondary_text,omitempty"`
+
+	// TertiaryText is the color of table headings and the active request in the collection.
+	TertiaryText string `yaml:"tertiary_text,omitempty"`
+
+	// InverseText is the color of text on contrasting backgrounds.
+	InverseText string `yaml:"inverse_text,omitempty"`
+
+	// Highlight is the color of names and values called out in messages.
+	Highlight string `yaml:"highlight,omitempty"`
+
+	// Muted is the color of supplementary text, such as the path to an item.
+	Muted string `yaml:"muted,omitempty"`
+
+	// Error is the color of error messages.
+	Error string `yaml:"error,omitempty"`
+
+	StatusBar StatusBarColors `yaml:"status_bar,omitempty"`
+	Status    StatusColors    `yaml:"status,omitempty"`
+	Syntax    SyntaxColors    `yaml:"syntax,omitempty"`
+
+	// Methods are the colors of HTTP methods in the collection, keyed by method. Methods that are not listed use the
+	// text color.
+	Methods map[string]string `yaml:"methods,omitempty"`
+}
+
+// StatusBarColors are the colors of the status bar.
+type StatusBarColors struct {
+	Text       string `yaml:"text,omitempty"`
+	Background string `yaml:"background,omitempty"`
+}
+
+// StatusColors are the colors of response status codes.
+type StatusColors struct {
+	Success     string `yaml:"success,omitempty"`
+	Redirect    string `yaml:"redirect,omitempty"`
+	ClientError string `yaml:"client_error,omitempty"`
+	ServerError string `yaml:"server_error,omitempty"`
+}
+
+// SyntaxColors are the colors used to highlight request and response bodies.
+type SyntaxColors struct {
+	Key         string `yaml:"key,omitempty"`
+	String      string `yaml:"string,omitempty"`
+	Number      string `yaml:"number,omitempty"`
+	Boolean     string `yaml:"boolean,omitempty"`
+	Null        string `yaml:"null,omitempty"`
+	Punctuation string `yaml:"punctuation,omitempty"`
+	Tag         string `yaml:"tag,omitempty"`
+	Attribute   string `yaml:"attribute,omitempty"`
+}
+
+var builtins = map[string]Theme{
+	"dark": {
+		Background:    "black",
+		Contrast:      "blue",
+		MoreContrast:  "green",
+		Border:        "white",
+		Title:         "white",
+		Text:          "white",
+		SecondaryText: "yellow",
+		TertiaryText:  "green",
+		InverseText:   "blue",
+		Highlight:     "yellow",
+		Muted:         "gray",
+		Error:         "red",
+		StatusBar:     StatusBarColors{Text: "white", Background: "blue"},
+		Status:        StatusColors{Success: "green", Redirect: "yellow", ClientError: "orange", ServerError: "red"},
+		Syntax: SyntaxColors{Key: "aqua", String: "green", Number: "fuchsia", Boolean: "yellow", Null: "gray",
+			Punctuation: "white", Tag: "aqua", Attribute: "yellow"},
+		Methods: map[string]string{"GET": "green", "POST": "yellow", "PUT": "aqua", "PATCH": "fuchsia",
+			"DELETE": "red"},
+	},
+	"light": {
+		Background:    "white",
+		Contrast:      "lightgray",
+		MoreContrast:  "lightsteelblue",
+		Border:        "black",
+		Title:         "black",
+		Text:          "black",
+		SecondaryText: "navy",
+		TertiaryText:  "darkgreen",
+		InverseText:   "white",
+		Highlight:     "darkmagenta",
+		Muted:         "dimgray",
+		Error:         "firebrick",
+		StatusBar:     StatusBarColors{Text: "white", Background: "navy"},
+		Status: StatusColors{Success: "darkgreen", Redirect: "darkgoldenrod", ClientError: "chocolate",
+			ServerError: "firebrick"},
+		Syntax: SyntaxColors{Key: "navy", String: "darkgreen", Number: "purple", Boolean: "darkgoldenrod",
+			Null: "dimgray", Punctuation: "black", Tag: "navy", Attribute: "darkgoldenrod"},
+		Methods: map[string]string{"GET": "darkgreen", "POST": "darkgoldenrod", "PUT": "navy", "PATCH": "purple",
+			"DELETE": "firebrick"},
+	},
+	"high-contrast": {
+		Background:    "black",
+		Contrast:      "white",
+		MoreContrast:  "yellow",
+		Border:        "white",
+		Title:         "yellow",
+		Text:          "white",
+		SecondaryText: "yellow",
+		TertiaryText:  "aqua",
+		InverseText:   "black",
+		Highlight:     "yellow",
+		Muted:         "silver",
+		Error:         "red",
+		StatusBar:     StatusBarColors{Text: "black", Background: "yellow"},
+		Status:        StatusColors{Success: "lime", Redirect: "yellow", ClientError: "fuchsia", ServerError: "red"},
+		Syntax: SyntaxColors{Key: "aqua", String: "lime", Number: "fuchsia", Boolean: "yellow", Null: "silver",
+			Punctuation: "white", Tag: "aqua", Attribute: "yellow"},
+		Methods: map[string]string{"GET": "lime", "POST": "yellow", "PUT": "aqua", "PATCH": "fuchsia",
+			"DELETE": "red"},
+	},
+}
+
+// Setup chooses the theme with the given name, either one of the built-in themes or one of the custom themes, and
+// applies it to all primitives created from now on.
+func Setup(name string, custom map[string]Theme) error {
+	t, err := Resolve(name, custom)
+	if err != nil {
+		return err
+	}
+
+	instance = t
+	t.apply()
+	return nil
+}
+
+// Get returns the theme chosen by Setup, or the default theme if Setup was not called.
+func Get() *Theme {
+	if instance == nil {
+		instance, _ = Resolve(Default, nil)
+	}
+
+	return instance
+}
+
+// Names returns the names of the built-in themes and the custom themes, sorted by name.
+func Names(custom map[string]Theme) []string {
+	var names []string
+	for name := range builtins {
+		names = append(names, name)
+	}
+
+	for name := range custom {
+		if _, ok := builtins[name]; !ok {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// Resolve returns the theme with the given name, with colors not given by a custom theme taken from the theme it
+// extends.
+func Resolve(name string, custom map[string]Theme) (*Theme, error) {
+	t, err := resolve(name, custom, map[string]bool{})
+	if err != nil {
+		return nil, err
+	}
+
+	if problems := t.validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("theme %s: %s", name, strings.Join(problems, "; "))
+	}
+
+	return &t, nil
+}
+
+// Validate returns a description of each problem with the chosen theme and the custom themes, such as unknown colors
+// or themes extending themes that do not exist.
+func Validate(name string, custom map[string]Theme) []string {
+	var problems []string
+	if _, ok := builtins[name]; !ok {
+		if _, ok := custom[name]; !ok {
+			problems = append(problems, fmt.Sprintf("theme: %q is not one of %s", name, strings.Join(Names(custom), ", ")))
+		}
+	}
+
+	names := make([]string, 0, len(custom))
+	for n := range custom {
+		names = append(names, n)
+	}
+
+	sort.Strings(names)
+	for _, n := range names {
+		t, err := resolve(n, custom, map[string]bool{})
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("themes.%s: %s", n, err))
+			continue
+		}
+
+		for _, p := range t.validate() {
+			problems = append(problems, fmt.Sprintf("themes.%s.%s", n, p))
+		}
+	}
+
+	return problems
+}
+
+func resolve(name string, custom map[string]Theme, seen map[string]bool) (Theme, error) {
+	t, ok := custom[name]
+	if !ok {
+		if b, ok := builtins[name]; ok {
+			return b, nil
+		}
+
+		return Theme{}, fmt.Errorf("%q is not a known theme", name)
+	}
+
+	if seen[name] {
+		return Theme{}, fmt.Errorf("%s extends itself, directly or through other themes", name)
+	}
+
+	seen[name] = true
+
+	base := t.Extends
+	if base == "" {
+		base = Default
+	}
+
+	// a custom theme may be named after the built-in theme that it changes
+	parent, ok := builtins[base]
+	if base != name || !ok {
+		var err error
+		if parent, err = resolve(base, custom, seen); err != nil {
+			return Theme{}, err
+		}
+	}
+
+	inherit(reflect.ValueOf(&t).Elem(), reflect.ValueOf(parent))
+
+	methods := map[string]string{}
+	for m, c := range parent.Methods {
+		methods[m] = c
+	}
+	for m, c := range t.Methods {
+		methods[strings.ToUpper(m)] = c
+	}
+
+	t.Methods = methods
+	t.Extends = ""
+	return t, nil
+}
+
+// inherit sets each empty color in a theme, or in a group of colors, to the matching color of another.
+func inherit(v reflect.Value, from reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch f.Kind() {
+		case reflect.String:
+			if f.String() == "" {
+				f.Set(from.Field(i))
+			}
+		case reflect.Struct:
+			inherit(f, from.Field(i))
+		}
+	}
+}
+
+// validate returns a description of each color that is not known.
+func (t Theme) validate() []string {
+	var problems []string
+	check := func(field string, color string) {
+		if !valid(color) {
+			problems = append(problems, fmt.Sprintf("%s: %q is not a color name or hex value such as #ffaa00", field, color))
+		}
+	}
+
+	var visit func(v reflect.Value, prefix string)
+	visit = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			name := prefix + strings.Split(v.Type().Field(i).Tag.Get("yaml"), ",")[0]
+			switch f := v.Field(i); f.Kind() {
+			case reflect.String:
+				if name != "extends" {
+					check(name, f.String())
+				}
+			case reflect.Struct:
+				visit(f, name+".")
+			}
+		}
+	}
+
+	visit(reflect.ValueOf(t), "")
+
+	methods := make([]string, 0, len(t.Methods))
+	for m := range t.Methods {
+		methods = append(methods, m)
+	}
+
+	sort.Strings(methods)
+	for _, m := range methods {
+		check("methods."+m, t.Methods[m])
+	}
+
+	return problems
+}
+
+// valid returns true if the color is a known name or a hex value.
+func valid(color string) bool {
+	return color == "default" || tcell.GetColor(color) != tcell.ColorDefault
+}
+
+// apply sets the colors that primitives use by default.
+func (t *Theme) apply() {
+	tview.Styles = tview.Theme{
+		PrimitiveBackgroundColor:    Color(t.Background),
+		ContrastBackgroundColor:     Color(t.Contrast),
+		MoreContrastBackgroundColor: Color(t.MoreContrast),
+		BorderColor:                 Color(t.Border),
+		TitleColor:                  Color(t.Title),
+		GraphicsColor:               Color(t.Border),
+		PrimaryTextColor:            Color(t.Text),
+		SecondaryTextColor:          Color(t.SecondaryText),
+		TertiaryTextColor:           Color(t.TertiaryText),
+		InverseTextColor:            Color(t.InverseText),
+		ContrastSecondaryTextColor:  Color(t.InverseText),
+	}
+}
+
+// Method returns the color of an HTTP method.
+func (t *Theme) Method(method string) string {
+	if c, ok := t.Methods[strings.ToUpper(method)]; ok {
+		return c
+	}
+
+	return t.Text
+}
+
+// StatusCode returns the color of a response status code.
+func (t *Theme) StatusCode(code int) string {
+	switch {
+	case code >= 200 && code < 300:
+		return t.Status.Success
+	case code >= 300 && code < 400:
+		return t.Status.Redirect
+	case code >= 400 && code < 500:
+		return t.Status.ClientError
+	case code >= 500 && code < 600:
+		return t.Status.ServerError
+	default:
+		return t.Text
+	}
+}
+
+// Color returns a color given by name or hex value.
+func Color(color string) tcell.Color {
+	return tcell.GetColor(color)
+}
+
+// Tag returns a tag that changes the color of the text that follows it, for use in primitives with dynamic colors.
+// The tag "[-]" restores the previous color.
+func Tag(color string) string {
+	return fmt.Sprintf("[%s]", color)
+}
+
+// Colorize returns text in the given color, for use in primitives with dynamic colors.
+func Colorize(color string, text string) string {
+	return Tag(color) + text + "[-]"
+}
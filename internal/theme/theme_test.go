@@ -0,0 +1,48 @@
+package theme
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Resolve_Builtins(t *testing.T) {
+	for _, name := range Names(nil) {
+		_, err := Resolve(name, nil)
+		assert.NoError(t, err, name)
+	}
+}
+
+func Test_Resolve_Custom(t *testing.T) {
+	custom := map[string]Theme{
+		"solar": {Extends: "light", Background: "#fdf6e3", Methods: map[string]string{"get": "teal"}},
+		"dark":  {Border: "gray"},
+	}
+
+	solar, err := Resolve("solar", custom)
+	assert.NoError(t, err)
+	assert.Equal(t, "#fdf6e3", solar.Background)
+	assert.Equal(t, "black", solar.Text)
+	assert.Equal(t, "teal", solar.Method("GET"))
+	assert.Equal(t, "firebrick", solar.Method("DELETE"))
+	assert.Equal(t, "black", solar.Method("OPTIONS"))
+
+	dark, err := Resolve("dark", custom)
+	assert.NoError(t, err)
+	assert.Equal(t, "gray", dark.Border)
+	assert.Equal(t, "black", dark.Background)
+}
+
+func Test_Validate(t *testing.T) {
+	custom := map[string]Theme{
+		"a":   {Extends: "b"},
+		"b":   {Extends: "a"},
+		"bad": {Status: StatusColors{Success: "greenish"}},
+	}
+
+	assert.Equal(t, []string{
+		`theme: "nope" is not one of a, b, bad, dark, high-contrast, light`,
+		"themes.a: a extends itself, directly or through other themes",
+		"themes.b: b extends itself, directly or through other themes",
+		`themes.bad.status.success: "greenish" is not a color name or hex value such as #ffaa00`,
+	}, Validate("nope", custom))
+}
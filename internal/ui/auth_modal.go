@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"github.com/mbpolan/lull/internal/state"
 	"github.com/mbpolan/lull/internal/state/auth"
 	"github.com/rivo/tview"
 )
@@ -25,6 +26,11 @@ func NewAuthModal(accept AuthModalAcceptHandler, reject ModalRejectHandler) *Aut
 	return m
 }
 
+// Set applies the authentication parameters of the CollectionItem to the modal.
+func (m *AuthModal) Set(item *state.CollectionItem) {
+	m.auth.Set(item)
+}
+
 // Widget returns a primitive widget containing this component.
 func (m *AuthModal) Widget() tview.Primitive {
 	return m.BaseInputModal.Widget()
@@ -48,7 +54,7 @@ func (m *AuthModal) build() {
 	m.grid.SetRows(-1, m.ButtonHeight())
 
 	// set up focus manager based on current scheme view's primitives
-	focusPrimitives := append([]tview.Primitive{m.auth.Widget()})
+	focusPrimitives := []tview.Primitive{m.auth.Widget()}
 	focusPrimitives = append(focusPrimitives, m.ok, m.cancel)
 	m.setupFocus(focusPrimitives)
 }
@@ -58,8 +64,6 @@ func (m *AuthModal) handleAuthTypeChange(text string, index int) {
 }
 
 func (m *AuthModal) handleAccept() {
-	auth := m.auth.Data()
-	if auth != nil {
-		m.onAccept(auth)
-	}
+	// a nil value indicates that authentication has been removed
+	m.onAccept(m.auth.Data())
 }
@@ -1,7 +1,10 @@
 package ui
 
 import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
 	"github.com/mbpolan/lull/internal/events"
+	"github.com/mbpolan/lull/internal/keys"
 	"github.com/mbpolan/lull/internal/state"
 	"github.com/mbpolan/lull/internal/state/auth"
 	"github.com/mbpolan/lull/internal/util"
@@ -10,10 +13,15 @@ import (
 
 type AuthViewChangeHandler func(data auth.RequestAuthentication)
 
+// secretMask is the character used to hide secret values that have not been revealed.
+const secretMask = '*'
+
 const (
 	authTypeNone         = "None"
 	authTypeBasic        = "Basic"
 	authTypeOAuth2Option = "OAuth2"
+	authTypeBearer       = "Bearer"
+	authTypeInherit      = "Inherit"
 )
 
 // AuthView shows various authentication schemes that can be configured for requests.
@@ -23,6 +31,10 @@ type AuthView struct {
 	authType     *tview.DropDown
 	basic        *BasicAuthView
 	oauth2       *OAuth2View
+	bearer       *BearerAuthView
+	inherited    *tview.TextView
+	reveal       bool
+	item         *state.CollectionItem
 	focusManager *util.FocusManager
 	handler      AuthViewChangeHandler
 }
@@ -48,12 +60,14 @@ func (a *AuthView) FocusPrimitives() []tview.Primitive {
 
 	// notify the handler func that parameters have changed
 	switch option {
-	case authTypeNone:
+	case authTypeNone, authTypeInherit:
 		return []tview.Primitive{a.authType}
 	case authTypeBasic:
 		return append([]tview.Primitive{a.authType}, a.basic.FocusPrimitives()...)
 	case authTypeOAuth2Option:
 		return append([]tview.Primitive{a.authType}, a.oauth2.FocusPrimitives()...)
+	case authTypeBearer:
+		return append([]tview.Primitive{a.authType}, a.bearer.FocusPrimitives()...)
 	}
 
 	return []tview.Primitive{}
@@ -68,6 +82,10 @@ func (a *AuthView) Data() auth.RequestAuthentication {
 		return a.basic.Data()
 	case authTypeOAuth2Option:
 		return a.oauth2.Data()
+	case authTypeBearer:
+		return a.bearer.Data()
+	case authTypeInherit:
+		return auth.NewInheritedAuthentication()
 	default:
 		return nil
 	}
@@ -79,6 +97,13 @@ func (a *AuthView) Set(item *state.CollectionItem) {
 	a.authType.SetSelectedFunc(nil)
 	defer a.authType.SetSelectedFunc(a.handleAuthTypeChange)
 
+	// mask secrets again when switching to another item
+	if a.item != item {
+		a.SetRevealSecrets(false)
+	}
+
+	a.item = item
+
 	if item.Authentication.None() {
 		a.pages.SwitchToPage(authTypeNone)
 		a.authType.SetCurrentOption(0)
@@ -90,7 +115,39 @@ func (a *AuthView) Set(item *state.CollectionItem) {
 		a.pages.SwitchToPage(authTypeOAuth2Option)
 		a.authType.SetCurrentOption(2)
 		a.oauth2.Set(oauth2)
+	} else if bearer, ok := item.Authentication.Data.(*auth.BearerAuthentication); ok && bearer != nil {
+		a.pages.SwitchToPage(authTypeBearer)
+		a.authType.SetCurrentOption(3)
+		a.bearer.Set(bearer)
+	} else if item.Authentication.Inherited() {
+		a.pages.SwitchToPage(authTypeInherit)
+		a.authType.SetCurrentOption(4)
+		a.setInherited(item)
 	}
+
+	a.focusManager.SetPrimitives(a.FocusPrimitives()...)
+}
+
+// setInherited shows a read-only summary of the authentication the item inherits from its ancestors.
+func (a *AuthView) setInherited(item *state.CollectionItem) {
+	effective, source := item.EffectiveAuthentication()
+	if source == nil || effective.None() {
+		a.inherited.SetText("No authentication is inherited from parent groups.")
+		return
+	}
+
+	text := fmt.Sprintf("Inherited from %s\n\n", highlight(tview.Escape(source.Name)))
+	if basic, ok := effective.Data.(*auth.BasicAuthentication); ok {
+		text = fmt.Sprintf("%sType: %s\nUsername: %s", text, authTypeBasic, tview.Escape(basic.Username))
+	} else if oauth2, ok := effective.Data.(*auth.OAuth2RequestAuthentication); ok {
+		text = fmt.Sprintf("%sType: %s\nToken URL: %s\nClient ID: %s\nGrant Type: %s\nScope: %s", text,
+			authTypeOAuth2Option, tview.Escape(oauth2.TokenURL), tview.Escape(oauth2.ClientID),
+			tview.Escape(oauth2.GrantType), tview.Escape(oauth2.Scope))
+	} else if _, ok := effective.Data.(*auth.BearerAuthentication); ok {
+		text = fmt.Sprintf("%sType: %s", text, authTypeBearer)
+	}
+
+	a.inherited.SetText(text)
 }
 
 func (a *AuthView) build() {
@@ -99,21 +156,28 @@ func (a *AuthView) build() {
 
 	// set up authentication scheme options
 	a.authType = tview.NewDropDown()
-	a.authType.SetOptions([]string{authTypeNone, authTypeBasic, authTypeOAuth2Option}, a.handleAuthTypeChange)
+	a.authType.SetOptions([]string{authTypeNone, authTypeBasic, authTypeOAuth2Option, authTypeBearer, authTypeInherit}, a.handleAuthTypeChange)
 	a.authType.SetLabel("Authentication Type ")
 
 	a.focusManager = util.NewFocusManager(a, GetApplication(), events.Dispatcher(), a.authType)
 	a.focusManager.SetName("auth_view")
+	a.focusManager.SetHandler(a.handleKeyEvent)
 
 	// create views for various schemes
 	a.basic = NewBasicAuthView(a.handleBasicAuthParameterChange, a.focusManager)
 	a.oauth2 = NewOAuth2View(a.handleOAuth2ParameterChange, a.focusManager)
+	a.bearer = NewBearerAuthView(a.handleBearerAuthParameterChange, a.focusManager)
+
+	a.inherited = tview.NewTextView()
+	a.inherited.SetDynamicColors(true)
 
 	// add scheme views to pages and show a default one
 	a.pages = tview.NewPages()
 	a.pages.AddAndSwitchToPage(authTypeNone, tview.NewBox(), true)
 	a.pages.AddPage(authTypeBasic, a.basic.Widget(), true, false)
 	a.pages.AddPage(authTypeOAuth2Option, a.oauth2.Widget(), true, false)
+	a.pages.AddPage(authTypeBearer, a.bearer.Widget(), true, false)
+	a.pages.AddPage(authTypeInherit, a.inherited, true, false)
 
 	a.flex.AddItem(a.authType, 1, 0, true)
 	a.flex.AddItem(a.pages, 0, 1, false)
@@ -123,8 +187,28 @@ func (a *AuthView) build() {
 
 	a.flex.SetInputCapture(a.focusManager.HandleKeyEvent)
 
-	// set defaults after the ui has been built
+	// set defaults after the ui has been built without notifying the handler, since no item has been set yet
+	a.authType.SetSelectedFunc(nil)
 	a.authType.SetCurrentOption(0)
+	a.authType.SetSelectedFunc(a.handleAuthTypeChange)
+	a.focusManager.SetPrimitives(a.FocusPrimitives()...)
+}
+
+func (a *AuthView) handleKeyEvent(event *tcell.EventKey) *tcell.EventKey {
+	if keys.Get().Matches(keys.RevealSecrets, event) {
+		a.SetRevealSecrets(!a.reveal)
+		return nil
+	}
+
+	return event
+}
+
+// SetRevealSecrets controls whether secret values are shown in plaintext or masked.
+func (a *AuthView) SetRevealSecrets(reveal bool) {
+	a.reveal = reveal
+	a.basic.SetRevealSecrets(reveal)
+	a.oauth2.SetRevealSecrets(reveal)
+	a.bearer.SetRevealSecrets(reveal)
 }
 
 func (a *AuthView) handleAuthTypeChange(text string, index int) {
@@ -134,20 +218,49 @@ func (a *AuthView) handleAuthTypeChange(text string, index int) {
 	// notify the handler func that parameters have changed
 	switch text {
 	case authTypeNone:
-		a.handleOAuth2ParameterChange(nil)
+		a.notify(nil)
 	case authTypeBasic:
 		a.basic.SetFocus()
 		a.handleBasicAuthParameterChange(a.basic.Data())
 	case authTypeOAuth2Option:
 		a.oauth2.SetFocus()
 		a.handleOAuth2ParameterChange(a.oauth2.Data())
+	case authTypeBearer:
+		a.bearer.SetFocus()
+		a.handleBearerAuthParameterChange(a.bearer.Data())
+	case authTypeInherit:
+		a.notify(auth.NewInheritedAuthentication())
+
+		if a.item != nil {
+			a.setInherited(a.item)
+		}
 	}
 }
 
 func (a *AuthView) handleOAuth2ParameterChange(data *auth.OAuth2RequestAuthentication) {
-	a.handler(data)
+	a.notify(data)
 }
 
 func (a *AuthView) handleBasicAuthParameterChange(data *auth.BasicAuthentication) {
-	a.handler(data)
+	a.notify(data)
+}
+
+func (a *AuthView) handleBearerAuthParameterChange(data *auth.BearerAuthentication) {
+	a.notify(data)
+}
+
+func (a *AuthView) notify(data auth.RequestAuthentication) {
+	// the handler is optional when the view is used in a modal
+	if a.handler != nil {
+		a.handler(data)
+	}
+}
+
+// secretMaskCharacter returns the mask character for input fields containing secrets.
+func secretMaskCharacter(reveal bool) rune {
+	if reveal {
+		return 0
+	}
+
+	return secretMask
 }
@@ -39,6 +39,11 @@ func (a *BasicAuthView) Set(data *auth.BasicAuthentication) {
 	a.password.SetText(data.Password)
 }
 
+// SetRevealSecrets controls whether secret values are shown in plaintext or masked.
+func (a *BasicAuthView) SetRevealSecrets(reveal bool) {
+	a.password.SetMaskCharacter(secretMaskCharacter(reveal))
+}
+
 // FocusPrimitives returns a slice of primitives that should receive focus.
 func (a *BasicAuthView) FocusPrimitives() []tview.Primitive {
 	return []tview.Primitive{
@@ -69,6 +74,7 @@ func (a *BasicAuthView) build() {
 
 	a.password = tview.NewInputField()
 	a.password.SetChangedFunc(a.handleParameterChange)
+	a.SetRevealSecrets(false)
 
 	a.grid.AddItem(a.label("Username"), 0, 0, 1, 1, 0, 0, false)
 	a.grid.AddItem(a.username, 0, 1, 1, 1, 0, 0, true)
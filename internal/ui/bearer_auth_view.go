@@ -0,0 +1,90 @@
+package ui
+
+import (
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/mbpolan/lull/internal/util"
+	"github.com/rivo/tview"
+)
+
+type BearerAuthChangeHandler func(data *auth.BearerAuthentication)
+
+// BearerAuthView contains form fields that represent a static bearer token.
+type BearerAuthView struct {
+	grid         *tview.Grid
+	token        *tview.InputField
+	focusManager *util.FocusManager
+	handler      BearerAuthChangeHandler
+}
+
+// NewBearerAuthView returns a new BearerAuthView instance configured with a change handler function.
+func NewBearerAuthView(handler BearerAuthChangeHandler, manager *util.FocusManager) *BearerAuthView {
+	v := &BearerAuthView{
+		handler:      handler,
+		focusManager: manager,
+	}
+
+	v.build()
+	return v
+}
+
+// Data returns the authentication data provided in the view.
+func (a *BearerAuthView) Data() *auth.BearerAuthentication {
+	return auth.NewBearerAuthentication(a.token.GetText())
+}
+
+// Set applies the values for the bearer authentication scheme.
+func (a *BearerAuthView) Set(data *auth.BearerAuthentication) {
+	a.token.SetText(data.Token)
+}
+
+// SetRevealSecrets controls whether secret values are shown in plaintext or masked.
+func (a *BearerAuthView) SetRevealSecrets(reveal bool) {
+	a.token.SetMaskCharacter(secretMaskCharacter(reveal))
+}
+
+// FocusPrimitives returns a slice of primitives that should receive focus.
+func (a *BearerAuthView) FocusPrimitives() []tview.Primitive {
+	return []tview.Primitive{
+		a.token,
+	}
+}
+
+// SetFocus sets the focus on this component.
+func (a *BearerAuthView) SetFocus() {
+	GetApplication().SetFocus(a.FocusPrimitives()[0])
+}
+
+// Widget returns a primitive widget containing this component.
+func (a *BearerAuthView) Widget() tview.Primitive {
+	return a.grid
+}
+
+func (a *BearerAuthView) build() {
+	a.grid = tview.NewGrid()
+
+	// give the input fields as must space as possible, fix the size of the labels
+	a.grid.SetColumns(15, -1)
+	a.grid.SetRows(2, -1)
+
+	a.token = tview.NewInputField()
+	a.token.SetChangedFunc(a.handleParameterChange)
+	a.SetRevealSecrets(false)
+
+	a.grid.AddItem(a.label("Token"), 0, 0, 1, 1, 0, 0, false)
+	a.grid.AddItem(a.token, 0, 1, 1, 1, 0, 0, true)
+
+	// fill remaining vertical space
+	a.grid.AddItem(tview.NewBox(), 1, 1, 1, 2, 0, 0, false)
+
+	a.token.SetInputCapture(a.focusManager.HandleKeyEvent)
+}
+
+func (a *BearerAuthView) label(text string) *tview.TextView {
+	t := tview.NewTextView()
+	t.SetText(text)
+	return t
+}
+
+func (a *BearerAuthView) handleParameterChange(_ string) {
+	a.handler(a.Data())
+}
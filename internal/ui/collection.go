@@ -4,29 +4,72 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/mbpolan/lull/internal/events"
+	"github.com/mbpolan/lull/internal/keys"
 	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/theme"
 	"github.com/mbpolan/lull/internal/util"
 	"github.com/rivo/tview"
+	"strings"
 )
 
 const collectionNodeExpanded = "▾"
 const collectionNodeCollapsed = "▸"
+const collectionTitle = "Collection"
 
 type CollectionItemAction int
 
 const (
 	CollectionItemOpen CollectionItemAction = iota
 	CollectionItemAdd
+	CollectionItemAddGroup
 	CollectionItemRename
 	CollectionItemDelete
 	CollectionItemClone
+	CollectionItemAuthentication
+	CollectionItemHeaders
+	CollectionItemDocs
+	CollectionItemVariables
+	CollectionItemImport
+	CollectionItemExport
+	CollectionItemSync
+	CollectionItemCut
+	CollectionItemPaste
+	CollectionItemMoveUp
+	CollectionItemMoveDown
+	CollectionItemMoveToGroup
+	CollectionItemRun
 )
 
+// collectionItemActions maps the actions bound to keys to the actions performed on collection items.
+var collectionItemActions = map[keys.Action]CollectionItemAction{
+	keys.ItemAdd:            CollectionItemAdd,
+	keys.ItemAddGroup:       CollectionItemAddGroup,
+	keys.ItemRename:         CollectionItemRename,
+	keys.ItemDelete:         CollectionItemDelete,
+	keys.ItemClone:          CollectionItemClone,
+	keys.ItemAuthentication: CollectionItemAuthentication,
+	keys.ItemHeaders:        CollectionItemHeaders,
+	keys.ItemDocs:           CollectionItemDocs,
+	keys.ItemVariables:      CollectionItemVariables,
+	keys.ItemImport:         CollectionItemImport,
+	keys.ItemExport:         CollectionItemExport,
+	keys.ItemSync:           CollectionItemSync,
+	keys.ItemCut:            CollectionItemCut,
+	keys.ItemPaste:          CollectionItemPaste,
+	keys.ItemMoveUp:         CollectionItemMoveUp,
+	keys.ItemMoveDown:       CollectionItemMoveDown,
+	keys.ItemMoveToGroup:    CollectionItemMoveToGroup,
+	keys.ItemRun:            CollectionItemRun,
+}
+
 type CollectionItemActionHandler func(action CollectionItemAction, item *state.CollectionItem)
 
 // Collection is a view that shows saved API requests.
 type Collection struct {
+	flex         *tview.Flex
 	tree         *tview.TreeView
+	filterInput  *tview.InputField
+	filter       *state.Filter
 	state        *state.Manager
 	focusManager *util.FocusManager
 	sbSequences  []events.StatusBarContextChangeSequence
@@ -39,29 +82,29 @@ func NewCollection(state *state.Manager) *Collection {
 	p.state = state
 	p.build()
 
+	km := keys.Get()
 	p.sbSequences = []events.StatusBarContextChangeSequence{
 		{
 			Label:       "Open",
 			KeySequence: "⏎",
 		},
-		{
-			Label:       "New",
-			KeySequence: "+",
-		},
-		{
-			Label:       "Delete",
-			KeySequence: "-",
-		},
-		{
-			Label:       "Rename",
-			KeySequence: "r",
-		},
-		{
-			Label:       "Clone",
-			KeySequence: "c",
-		},
 	}
 
+	p.sbSequences = append(p.sbSequences, km.Sequences(keys.ItemAdd, keys.ItemAddGroup, keys.ItemDelete, keys.ItemRename,
+		keys.ItemClone, keys.ItemAuthentication, keys.ItemHeaders, keys.ItemDocs, keys.ItemVariables, keys.ItemImport,
+		keys.ItemExport, keys.ItemSync, keys.ItemCut, keys.ItemPaste)...)
+
+	if move := km.Label(keys.ItemMoveUp, keys.ItemMoveDown); move != "" {
+		p.sbSequences = append(p.sbSequences, events.StatusBarContextChangeSequence{
+			Label:       "Move",
+			KeySequence: move,
+		})
+	}
+
+	p.sbSequences = append(p.sbSequences, km.Sequences(keys.ItemMoveToGroup, keys.ItemRun, keys.Filter)...)
+
+	events.Dispatcher().Subscribe(p, []events.Code{events.EventCollectionItemChanged})
+
 	return p
 }
 
@@ -70,22 +113,47 @@ func (p *Collection) SetItemActivatedHandler(handler CollectionItemActionHandler
 	p.onAction = handler
 }
 
+func (p *Collection) HandleEvent(code events.Code, payload events.Payload) {
+	switch code {
+	case events.EventCollectionItemChanged:
+		// refresh the label of an item that was changed elsewhere
+		if item, ok := payload.Data.(*state.CollectionItem); ok {
+			if node := p.findNodeForItem(p.tree.GetRoot(), item); node != nil {
+				node.SetText(p.labelForNode(node))
+			}
+		}
+	default:
+		break
+	}
+}
+
 // Widget returns a primitive widget containing this component.
 func (p *Collection) Widget() tview.Primitive {
-	return p.tree
+	return p.flex
 }
 
 // Reload clears all nodes from the collection and rebuilds them from current app state. The currently selected
 // item will be selected once again after reloading data if it still exists. If it doesn't exist anymore, the root
-// item will be selected.
+// item will be selected. If a filter is applied, only matching requests and the groups containing them are shown.
 func (p *Collection) Reload() {
 	root := p.buildTreeNodes(p.state.Get().Collection)
+	if root == nil {
+		// the root group is always shown, even if nothing matches the filter
+		root = tview.NewTreeNode("")
+		root.SetReference(p.state.Get().Collection)
+		root.SetText(p.labelForNode(root))
+		root.SetColor(tview.Styles.SecondaryTextColor)
+	}
+
 	p.tree.SetRoot(root)
 
-	// select the previously selected item, if it still exists
+	// select the previously selected item, if it still exists. the tree doesn't notify the changed func when the
+	// current node is set, so the selected item is updated here for actions to apply to the root instead of an item
+	// that is no longer shown
 	selected := p.findNodeForItem(root, p.state.Get().SelectedItem)
 	if selected == nil {
 		p.tree.SetCurrentNode(root)
+		p.handleNodeChange(root)
 	} else {
 		p.tree.SetCurrentNode(selected)
 	}
@@ -108,7 +176,7 @@ func (p *Collection) SetFocus() {
 // build creates the layout and child components.
 func (p *Collection) build() {
 	p.tree = tview.NewTreeView()
-	p.tree.SetTitle("Collection")
+	p.tree.SetTitle(collectionTitle)
 	p.tree.SetBorder(true)
 	p.tree.SetSelectedFunc(func(node *tview.TreeNode) {
 		p.handleSelectNode(node, true)
@@ -120,9 +188,50 @@ func (p *Collection) build() {
 	p.focusManager.AddArrowNavigation(util.FocusRight)
 	p.tree.SetInputCapture(p.focusManager.HandleKeyEvent)
 
+	p.filterInput = tview.NewInputField()
+	p.filterInput.SetLabel("/")
+	p.filterInput.SetChangedFunc(p.handleFilterChange)
+	p.filterInput.SetDoneFunc(p.handleFilterDone)
+
+	// the filter input is only shown while a filter is being edited or applied
+	p.flex = tview.NewFlex()
+	p.flex.SetDirection(tview.FlexRow)
+	p.flex.AddItem(p.tree, 0, 1, true)
+	p.flex.AddItem(p.filterInput, 0, 0, false)
+
+	p.Reload()
+}
+
+// showFilter reveals the filter input and moves focus to it.
+func (p *Collection) showFilter() {
+	p.flex.ResizeItem(p.filterInput, 1, 0)
+	GetApplication().SetFocus(p.filterInput)
+}
+
+func (p *Collection) handleFilterChange(text string) {
+	p.filter = state.ParseFilter(text)
+	if p.filter == nil {
+		p.tree.SetTitle(collectionTitle)
+	} else {
+		p.tree.SetTitle(fmt.Sprintf("%s (filtered)", collectionTitle))
+	}
+
 	p.Reload()
 }
 
+func (p *Collection) handleFilterDone(key tcell.Key) {
+	// escape clears the filter, restoring the tree as it was before filtering
+	if key == tcell.KeyEscape {
+		p.filterInput.SetText("")
+	}
+
+	if p.filter == nil {
+		p.flex.ResizeItem(p.filterInput, 0, 0)
+	}
+
+	GetApplication().SetFocus(p.tree)
+}
+
 func (p *Collection) handleSelectNode(node *tview.TreeNode, fireCallback bool) {
 	item := node.GetReference().(*state.CollectionItem)
 
@@ -134,6 +243,12 @@ func (p *Collection) handleSelectNode(node *tview.TreeNode, fireCallback bool) {
 			node.Expand()
 		}
 
+		// groups are expanded while filtering, so only remember changes made to the unfiltered tree
+		if p.filter == nil {
+			p.state.Get().Layout.SetCollapsed(item.UUID, !node.IsExpanded())
+			p.state.SetDirty()
+		}
+
 		// update the node label to contain the correct prefix character (expanded vs collapsed)
 		node.SetText(p.labelForNode(node))
 	} else {
@@ -162,18 +277,26 @@ func (p *Collection) setNodeActive(node *tview.TreeNode, fireCallback bool) {
 	}
 }
 
-// buildTreeNodes constructs a tree of tview.TreeNode objects corresponding to the items in our collection.
+// buildTreeNodes constructs a tree of tview.TreeNode objects corresponding to the items in our collection. If a
+// filter is applied, nil is returned for items that neither match it nor contain a matching request.
 func (p *Collection) buildTreeNodes(item *state.CollectionItem) *tview.TreeNode {
 	var node *tview.TreeNode
 
+	if p.filter != nil && !p.filter.MatchesAny(item) {
+		return nil
+	}
+
 	if item.IsGroup {
 		node = tview.NewTreeNode("")
 		node.SetReference(item)
+		node.SetExpanded(p.filter != nil || !p.state.Get().Layout.IsCollapsed(item.UUID))
 		node.SetText(p.labelForNode(node))
 		node.SetColor(tview.Styles.SecondaryTextColor)
 
 		for _, c := range item.Children {
-			node.AddChild(p.buildTreeNodes(c))
+			if child := p.buildTreeNodes(c); child != nil {
+				node.AddChild(child)
+			}
 		}
 	} else {
 		node = tview.NewTreeNode("")
@@ -199,9 +322,10 @@ func (p *Collection) labelForNode(node *tview.TreeNode) string {
 			prefix = collectionNodeCollapsed
 		}
 
-		return fmt.Sprintf("%s%s", prefix, item.Name)
+		return fmt.Sprintf("%s%s", prefix, tview.Escape(item.Name))
 	} else {
-		return item.Name
+		method := theme.Colorize(theme.Get().Method(item.Method), item.Method)
+		return fmt.Sprintf("%s %s", method, tview.Escape(item.Name))
 	}
 }
 
@@ -228,31 +352,18 @@ func (p *Collection) findNodeForItem(node *tview.TreeNode, item *state.Collectio
 }
 
 func (p *Collection) handleKeyEvent(event *tcell.EventKey) *tcell.EventKey {
-	if event.Rune() == 'r' {
-		if p.state.Get().SelectedItem != nil {
-			p.onAction(CollectionItemRename, p.state.Get().SelectedItem)
-		}
-
-		return nil
-	} else if event.Rune() == '+' {
-		if p.state.Get().SelectedItem != nil {
-			p.onAction(CollectionItemAdd, p.state.Get().SelectedItem)
-		}
-
-		return nil
-	} else if event.Rune() == '-' {
-		if p.state.Get().SelectedItem != nil {
-			p.onAction(CollectionItemDelete, p.state.Get().SelectedItem)
-		}
+	action, ok := keys.Get().Action(keys.ContextCollection, event)
+	if !ok {
+		return event
+	}
 
-		return nil
-	} else if event.Rune() == 'c' {
-		if p.state.Get().SelectedItem != nil {
-			p.onAction(CollectionItemClone, p.state.Get().SelectedItem)
-		}
+	if action == keys.Filter {
+		p.showFilter()
+	} else if item := p.state.Get().SelectedItem; item != nil {
+		p.onAction(collectionItemActions[action], item)
 	}
 
-	return event
+	return nil
 }
 
 func (p *Collection) handleNodeChange(node *tview.TreeNode) {
@@ -262,4 +373,26 @@ func (p *Collection) handleNodeChange(node *tview.TreeNode) {
 	}
 
 	p.state.Get().SelectedItem = item
+	p.postItemSummary(item)
+}
+
+// postItemSummary shows the tags and the first line of the description of an item in the status bar.
+func (p *Collection) postItemSummary(item *state.CollectionItem) {
+	text := ""
+	if item.Documented() {
+		var parts []string
+		if len(item.Tags) > 0 {
+			parts = append(parts, fmt.Sprintf("Tags: %s", strings.Join(item.Tags, ", ")))
+		}
+
+		if line, _, _ := strings.Cut(strings.TrimSpace(item.Description), "\n"); line != "" {
+			parts = append(parts, strings.TrimLeft(line, "# "))
+		}
+
+		text = fmt.Sprintf("%s: %s", item.Name, strings.Join(parts, " | "))
+	}
+
+	events.Dispatcher().Post(events.EventStatusBarSummary, p, &events.StatusBarSummaryData{
+		Text: text,
+	})
 }
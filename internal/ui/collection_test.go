@@ -0,0 +1,29 @@
+package ui
+
+import (
+	"github.com/mbpolan/lull/internal/events"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Collection_Reload_HiddenSelection(t *testing.T) {
+	events.Setup()
+
+	s := state.NewAppState()
+	root := state.NewCollectionGroup("root", nil)
+	users := state.NewCollectionRequest("users", "GET", "https://example.com/users", root)
+	pets := state.NewCollectionRequest("pets", "GET", "https://example.com/pets", root)
+	root.AddChild(users)
+	root.AddChild(pets)
+	s.Collection = root
+	s.SelectedItem = users
+
+	m := state.NewStateManager(s, state.NewFileStorage(t.TempDir()+"/state.json"))
+	c := NewCollection(m)
+	c.Reload()
+	c.filterInput.SetText("pets")
+
+	assert.Same(t, root, c.tree.GetCurrentNode().GetReference())
+	assert.Same(t, root, s.SelectedItem)
+}
@@ -0,0 +1,18 @@
+package ui
+
+import "github.com/mbpolan/lull/internal/theme"
+
+// highlight returns text in the color used to call out names and values in messages.
+func highlight(text string) string {
+	return theme.Colorize(theme.Get().Highlight, text)
+}
+
+// muted returns text in the color used for supplementary information.
+func muted(text string) string {
+	return theme.Colorize(theme.Get().Muted, text)
+}
+
+// errorText returns text in the color used for errors.
+func errorText(text string) string {
+	return theme.Colorize(theme.Get().Error, text)
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/rivo/tview"
+)
+
+type ConflictModalAcceptHandler func(resolution state.ConflictResolution)
+
+// conflictResolutions lists the ways a conflict can be resolved, in the order they are offered.
+var conflictResolutions = []struct {
+	label      string
+	resolution state.ConflictResolution
+}{
+	{"Merge changes from both", state.ConflictMerge},
+	{"Reload and discard my changes", state.ConflictReload},
+	{"Overwrite their changes", state.ConflictOverwrite},
+}
+
+// ConflictModal is a modal that prompts the user to choose how to resolve changes made to the app state by another
+// instance of lull.
+type ConflictModal struct {
+	resolution *tview.DropDown
+	onAccept   ConflictModalAcceptHandler
+	*BaseInputModal
+}
+
+// NewConflictModal returns a new modal offering to merge, reload or overwrite the app state. A resolution must be
+// chosen, so the modal cannot be dismissed otherwise.
+func NewConflictModal(accept ConflictModalAcceptHandler) *ConflictModal {
+	m := new(ConflictModal)
+	m.BaseInputModal = NewBaseInputModal()
+	m.onAccept = accept
+	m.build()
+
+	return m
+}
+
+func (m *ConflictModal) build() {
+	row := m.BaseInputModal.build("Conflict", "The collection was changed by another instance.", func() {
+		if i, _ := m.resolution.GetCurrentOption(); i >= 0 {
+			m.onAccept(conflictResolutions[i].resolution)
+		}
+	})
+
+	options := make([]string, len(conflictResolutions))
+	for i, r := range conflictResolutions {
+		options[i] = r.label
+	}
+
+	m.resolution = tview.NewDropDown()
+	m.resolution.SetLabel("Resolve ")
+	m.resolution.SetOptions(options, nil)
+	m.resolution.SetCurrentOption(0)
+
+	m.grid.AddItem(m.resolution, row, 0, 1, 2, 0, 0, true)
+
+	m.buildButtons(row+1, BaseInputModalButtonAccept)
+	m.setupFocus([]tview.Primitive{m.resolution, m.ok})
+}
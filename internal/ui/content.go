@@ -3,12 +3,21 @@ package ui
 import (
 	"github.com/mbpolan/lull/internal/events"
 	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/util"
 	"github.com/rivo/tview"
 )
 
+// defaultRequestShare is the percentage of the content width given to the request view by default.
+const defaultRequestShare = 50
+
+// minRequestShare and maxRequestShare limit how much of the content width the request view can use.
+const minRequestShare = 20
+const maxRequestShare = 80
+
 // Content provides a view that shows a request, response and URL input box.
 type Content struct {
 	flex     *tview.Flex
+	split    *tview.Flex
 	url      *URLBox
 	request  *RequestView
 	response *ResponseView
@@ -94,6 +103,8 @@ func (c *Content) HasFocus(widget ContentWidget) bool {
 		return c.url.Widget().HasFocus()
 	case ContentRequestBody:
 		return c.request.Widget().HasFocus()
+	case ContentResponseBody:
+		return c.response.Widget().HasFocus()
 	default:
 		return false
 	}
@@ -104,12 +115,45 @@ func (c *Content) build() {
 	c.request = NewRequestView(c.state)
 	c.response = NewResponseView(c.state)
 
-	split := tview.NewFlex()
-	split.AddItem(c.request.Widget(), 0, 1, false)
-	split.AddItem(c.response.Widget(), 0, 1, false)
+	c.split = tview.NewFlex()
+	c.split.AddItem(c.request.Widget(), 0, 1, false)
+	c.split.AddItem(c.response.Widget(), 0, 1, false)
 
 	c.flex = tview.NewFlex()
 	c.flex.SetDirection(tview.FlexRow)
 	c.flex.AddItem(c.url.Widget(), 3, 0, true)
-	c.flex.AddItem(split, 0, 5, false)
+	c.flex.AddItem(c.split, 0, 5, false)
+
+	c.RestoreLayout()
+}
+
+// RestoreLayout applies the saved layout to the request and response views.
+func (c *Content) RestoreLayout() {
+	c.request.RestoreLayout()
+	c.response.RestoreLayout()
+	c.applyRequestShare()
+}
+
+// ResizeRequest grows or shrinks the request view, and the response view with it, by delta percent of the available
+// width.
+func (c *Content) ResizeRequest(delta int) {
+	layout := &c.state.Get().Layout
+	layout.RequestShare = util.Max(minRequestShare, util.Min(maxRequestShare, c.requestShare()+delta))
+	c.state.SetDirty()
+
+	c.applyRequestShare()
+}
+
+func (c *Content) requestShare() int {
+	if share := c.state.Get().Layout.RequestShare; share > 0 {
+		return share
+	}
+
+	return defaultRequestShare
+}
+
+func (c *Content) applyRequestShare() {
+	share := c.requestShare()
+	c.split.ResizeItem(c.request.Widget(), 0, share)
+	c.split.ResizeItem(c.response.Widget(), 0, 100-share)
 }
@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"github.com/rivo/tview"
+	"strings"
+)
+
+type DocsModalAcceptHandler func(tags []string, description string)
+
+// DocsModal is a modal that allows editing the tags and markdown description of an item.
+type DocsModal struct {
+	tags        *tview.InputField
+	description *tview.TextArea
+	onAccept    DocsModalAcceptHandler
+	*BaseInputModal
+}
+
+// NewDocsModal returns a new instance of DocsModal.
+func NewDocsModal(title string, accept DocsModalAcceptHandler, reject ModalRejectHandler) *DocsModal {
+	m := new(DocsModal)
+	m.BaseInputModal = NewBaseInputModal()
+	m.width = 75
+	m.height = 16
+	m.onAccept = accept
+	m.onReject = reject
+	m.build(title)
+
+	return m
+}
+
+// Set populates the modal with existing tags and description.
+func (m *DocsModal) Set(tags []string, description string) {
+	m.tags.SetText(strings.Join(tags, ", "))
+	m.description.SetText(description, false)
+}
+
+func (m *DocsModal) build(title string) {
+	row := m.BaseInputModal.build(title, "Describe this group using markdown", func() {
+		m.onAccept(parseTags(m.tags.GetText()), m.description.GetText())
+	})
+
+	m.tags = tview.NewInputField()
+	m.tags.SetLabel("Tags ")
+	m.tags.SetPlaceholder("comma, separated")
+
+	m.description = tview.NewTextArea()
+
+	m.grid.AddItem(m.tags, row, 0, 1, 2, 0, 0, true)
+	m.grid.AddItem(m.description, row+1, 0, 1, 2, 0, 0, false)
+
+	m.buildButtons(row+2, BaseInputModalButtonAll)
+
+	// fixed height for the info text, tags and buttons with the text area taking up the remaining space
+	m.grid.SetRows(1, 1, -1, m.ButtonHeight())
+
+	m.setupFocus([]tview.Primitive{m.tags, m.description, m.ok, m.cancel})
+}
+
+// parseTags returns the distinct tags in a comma-separated list, in the order they were given.
+func parseTags(text string) []string {
+	var tags []string
+	seen := map[string]bool{}
+	for _, t := range strings.Split(text, ",") {
+		if t = strings.TrimSpace(t); t != "" && !seen[t] {
+			tags = append(tags, t)
+			seen[t] = true
+		}
+	}
+
+	return tags
+}
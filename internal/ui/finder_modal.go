@@ -0,0 +1,247 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/gdamore/tcell/v2"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/theme"
+	"github.com/mbpolan/lull/internal/util"
+	"github.com/rivo/tview"
+	"sort"
+	"strings"
+)
+
+// maxFinderResults is the number of matching requests listed in the finder.
+const maxFinderResults = 50
+
+// maxFinderPreviewBody is the number of characters of a request body shown in the preview.
+const maxFinderPreviewBody = 500
+
+// finderNameWeight and finderURLWeight favour matches on names and URLs over matches on group paths and methods.
+const finderNameWeight = 3
+const finderURLWeight = 2
+
+type FinderModalAcceptHandler func(item *state.CollectionItem)
+
+// finderResult is a request that matched the search query.
+type finderResult struct {
+	item  *state.CollectionItem
+	path  string
+	score int
+}
+
+// FinderModal is a modal that searches for requests across the entire collection.
+type FinderModal struct {
+	grid     *tview.Grid
+	query    *tview.InputField
+	list     *tview.List
+	preview  *tview.TextView
+	items    []*state.CollectionItem
+	results  []finderResult
+	onAccept FinderModalAcceptHandler
+	onReject ModalRejectHandler
+	*Modal
+}
+
+// NewFinderModal returns a new modal that searches the requests contained in the collection.
+func NewFinderModal(collection *state.CollectionItem, accept FinderModalAcceptHandler, reject ModalRejectHandler) *FinderModal {
+	m := new(FinderModal)
+	m.onAccept = accept
+	m.onReject = reject
+	m.items = collectRequests(collection)
+	m.build()
+	m.search("")
+
+	return m
+}
+
+// Widget returns a primitive widget containing this component.
+func (m *FinderModal) Widget() tview.Primitive {
+	return m.Modal.flex
+}
+
+func (m *FinderModal) build() {
+	m.query = tview.NewInputField()
+	m.query.SetLabel("Find ")
+	m.query.SetChangedFunc(m.search)
+	m.query.SetInputCapture(m.handleKeyEvent)
+
+	m.list = tview.NewList()
+	m.list.ShowSecondaryText(false)
+	m.list.SetHighlightFullLine(true)
+	m.list.SetChangedFunc(func(index int, _ string, _ string, _ rune) {
+		m.showPreview(index)
+	})
+
+	m.preview = tview.NewTextView()
+	m.preview.SetDynamicColors(true)
+	m.preview.SetWrap(true)
+	m.preview.SetBorder(true)
+	m.preview.SetTitle("Preview")
+
+	m.grid = tview.NewGrid()
+	m.grid.SetBorder(true)
+	m.grid.SetTitle("Find Request")
+	m.grid.SetRows(1, -1)
+	m.grid.SetColumns(-1, -1)
+	m.grid.AddItem(m.query, 0, 0, 1, 2, 0, 0, true)
+	m.grid.AddItem(m.list, 1, 0, 1, 1, 0, 0, false)
+	m.grid.AddItem(m.preview, 1, 1, 1, 1, 0, 0, false)
+
+	m.Modal = NewModal(m.grid, 100, 20)
+}
+
+// handleKeyEvent moves the selection in the list of results while the query retains focus.
+func (m *FinderModal) handleKeyEvent(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyUp:
+		if i := m.list.GetCurrentItem(); i > 0 {
+			m.list.SetCurrentItem(i - 1)
+		}
+	case tcell.KeyDown:
+		if i := m.list.GetCurrentItem(); i < m.list.GetItemCount()-1 {
+			m.list.SetCurrentItem(i + 1)
+		}
+	case tcell.KeyEnter:
+		if i := m.list.GetCurrentItem(); i >= 0 && i < len(m.results) {
+			m.onAccept(m.results[i].item)
+		} else {
+			util.ConsoleBell()
+		}
+	case tcell.KeyEscape:
+		m.onReject()
+	default:
+		return event
+	}
+
+	return nil
+}
+
+// search ranks all requests against the query and lists the best matches.
+func (m *FinderModal) search(query string) {
+	terms := strings.Fields(query)
+	m.results = nil
+
+	for _, item := range m.items {
+		path := itemPath(item)
+		if score, ok := matchRequest(item, path, terms); ok {
+			m.results = append(m.results, finderResult{
+				item:  item,
+				path:  path,
+				score: score,
+			})
+		}
+	}
+
+	// keep the collection order for requests that score the same
+	sort.SliceStable(m.results, func(i, j int) bool {
+		return m.results[i].score > m.results[j].score
+	})
+
+	if len(m.results) > maxFinderResults {
+		m.results = m.results[:maxFinderResults]
+	}
+
+	m.list.Clear()
+	for _, r := range m.results {
+		text := fmt.Sprintf("%s %s %s", theme.Colorize(theme.Get().Method(r.item.Method), fmt.Sprintf("%-7s", r.item.Method)),
+			tview.Escape(r.item.Name), muted(tview.Escape(r.path)))
+		m.list.AddItem(text, "", 0, nil)
+	}
+
+	m.showPreview(m.list.GetCurrentItem())
+}
+
+// showPreview shows the details of the request at the given index in the list of results.
+func (m *FinderModal) showPreview(index int) {
+	if index < 0 || index >= len(m.results) {
+		m.preview.SetText("No matching requests.")
+		return
+	}
+
+	item := m.results[index].item
+	text := fmt.Sprintf("%s\n%s\n\n%s %s", highlight(tview.Escape(item.Name)), tview.Escape(m.results[index].path),
+		item.Method, tview.Escape(item.URL))
+
+	keys := make([]string, 0, len(item.Headers))
+	for k := range item.Headers {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	if len(keys) > 0 {
+		text = fmt.Sprintf("%s\n", text)
+	}
+
+	for _, k := range keys {
+		text = fmt.Sprintf("%s\n%s: %s", text, tview.Escape(k), tview.Escape(strings.Join(item.Headers[k], headerTableSeparator)))
+	}
+
+	if body := item.RequestBody; body != nil && body.Payload != "" {
+		payload := body.Payload
+		if r := []rune(payload); len(r) > maxFinderPreviewBody {
+			payload = string(r[:maxFinderPreviewBody]) + "…"
+		}
+
+		text = fmt.Sprintf("%s\n\n%s", text, tview.Escape(payload))
+	}
+
+	m.preview.SetText(text)
+	m.preview.ScrollToBeginning()
+}
+
+// matchRequest scores a request against each term of a query. Every term must match the request's name, URL, method
+// or the path of groups containing it.
+func matchRequest(item *state.CollectionItem, path string, terms []string) (int, bool) {
+	total := 0
+	for _, term := range terms {
+		best, found := 0, false
+		candidates := []struct {
+			text   string
+			weight int
+		}{
+			{item.Name, finderNameWeight},
+			{item.URL, finderURLWeight},
+			{item.Method, 1},
+			{path, 1},
+		}
+
+		for _, c := range candidates {
+			if score, ok := util.FuzzyMatch(term, c.text); ok && (!found || score*c.weight > best) {
+				best, found = score*c.weight, true
+			}
+		}
+
+		if !found {
+			return 0, false
+		}
+
+		total += best
+	}
+
+	return total, true
+}
+
+// collectRequests returns all requests in the collection, in the order they appear in the collection tree.
+func collectRequests(item *state.CollectionItem) []*state.CollectionItem {
+	if !item.IsGroup {
+		return []*state.CollectionItem{item}
+	}
+
+	var items []*state.CollectionItem
+	for _, c := range item.Children {
+		items = append(items, collectRequests(c)...)
+	}
+
+	return items
+}
+
+// itemPath returns the names of the groups containing an item, starting from the root of the collection.
+func itemPath(item *state.CollectionItem) string {
+	var names []string
+	for _, a := range item.Ancestors() {
+		names = append(names, a.Name)
+	}
+
+	return strings.Join(names, " > ")
+}
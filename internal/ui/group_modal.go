@@ -0,0 +1,61 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/rivo/tview"
+)
+
+type GroupModalAcceptHandler func(group *state.CollectionItem)
+
+// GroupModal is a modal that prompts the user to choose a group from the collection.
+type GroupModal struct {
+	group    *tview.DropDown
+	groups   []*state.CollectionItem
+	onAccept GroupModalAcceptHandler
+	*BaseInputModal
+}
+
+// NewGroupModal returns a new modal listing the given groups by their path in the collection.
+func NewGroupModal(title string, text string, groups []*state.CollectionItem, accept GroupModalAcceptHandler, reject ModalRejectHandler) *GroupModal {
+	m := new(GroupModal)
+	m.BaseInputModal = NewBaseInputModal()
+	m.groups = groups
+	m.onAccept = accept
+	m.onReject = reject
+	m.build(title, text)
+
+	return m
+}
+
+func (m *GroupModal) build(title string, text string) {
+	row := m.BaseInputModal.build(title, text, func() {
+		if i, _ := m.group.GetCurrentOption(); i >= 0 {
+			m.onAccept(m.groups[i])
+		}
+	})
+
+	options := make([]string, len(m.groups))
+	for i, g := range m.groups {
+		options[i] = groupPath(g)
+	}
+
+	m.group = tview.NewDropDown()
+	m.group.SetLabel("Group ")
+	m.group.SetOptions(options, nil)
+	m.group.SetCurrentOption(0)
+
+	m.grid.AddItem(m.group, row, 0, 1, 2, 0, 0, true)
+
+	m.buildButtons(row+1, BaseInputModalButtonAll)
+	m.setupFocus([]tview.Primitive{m.group, m.ok, m.cancel})
+}
+
+// groupPath returns the names of a group and its ancestors, starting from the root of the collection.
+func groupPath(group *state.CollectionItem) string {
+	if path := itemPath(group); path != "" {
+		return fmt.Sprintf("%s > %s", path, group.Name)
+	}
+
+	return group.Name
+}
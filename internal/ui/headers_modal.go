@@ -0,0 +1,89 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/rivo/tview"
+	"sort"
+	"strings"
+)
+
+type HeadersModalAcceptHandler func(headers map[string][]string, inherit bool)
+
+// HeadersModal is a modal that allows editing a set of headers, one per line, in "Key: Value" format.
+type HeadersModal struct {
+	headers  *tview.TextArea
+	inherit  *tview.Checkbox
+	onAccept HeadersModalAcceptHandler
+	*BaseInputModal
+}
+
+// NewHeadersModal returns a new instance of HeadersModal.
+func NewHeadersModal(title string, accept HeadersModalAcceptHandler, reject ModalRejectHandler) *HeadersModal {
+	m := new(HeadersModal)
+	m.BaseInputModal = NewBaseInputModal()
+	m.width = 75
+	m.height = 16
+	m.onAccept = accept
+	m.onReject = reject
+	m.build(title)
+
+	return m
+}
+
+// Set populates the modal with existing headers and the inheritance flag.
+func (m *HeadersModal) Set(headers map[string][]string, inherit bool) {
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var lines []string
+	for _, k := range keys {
+		for _, v := range headers[k] {
+			lines = append(lines, fmt.Sprintf("%s: %s", k, v))
+		}
+	}
+
+	m.headers.SetText(strings.Join(lines, "\n"), false)
+	m.inherit.SetChecked(inherit)
+}
+
+func (m *HeadersModal) build(title string) {
+	row := m.BaseInputModal.build(title, "One header per line, formatted as "+highlight("Key: Value"), func() {
+		m.onAccept(m.parseHeaders(), m.inherit.IsChecked())
+	})
+
+	m.headers = tview.NewTextArea()
+
+	m.inherit = tview.NewCheckbox()
+	m.inherit.SetLabel("Inherit headers from parent ")
+
+	m.grid.AddItem(m.headers, row, 0, 1, 2, 0, 0, true)
+	m.grid.AddItem(m.inherit, row+1, 0, 1, 2, 0, 0, false)
+
+	m.buildButtons(row+2, BaseInputModalButtonAll)
+
+	// fixed height for the info text, checkbox and buttons with the text area taking up the remaining space
+	m.grid.SetRows(1, -1, 1, m.ButtonHeight())
+
+	m.setupFocus([]tview.Primitive{m.headers, m.inherit, m.ok, m.cancel})
+}
+
+// parseHeaders returns the headers entered in the text area. Lines without a key are ignored.
+func (m *HeadersModal) parseHeaders() map[string][]string {
+	headers := map[string][]string{}
+
+	for _, line := range strings.Split(m.headers.GetText(), "\n") {
+		key, value, _ := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+
+		headers[key] = append(headers[key], strings.TrimSpace(value))
+	}
+
+	return headers
+}
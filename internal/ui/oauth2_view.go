@@ -48,6 +48,11 @@ func (a *OAuth2View) Set(data *auth.OAuth2RequestAuthentication) {
 	a.scope.SetText(data.Scope)
 }
 
+// SetRevealSecrets controls whether secret values are shown in plaintext or masked.
+func (a *OAuth2View) SetRevealSecrets(reveal bool) {
+	a.clientSecret.SetMaskCharacter(secretMaskCharacter(reveal))
+}
+
 // FocusPrimitives returns a slice of primitives that should receive focus.
 func (a *OAuth2View) FocusPrimitives() []tview.Primitive {
 	return []tview.Primitive{
@@ -84,6 +89,7 @@ func (a *OAuth2View) build() {
 
 	a.clientSecret = tview.NewInputField()
 	a.clientSecret.SetChangedFunc(a.handleParameterChange)
+	a.SetRevealSecrets(false)
 
 	a.grantType = tview.NewInputField()
 	a.grantType.SetChangedFunc(a.handleParameterChange)
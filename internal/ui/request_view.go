@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/mbpolan/lull/internal/events"
+	"github.com/mbpolan/lull/internal/keys"
 	"github.com/mbpolan/lull/internal/parsers"
 	"github.com/mbpolan/lull/internal/state"
 	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/mbpolan/lull/internal/theme"
 	"github.com/mbpolan/lull/internal/util"
 	"github.com/rivo/tview"
+	"sort"
 	"strings"
 )
 
@@ -18,15 +21,19 @@ const requestViewTitle = "Request"
 const requestViewBody = "body"
 const requestViewHeaders = "headers"
 const requestViewAuthentication = "authentication"
+const requestViewDocs = "docs"
 const requestViewModal = "modal"
 
 const headerTableSeparator = "; "
+const headerTableInherited = "inherited"
 
-var contentTypeOptions = []string{"None", "JSON", "Text"}
+var contentTypeOptions = []string{"None", "JSON", "XML", "HTML", "Text"}
 var contentTypeOptionsToValues = map[string]string{
 	contentTypeOptions[0]: "",
 	contentTypeOptions[1]: "application/json",
-	contentTypeOptions[2]: "text/plain",
+	contentTypeOptions[2]: "application/xml",
+	contentTypeOptions[3]: "text/html",
+	contentTypeOptions[4]: "text/plain",
 }
 
 // RequestView is a view that allows viewing and editing request/response components.
@@ -34,12 +41,18 @@ type RequestView struct {
 	flex         *tview.Flex
 	pages        *tview.Pages
 	body         *tview.TextArea
+	bodyContent  *tview.Flex
+	preview      *tview.TextView
 	auth         *AuthView
 	contentType  *tview.DropDown
 	headers      *tview.Table
+	tags         *tview.InputField
+	description  *tview.TextArea
+	docs         *tview.TextView
 	focusHolder  *tview.TextView
 	focusManager *util.FocusManager
 	state        *state.Manager
+	reloading    bool
 }
 
 // NewRequestView returns a new instance of RequestView.
@@ -62,6 +75,12 @@ func (p *RequestView) SetFocus() {
 func (p *RequestView) Reload() {
 	p.setTitle()
 
+	// ignore changes reported by the views while they are being populated
+	p.reloading = true
+	defer func() {
+		p.reloading = false
+	}()
+
 	item := p.state.Get().ActiveItem
 	if item == nil {
 		return
@@ -78,8 +97,12 @@ func (p *RequestView) Reload() {
 			}
 		}
 
-		if contentTypeOption == "" {
-			contentTypeOption = contentTypeOptions[0]
+		// other XML content types, such as those used by SOAP, are edited as XML, and any other content type as text;
+		// the request keeps its content type unless a different option is chosen
+		if contentTypeOption == "" && parsers.IsXMLContentType(body.ContentType) {
+			contentTypeOption = contentTypeOptions[2]
+		} else if contentTypeOption == "" {
+			contentTypeOption = contentTypeOptions[4]
 		}
 
 		contentType := -1
@@ -101,6 +124,8 @@ func (p *RequestView) Reload() {
 		p.body.SetText("", false)
 	}
 
+	p.renderPreview()
+
 	// build header table
 	p.headers.Clear()
 	p.headers.SetCell(0, 0, tview.NewTableCell("Header").SetTextColor(tview.Styles.TertiaryTextColor))
@@ -113,12 +138,33 @@ func (p *RequestView) Reload() {
 		row++
 	}
 
+	// show inherited headers after the item's own headers; these cannot be edited from this item
+	if item.InheritHeaders {
+		inherited := item.InheritedHeaders()
+		keys := make([]string, 0, len(inherited))
+		for k := range inherited {
+			keys = append(keys, k)
+		}
+
+		sort.Strings(keys)
+		for _, k := range keys {
+			p.headers.SetCell(row, 0, p.inheritedHeaderCell(k))
+			p.headers.SetCell(row, 1, p.inheritedHeaderCell(strings.Join(inherited[k], headerTableSeparator)))
+			row++
+		}
+	}
+
 	if len(item.Headers) > 0 {
 		p.headers.Select(1, 0)
 	}
 
 	// apply authentication
 	p.auth.Set(item)
+
+	// apply documentation
+	p.tags.SetText(strings.Join(item.Tags, ", "))
+	p.description.SetText(item.Description, false)
+	p.renderDocs(item)
 }
 
 // Widget returns a primitive widget containing this component.
@@ -143,10 +189,16 @@ func (p *RequestView) build() {
 	p.contentType.SetLabel("Body ")
 	p.contentType.SetOptions(contentTypeOptions, p.handleContentTypeChange)
 
+	p.preview = tview.NewTextView()
+	p.preview.SetDynamicColors(true)
+
+	p.bodyContent = tview.NewFlex()
+	p.showPreview(false)
+
 	bodyFlex := tview.NewFlex()
 	bodyFlex.SetDirection(tview.FlexRow)
 	bodyFlex.AddItem(p.contentType, 1, 0, false)
-	bodyFlex.AddItem(p.body, 0, 1, true)
+	bodyFlex.AddItem(p.bodyContent, 0, 1, true)
 
 	p.auth = NewAuthView(p.handleAuthenticationChange)
 
@@ -154,9 +206,35 @@ func (p *RequestView) build() {
 	p.headers.SetSelectable(true, false)
 	p.headers.SetSelectedFunc(p.showEditHeaderModal)
 
+	p.tags = tview.NewInputField()
+	p.tags.SetLabel("Tags ")
+	p.tags.SetPlaceholder("comma, separated")
+	p.tags.SetChangedFunc(p.handleTagsChange)
+
+	p.description = tview.NewTextArea()
+	p.description.SetPlaceholder("Describe this request using markdown")
+	p.description.SetChangedFunc(p.handleDescriptionChange)
+
+	p.docs = tview.NewTextView()
+	p.docs.SetDynamicColors(true)
+	p.docs.SetWrap(true)
+	p.docs.SetWordWrap(true)
+
+	// show the description as written alongside its rendered form
+	docsContent := tview.NewFlex()
+	docsContent.AddItem(p.description, 0, 1, true)
+	docsContent.AddItem(tview.NewBox(), 1, 0, false)
+	docsContent.AddItem(p.docs, 0, 1, false)
+
+	docsFlex := tview.NewFlex()
+	docsFlex.SetDirection(tview.FlexRow)
+	docsFlex.AddItem(p.tags, 1, 0, false)
+	docsFlex.AddItem(docsContent, 0, 1, true)
+
 	p.pages.AddAndSwitchToPage(requestViewBody, bodyFlex, true)
 	p.pages.AddPage(requestViewHeaders, p.headers, true, false)
 	p.pages.AddPage(requestViewAuthentication, p.auth.Widget(), true, false)
+	p.pages.AddPage(requestViewDocs, docsFlex, true, false)
 
 	p.focusManager = util.NewFocusManager(p, GetApplication(), events.Dispatcher(), p.focusHolder)
 	p.focusManager.SetName("request_view")
@@ -168,6 +246,15 @@ func (p *RequestView) build() {
 	p.flex.SetInputCapture(p.focusManager.HandleKeyEvent)
 }
 
+func (p *RequestView) inheritedHeaderCell(text string) *tview.TableCell {
+	cell := tview.NewTableCell(text)
+	cell.SetTextColor(tview.Styles.SecondaryTextColor)
+	cell.SetSelectable(false)
+	cell.SetReference(headerTableInherited)
+
+	return cell
+}
+
 func (p *RequestView) setTitle() {
 	page, _ := p.pages.GetFrontPage()
 	if page == requestViewModal {
@@ -183,6 +270,29 @@ func (p *RequestView) setTitle() {
 }
 
 func (p *RequestView) switchToPage(view string) {
+	p.showPage(view)
+	p.postKeyboardSequences()
+
+	if layout := &p.state.Get().Layout; layout.RequestPage != view {
+		layout.RequestPage = view
+		p.state.SetDirty()
+	}
+}
+
+// RestoreLayout shows the page that was last selected.
+func (p *RequestView) RestoreLayout() {
+	p.showPreview(p.state.Get().Layout.BodyPreview)
+	p.renderPreview()
+
+	page := p.state.Get().Layout.RequestPage
+	if page == requestViewModal || !p.pages.HasPage(page) {
+		return
+	}
+
+	p.showPage(page)
+}
+
+func (p *RequestView) showPage(view string) {
 	// change the set of focus primitives based on the newly selected view
 	switch view {
 	case requestViewBody:
@@ -193,10 +303,11 @@ func (p *RequestView) switchToPage(view string) {
 		primitives := []tview.Primitive{p.focusHolder}
 		primitives = append(primitives, p.auth.FocusPrimitives()...)
 		p.focusManager.SetPrimitives(primitives...)
+	case requestViewDocs:
+		p.focusManager.SetPrimitives(p.focusHolder, p.tags, p.description)
 	}
 
 	p.pages.SwitchToPage(view)
-	p.postKeyboardSequences()
 	p.setTitle()
 }
 
@@ -210,21 +321,35 @@ func (p *RequestView) filterKeyEvent(event *tcell.EventKey) util.FocusFilterResu
 }
 
 func (p *RequestView) handleKeyEvent(event *tcell.EventKey) *tcell.EventKey {
+	action, ok := keys.Get().Action(keys.ContextRequest, event)
+	if !ok {
+		return event
+	}
+
 	// ensure that the parent primitive has focus to prevent switching pages while the user is entering text
 	// in one of the pages themselves
-	if event.Rune() == '1' && p.focusManager.ParentHasFocus() {
+	parent := p.focusManager.ParentHasFocus()
+
+	switch {
+	case action == keys.RequestBody && parent:
 		p.switchToPage(requestViewBody)
-	} else if event.Rune() == '2' && p.focusManager.ParentHasFocus() {
+	case action == keys.RequestHeaders && parent:
 		p.switchToPage(requestViewHeaders)
-	} else if event.Rune() == '3' && p.focusManager.ParentHasFocus() {
+	case action == keys.RequestAuth && parent:
 		p.switchToPage(requestViewAuthentication)
-	} else if event.Rune() == '+' && p.headers.HasFocus() {
+	case action == keys.RequestDocs && parent:
+		p.switchToPage(requestViewDocs)
+	case action == keys.HeaderAdd && p.headers.HasFocus():
 		p.showAddHeaderModal()
-	} else if event.Rune() == '-' && p.headers.HasFocus() {
+	case action == keys.HeaderRemove && p.headers.HasFocus():
 		p.removeHeader()
-	} else if event.Rune() == 'f' && p.focusManager.ParentHasFocus() {
+	case action == keys.InheritHeaders && (p.headers.HasFocus() || parent) && p.isPage(requestViewHeaders):
+		p.toggleInheritHeaders()
+	case action == keys.Format && parent:
 		p.formatBody()
-	} else {
+	case action == keys.Preview && parent && p.isPage(requestViewBody):
+		p.togglePreview()
+	default:
 		return event
 	}
 
@@ -249,11 +374,71 @@ func (p *RequestView) formatBody() {
 			return
 		}
 
+		p.state.Checkpoint("", fmt.Sprintf("format body of %s", item.Name))
 		item.RequestBody.Payload = formatted
+		p.state.SetDirty()
 		p.Reload()
 	}
 }
 
+func (p *RequestView) togglePreview() {
+	layout := &p.state.Get().Layout
+	layout.BodyPreview = !layout.BodyPreview
+	p.state.SetDirty()
+
+	p.showPreview(layout.BodyPreview)
+	p.renderPreview()
+}
+
+// showPreview shows or hides the highlighted preview alongside the body.
+func (p *RequestView) showPreview(show bool) {
+	p.bodyContent.Clear()
+	p.bodyContent.AddItem(p.body, 0, 1, true)
+
+	if show {
+		p.bodyContent.AddItem(tview.NewBox(), 1, 0, false)
+		p.bodyContent.AddItem(p.preview, 0, 1, false)
+	}
+}
+
+// renderPreview shows the body with syntax highlighting, if the preview is shown.
+func (p *RequestView) renderPreview() {
+	if !p.state.Get().Layout.BodyPreview {
+		return
+	}
+
+	body := p.currentRequestBody()
+	if body == "" {
+		p.preview.SetText(muted("No body."))
+		return
+	}
+
+	// the body is shown as-is until it can be parsed, which is often the case while it's being typed
+	text, err := parsers.GetBodyParserForContentType(p.currentContentType()).Highlight([]byte(body), theme.Get().Syntax)
+	if err != nil {
+		text = tview.Escape(body)
+	}
+
+	p.preview.SetText(text)
+}
+
+func (p *RequestView) isPage(view string) bool {
+	page, _ := p.pages.GetFrontPage()
+	return page == view
+}
+
+func (p *RequestView) toggleInheritHeaders() {
+	item := p.state.Get().ActiveItem
+	if item == nil {
+		return
+	}
+
+	p.state.Checkpoint("", fmt.Sprintf("toggle inherited headers of %s", item.Name))
+	item.InheritHeaders = !item.InheritHeaders
+	p.Reload()
+	p.state.SetDirty()
+}
+
 func (p *RequestView) removeHeader() {
 	item := p.state.Get().ActiveItem
 	if item == nil {
@@ -265,7 +450,9 @@ func (p *RequestView) removeHeader() {
 		return
 	}
 
+	p.state.Checkpoint("", fmt.Sprintf("remove header %s", key))
 	item.RemoveHeader(key)
+	p.state.SetDirty()
 	p.Reload()
 }
 
@@ -286,24 +473,40 @@ func (p *RequestView) handleBodyKeyEvent(event *tcell.EventKey) *tcell.EventKey
 
 func (p *RequestView) handleBodyChange() {
 	item := p.state.Get().ActiveItem
-	if item == nil || item.RequestBody == nil {
+	if p.reloading || item == nil || item.RequestBody == nil {
+		return
+	}
+
+	text := p.body.GetText()
+	if item.RequestBody.Payload == text {
 		return
 	}
 
-	item.RequestBody.Payload = p.body.GetText()
+	p.state.Checkpoint(fmt.Sprintf("body:%s", item.UUID), fmt.Sprintf("edit body of %s", item.Name))
+	item.RequestBody.Payload = text
+	p.state.SetDirty()
+	p.renderPreview()
 }
 
 func (p *RequestView) handleContentTypeChange(text string, index int) {
 	item := p.state.Get().ActiveItem
-	if item == nil {
+	if p.reloading || item == nil {
+		return
+	}
+
+	contentType := contentTypeOptionsToValues[text]
+	if index == 0 && item.RequestBody == nil {
+		return
+	} else if index != 0 && item.RequestBody != nil && strings.Contains(item.RequestBody.ContentType, contentType) {
 		return
 	}
 
+	p.state.Checkpoint("", fmt.Sprintf("change content type of %s", item.Name))
+	p.state.SetDirty()
+
 	if index == 0 {
 		item.RequestBody = nil
 	} else {
-		contentType := contentTypeOptionsToValues[text]
-
 		// if the request previously had no request body, initialize it now
 		// otherwise, just update the content type
 		if item.RequestBody == nil {
@@ -315,6 +518,8 @@ func (p *RequestView) handleContentTypeChange(text string, index int) {
 			item.RequestBody.ContentType = contentType
 		}
 	}
+
+	p.renderPreview()
 }
 
 func (p *RequestView) handleAddHeader(key string, value string) {
@@ -323,7 +528,9 @@ func (p *RequestView) handleAddHeader(key string, value string) {
 		return
 	}
 
+	p.state.Checkpoint("", fmt.Sprintf("add header %s", key))
 	item.AddHeader(key, value)
+	p.state.SetDirty()
 	p.hideModal()
 	p.Reload()
 }
@@ -353,6 +560,7 @@ func (p *RequestView) handleEditHeader(key string, value string) {
 		return
 	}
 
+	p.state.Checkpoint("", fmt.Sprintf("edit header %s", key))
 	newValues := strings.Split(value, headerTableSeparator)
 
 	// if the key has changed, we need to remove the existing header entry entirely
@@ -369,25 +577,75 @@ func (p *RequestView) handleEditHeader(key string, value string) {
 		}
 	}
 
+	p.state.SetDirty()
 	p.hideModal()
 	p.Reload()
 }
 
 func (p *RequestView) handleAuthenticationChange(data auth.RequestAuthentication) {
 	item := p.state.Get().ActiveItem
-	if item == nil {
+	if p.reloading || item == nil {
 		return
 	}
 
+	p.state.Checkpoint(fmt.Sprintf("auth:%s", item.UUID), fmt.Sprintf("edit authentication of %s", item.Name))
 	if data == nil {
 		item.Authentication.Data = nil
 	} else if basic, ok := data.(*auth.BasicAuthentication); ok && basic != nil {
 		item.Authentication.Data = basic
 	} else if oauth2, ok := data.(*auth.OAuth2RequestAuthentication); ok && oauth2 != nil {
 		item.Authentication.Data = oauth2
+	} else if bearer, ok := data.(*auth.BearerAuthentication); ok && bearer != nil {
+		item.Authentication.Data = bearer
+	} else if inherited, ok := data.(*auth.InheritedAuthentication); ok && inherited != nil {
+		item.Authentication.Data = inherited
+	}
+
+	p.state.SetDirty()
+}
+
+func (p *RequestView) handleTagsChange(text string) {
+	item := p.state.Get().ActiveItem
+	if p.reloading || item == nil {
+		return
+	}
+
+	tags := parseTags(text)
+	if strings.Join(tags, ",") == strings.Join(item.Tags, ",") {
+		return
+	}
+
+	p.state.Checkpoint(fmt.Sprintf("tags:%s", item.UUID), fmt.Sprintf("edit tags of %s", item.Name))
+	item.Tags = tags
+	p.state.SetDirty()
+}
+
+func (p *RequestView) handleDescriptionChange() {
+	item := p.state.Get().ActiveItem
+	if p.reloading || item == nil {
+		return
+	}
+
+	text := p.description.GetText()
+	if item.Description == text {
+		return
 	}
 
+	p.state.Checkpoint(fmt.Sprintf("docs:%s", item.UUID), fmt.Sprintf("edit description of %s", item.Name))
+	item.Description = text
 	p.state.SetDirty()
+	p.renderDocs(item)
+}
+
+// renderDocs shows the formatted description of the item.
+func (p *RequestView) renderDocs(item *state.CollectionItem) {
+	if item.Description == "" {
+		p.docs.SetText(muted("No description."))
+		return
+	}
+
+	p.docs.SetText(util.RenderMarkdown(item.Description))
+	p.docs.ScrollToBeginning()
 }
 
 func (p *RequestView) hideModal() {
@@ -399,13 +657,19 @@ func (p *RequestView) hideModal() {
 }
 
 func (p *RequestView) currentContentType() string {
+	item := p.state.Get().ActiveItem
+
+	// the request body's own content type is more specific than the option it's shown as, such as text/xml for XML
+	if item != nil && item.RequestBody != nil && item.RequestBody.ContentType != "" {
+		return item.RequestBody.ContentType
+	}
+
 	// if the body dropdown has a valid value selected, use that as the content type
 	i, contentType := p.contentType.GetCurrentOption()
 	if i != 0 {
 		return contentTypeOptionsToValues[contentType]
 	}
 
-	item := p.state.Get().ActiveItem
 	if item == nil {
 		return ""
 	}
@@ -438,63 +702,33 @@ func (p *RequestView) currentHeader() (string, []string, error) {
 	key := p.headers.GetCell(row, 0)
 	value := p.headers.GetCell(row, 1)
 
+	// inherited headers can only be changed on the group that defines them
+	if key.GetReference() == headerTableInherited {
+		return "", nil, errors.New("header is inherited")
+	}
+
 	return key.Text, strings.Split(value.Text, headerTableSeparator), nil
 }
 
 func (p *RequestView) keyboardSequences() []events.StatusBarContextChangeSequence {
 	var seq []events.StatusBarContextChangeSequence
 	page, _ := p.pages.GetFrontPage()
+	km := keys.Get()
 
 	switch page {
 	case requestViewBody:
-		seq = []events.StatusBarContextChangeSequence{
-			{
-				Label:       "Headers",
-				KeySequence: "2",
-			},
-			{
-				Label:       "Authentication",
-				KeySequence: "3",
-			},
-			{
-				Label:       "Format",
-				KeySequence: "f",
-			},
-		}
+		seq = km.Sequences(keys.RequestHeaders, keys.RequestAuth, keys.RequestDocs, keys.Format, keys.Preview)
 	case requestViewHeaders:
-		seq = []events.StatusBarContextChangeSequence{
-			{
-				Label:       "Body",
-				KeySequence: "1",
-			},
-			{
-				Label:       "Authentication",
-				KeySequence: "3",
-			},
-			{
-				Label:       "Add header",
-				KeySequence: "+",
-			},
-			{
-				Label:       "Remove header",
-				KeySequence: "-",
-			},
-			{
-				Label:       "Edit header",
-				KeySequence: "⏎",
-			},
-		}
+		seq = km.Sequences(keys.RequestBody, keys.RequestAuth, keys.RequestDocs, keys.HeaderAdd, keys.HeaderRemove)
+		seq = append(seq, events.StatusBarContextChangeSequence{
+			Label:       "Edit header",
+			KeySequence: "⏎",
+		})
+		seq = append(seq, km.Sequences(keys.InheritHeaders)...)
 	case requestViewAuthentication:
-		seq = []events.StatusBarContextChangeSequence{
-			{
-				Label:       "Body",
-				KeySequence: "1",
-			},
-			{
-				Label:       "Headers",
-				KeySequence: "2",
-			},
-		}
+		seq = km.Sequences(keys.RequestBody, keys.RequestHeaders, keys.RequestDocs, keys.RevealSecrets)
+	case requestViewDocs:
+		seq = km.Sequences(keys.RequestBody, keys.RequestHeaders, keys.RequestAuth)
 	default:
 		break
 	}
@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"github.com/gdamore/tcell/v2"
 	"github.com/mbpolan/lull/internal/events"
+	"github.com/mbpolan/lull/internal/keys"
 	"github.com/mbpolan/lull/internal/parsers"
 	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/theme"
 	"github.com/mbpolan/lull/internal/util"
 	"github.com/rivo/tview"
 	"strings"
@@ -23,6 +25,8 @@ type ResponseView struct {
 	status       *tview.TextView
 	metrics      *tview.TextView
 	body         *tview.TextView
+	table        *tview.Table
+	bodyContent  *tview.Flex
 	headers      *tview.Table
 	focusHolder  *tview.TextView
 	focusManager *util.FocusManager
@@ -64,26 +68,38 @@ func (p *ResponseView) Reload() {
 		p.status.SetText("")
 		p.metrics.SetText("")
 		p.body.SetText("")
+		p.showTable(false)
 	} else {
 		resp := res.Response
 		body := ""
 
+		table := false
+
 		// get a parser that's most suitable for the response and format the body
 		if res.PayloadError != nil {
-			body = fmt.Sprintf("[red]%s", res.PayloadError.Error())
+			body = errorText(tview.Escape(res.PayloadError.Error()))
 		} else {
 			parser := parsers.GetBodyParser(resp)
-			parsedBody, err := parser.ParseBytes(res.Payload)
+			parsedBody, err := parser.Highlight(res.Payload, theme.Get().Syntax)
 			if err != nil {
-				body = fmt.Sprintf("[red]%s", string(res.Payload))
+				body = errorText(tview.Escape(string(res.Payload)))
 			} else {
 				body = parsedBody
 			}
+
+			// content such as csv is shown as a table instead, if it can be read as one
+			if tp, ok := parser.(parsers.TableParser); ok {
+				if headers, rows, err := tp.ParseTable(res.Payload); err == nil {
+					p.setTable(headers, rows)
+					table = true
+				}
+			}
 		}
 
 		p.status.SetText(p.statusLine(resp.StatusCode, resp.Status))
 		p.metrics.SetText(util.FormatDuration(res.Duration))
 		p.body.SetText(body)
+		p.showTable(table)
 
 		// build header table
 		row := 1
@@ -128,7 +144,13 @@ func (p *ResponseView) build() {
 	p.body.SetDynamicColors(true)
 	p.headers = tview.NewTable()
 
-	p.pages.AddAndSwitchToPage(responseViewBody, p.body, true)
+	p.table = tview.NewTable()
+	p.table.SetFixed(1, 0)
+	p.table.SetSelectable(true, false)
+
+	p.bodyContent = tview.NewFlex()
+
+	p.pages.AddAndSwitchToPage(responseViewBody, p.bodyContent, true)
 	p.pages.AddPage(responseViewHeaders, p.headers, true, false)
 
 	p.focusManager = util.NewFocusManager(p, GetApplication(), events.Dispatcher(), p.focusHolder, p.focusHolder, p.body)
@@ -136,6 +158,46 @@ func (p *ResponseView) build() {
 	p.focusManager.SetHandler(p.handleKeyEvent)
 
 	p.flex.SetInputCapture(p.focusManager.HandleKeyEvent)
+	p.showTable(false)
+}
+
+// setTable fills the table with the headers and rows of the body.
+func (p *ResponseView) setTable(headers []string, rows [][]string) {
+	p.table.Clear()
+
+	for i, h := range headers {
+		cell := tview.NewTableCell(tview.Escape(h))
+		cell.SetTextColor(tview.Styles.TertiaryTextColor)
+		cell.SetSelectable(false)
+		p.table.SetCell(0, i, cell)
+	}
+
+	for i, r := range rows {
+		for j, c := range r {
+			p.table.SetCellSimple(i+1, j, tview.Escape(c))
+		}
+	}
+
+	p.table.ScrollToBeginning()
+	p.table.Select(1, 0)
+}
+
+// showTable shows the body as a table instead of as text.
+func (p *ResponseView) showTable(show bool) {
+	// don't leave the focus on a view that is no longer shown
+	if focus := GetApplication().GetFocus(); show && focus == p.body || !show && focus == p.table {
+		GetApplication().SetFocus(p.focusHolder)
+	}
+
+	p.bodyContent.Clear()
+
+	if show {
+		p.bodyContent.AddItem(p.table, 0, 1, true)
+		p.focusManager.SetPrimitives(p.focusHolder, p.table)
+	} else {
+		p.bodyContent.AddItem(p.body, 0, 1, true)
+		p.focusManager.SetPrimitives(p.focusHolder, p.body)
+	}
 }
 
 func (p *ResponseView) setTitle() {
@@ -152,16 +214,32 @@ func (p *ResponseView) setTitle() {
 func (p *ResponseView) switchToPage(view string) {
 	p.pages.SwitchToPage(view)
 	p.setTitle()
+
+	if layout := &p.state.Get().Layout; layout.ResponsePage != view {
+		layout.ResponsePage = view
+		p.state.SetDirty()
+	}
+}
+
+// RestoreLayout shows the page that was last selected.
+func (p *ResponseView) RestoreLayout() {
+	if page := p.state.Get().Layout.ResponsePage; p.pages.HasPage(page) {
+		p.pages.SwitchToPage(page)
+		p.setTitle()
+	}
 }
 
 func (p *ResponseView) handleKeyEvent(event *tcell.EventKey) *tcell.EventKey {
-	if event.Rune() == '1' {
+	action, _ := keys.Get().Action(keys.ContextResponse, event)
+
+	switch action {
+	case keys.ResponseBody:
 		p.switchToPage(responseViewBody)
 		p.postKeyboardSequences()
-	} else if event.Rune() == '2' {
+	case keys.ResponseHeaders:
 		p.switchToPage(responseViewHeaders)
 		p.postKeyboardSequences()
-	} else {
+	default:
 		return event
 	}
 
@@ -169,18 +247,8 @@ func (p *ResponseView) handleKeyEvent(event *tcell.EventKey) *tcell.EventKey {
 }
 
 func (p *ResponseView) statusLine(code int, status string) string {
-	color := ""
-
 	// choose a text color based on the "severity" of the status code
-	if code >= 200 && code < 300 {
-		color = "green"
-	} else if code >= 300 && code < 400 {
-		color = "yellow"
-	} else if code >= 400 && code < 500 {
-		color = "orange"
-	} else if code >= 500 && code < 599 {
-		color = "red"
-	}
+	color := theme.Get().StatusCode(code)
 
 	// take the status text from the response or create our own if there is none
 	// TODO: parse the status text if it exists and remove duplicate status codes
@@ -189,7 +257,7 @@ func (p *ResponseView) statusLine(code int, status string) string {
 		statusText = p.statusTextForCode(code)
 	}
 
-	return fmt.Sprintf("[%s]%d %s", color, code, statusText)
+	return theme.Colorize(color, fmt.Sprintf("%d %s", code, statusText))
 }
 
 func (p *ResponseView) statusTextForCode(code int) string {
@@ -220,19 +288,9 @@ func (p *ResponseView) keyboardSequences() []events.StatusBarContextChangeSequen
 
 	switch page {
 	case responseViewBody:
-		seq = []events.StatusBarContextChangeSequence{
-			{
-				Label:       "Headers",
-				KeySequence: "2",
-			},
-		}
+		seq = keys.Get().Sequences(keys.ResponseHeaders)
 	case responseViewHeaders:
-		seq = []events.StatusBarContextChangeSequence{
-			{
-				Label:       "Body",
-				KeySequence: "1",
-			},
-		}
+		seq = keys.Get().Sequences(keys.ResponseBody)
 	default:
 		break
 	}
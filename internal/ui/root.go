@@ -5,11 +5,19 @@ import (
 	"errors"
 	"fmt"
 	"github.com/gdamore/tcell/v2"
+	"github.com/mbpolan/lull/internal/config"
 	"github.com/mbpolan/lull/internal/events"
+	"github.com/mbpolan/lull/internal/formats"
+	"github.com/mbpolan/lull/internal/keys"
 	"github.com/mbpolan/lull/internal/network"
 	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/mbpolan/lull/internal/system"
 	"github.com/mbpolan/lull/internal/util"
 	"github.com/rivo/tview"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
@@ -21,10 +29,37 @@ const (
 	rootPageModal string = "modal"
 )
 
+// maxReportWarnings is the maximum number of warnings to show after an import or export.
+const maxReportWarnings = 8
+
+// defaultCollectionWidth, minCollectionWidth and maxCollectionWidth are the widths of the collection panel, in cells.
+const defaultCollectionWidth = 25
+const minCollectionWidth = 15
+const maxCollectionWidth = 80
+
+// collectionWidthStep and requestShareStep are how much panels are resized by with each key press.
+const collectionWidthStep = 2
+const requestShareStep = 5
+
+const (
+	transferFormatPostman = "Postman v2.1"
+	transferFormatOpenAPI = "OpenAPI 3 / Swagger 2"
+	transferFormatHAR     = "HAR 1.2"
+	transferFormatHARFull = "HAR 1.2 with responses"
+	transferFormatHTTP    = "HTTP file (.http)"
+	transferFormatHTTPDir = "Directory of .http files (linked)"
+)
+
+var importFormats = []string{transferFormatPostman, transferFormatOpenAPI, transferFormatHAR, transferFormatHARFull,
+	transferFormatHTTP, transferFormatHTTPDir}
+var historyFormats = []string{transferFormatHAR}
+var exportFormats = []string{transferFormatPostman, transferFormatHTTP}
+
 // Root is a top-level container for all application UI components.
 type Root struct {
 	pages        *tview.Pages
 	flex         *tview.Flex
+	main         *tview.Flex
 	collection   *Collection
 	content      *Content
 	StatusBar    *StatusBar
@@ -33,6 +68,17 @@ type Root struct {
 	lastFocus    tview.Primitive
 	network      *network.Manager
 	state        *state.Manager
+	workspaces   WorkspaceSwitcher
+	cutItem      *state.CollectionItem
+	run          *collectionRun
+}
+
+// collectionRun is a run of the requests in a group, which are sent one after another.
+type collectionRun struct {
+	group   *state.CollectionItem
+	pending []*state.CollectionItem
+	total   int
+	failed  int
 }
 
 // NewRoot returns a new Root instance.
@@ -44,6 +90,8 @@ func NewRoot(app *tview.Application, stateManager *state.Manager, buildMeta *uti
 	r.currentModal = ""
 	r.network = network.NewNetworkManager(r.handleRequestFinished)
 	r.state = stateManager
+	r.state.SetConflictHandler(r.handleConflict)
+	r.state.SetReloadHandler(r.handleExternalReload)
 	r.build()
 
 	events.Dispatcher().Subscribe(r, []events.Code{events.EventNavigateRight, events.EventNavigateLeft})
@@ -51,6 +99,12 @@ func NewRoot(app *tview.Application, stateManager *state.Manager, buildMeta *uti
 	return r
 }
 
+// SetWorkspaceSwitcher sets the switcher used to change the open workspace. If no switcher is set, workspaces cannot
+// be changed while the application is running.
+func (r *Root) SetWorkspaceSwitcher(switcher WorkspaceSwitcher) {
+	r.workspaces = switcher
+}
+
 // GetApplication returns the shared instance of tview.Application.
 func GetApplication() *tview.Application {
 	return application
@@ -88,25 +142,19 @@ func (r *Root) build() {
 	r.StatusBar = NewStatusBar()
 
 	// arrange the collection and content in a flex layout
-	mc := tview.NewFlex()
-	mc.AddItem(r.collection.Widget(), 25, 0, false)
-	mc.AddItem(r.content.Widget(), 0, 1, true)
+	r.main = tview.NewFlex()
+	r.main.AddItem(r.collection.Widget(), r.collectionWidth(), 0, false)
+	r.main.AddItem(r.content.Widget(), 0, 1, true)
 
 	// arrange the main content flex layout and the status bar in a parent flex
 	r.flex = tview.NewFlex()
 	r.flex.SetDirection(tview.FlexRow)
-	r.flex.AddItem(mc, 0, 1, true)
+	r.flex.AddItem(r.main, 0, 1, true)
 	r.flex.AddItem(r.StatusBar.Widget(), 1, 0, false)
 
 	r.flex.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
-		if event.Modifiers()&tcell.ModCtrl > 0 {
-			if r.handleControlKeyAction(event.Key(), event.Rune()) {
-				return nil
-			}
-		} else if event.Modifiers()&tcell.ModShift == tcell.ModShift {
-			if r.handleShiftKeyAction(event.Key(), event.Rune()) {
-				return nil
-			}
+		if action, ok := keys.Get().Action(keys.ContextGlobal, event); ok && r.handleKeyAction(action) {
+			return nil
 		}
 
 		return event
@@ -119,20 +167,43 @@ func (r *Root) build() {
 	r.content.SetFocus(ContentURLBox)
 }
 
-func (r *Root) handleControlKeyAction(code tcell.Key, key rune) bool {
-	switch code {
-	case tcell.KeyCtrlA:
+// handleKeyAction performs a global action, returning true if the action applied.
+func (r *Root) handleKeyAction(action keys.Action) bool {
+	switch action {
+	case keys.About:
 		r.showAboutModal()
-	case tcell.KeyCtrlL:
+	case keys.FocusCollection:
 		r.collection.SetFocus()
-	case tcell.KeyCtrlR:
+	case keys.FocusRequest:
 		r.content.SetFocus(ContentRequestBody)
-	case tcell.KeyCtrlY:
+	case keys.FocusResponse:
 		r.content.SetFocus(ContentResponseBody)
-	case tcell.KeyCtrlG:
+	case keys.FocusLeft:
+		if !r.collection.Widget().HasFocus() {
+			r.collection.SetFocus()
+		}
+	case keys.FocusRight:
+		if !r.content.Widget().HasFocus() {
+			r.content.SetFocus(ContentURLBox)
+		}
+	case keys.Send:
 		r.sendCurrentRequest()
-	case tcell.KeyCtrlS:
+	case keys.Save:
 		r.showSaveCurrentRequest()
+	case keys.History:
+		r.handleExportHistory()
+	case keys.Workspaces:
+		r.handleSwitchWorkspace()
+	case keys.Find:
+		r.showFinder()
+	case keys.Undo:
+		r.undo()
+	case keys.Redo:
+		r.redo()
+	case keys.Shrink:
+		return r.resizeFocused(-1)
+	case keys.Grow:
+		return r.resizeFocused(1)
 	default:
 		return false
 	}
@@ -140,23 +211,44 @@ func (r *Root) handleControlKeyAction(code tcell.Key, key rune) bool {
 	return true
 }
 
-func (r *Root) handleShiftKeyAction(code tcell.Key, key rune) bool {
-	switch code {
-	case tcell.KeyRight:
-		if !r.content.Widget().HasFocus() {
-			r.content.SetFocus(ContentURLBox)
-		}
-	case tcell.KeyLeft:
-		if !r.collection.Widget().HasFocus() {
-			r.collection.SetFocus()
-		}
-	default:
+// resizeFocused resizes the panel on either side of the divider next to the focused panel, returning false if the
+// focused panel cannot be resized.
+func (r *Root) resizeFocused(delta int) bool {
+	if r.collection.Widget().HasFocus() {
+		r.resizeCollection(delta * collectionWidthStep)
+	} else if r.content.HasFocus(ContentRequestBody) || r.content.HasFocus(ContentResponseBody) {
+		r.content.ResizeRequest(delta * requestShareStep)
+	} else {
 		return false
 	}
 
 	return true
 }
 
+// resizeCollection grows or shrinks the collection panel by delta cells.
+func (r *Root) resizeCollection(delta int) {
+	layout := &r.state.Get().Layout
+	layout.CollectionWidth = util.Max(minCollectionWidth, util.Min(maxCollectionWidth, r.collectionWidth()+delta))
+	r.state.SetDirty()
+
+	r.main.ResizeItem(r.collection.Widget(), layout.CollectionWidth, 0)
+}
+
+// collectionWidth returns the width of the collection panel, in cells.
+func (r *Root) collectionWidth() int {
+	if width := r.state.Get().Layout.CollectionWidth; width > 0 {
+		return width
+	}
+
+	return defaultCollectionWidth
+}
+
+// restoreLayout applies the saved layout to all panels.
+func (r *Root) restoreLayout() {
+	r.main.ResizeItem(r.collection.Widget(), r.collectionWidth(), 0)
+	r.content.RestoreLayout()
+}
+
 func (r *Root) pathToSelectedCollectionItemGroup() []*state.CollectionItem {
 	item := r.state.Get().SelectedItem
 	if item == nil {
@@ -178,17 +270,474 @@ func (r *Root) handleCollectionItemAction(action CollectionItemAction, item *sta
 		r.handleRenameSelectedItem(item)
 	case CollectionItemAdd:
 		r.handleAddItem(item)
+	case CollectionItemAddGroup:
+		r.handleAddGroup(item)
 	case CollectionItemDelete:
 		r.handleDeleteSelectedItem(item)
 	case CollectionItemClone:
 		r.handleCloneSelectedItem(item)
 	case CollectionItemOpen:
 		r.setCurrentRequest(item)
+	case CollectionItemAuthentication:
+		r.handleEditGroupAuthentication(item)
+	case CollectionItemHeaders:
+		r.handleEditGroupHeaders(item)
+	case CollectionItemDocs:
+		r.handleEditGroupDocs(item)
+	case CollectionItemVariables:
+		r.handleEditGroupVariables(item)
+	case CollectionItemImport:
+		r.handleImport(item)
+	case CollectionItemExport:
+		r.handleExport(item)
+	case CollectionItemSync:
+		r.syncItems(item)
+	case CollectionItemCut:
+		r.cutItem = item
+		r.StatusBar.ShowMessage(fmt.Sprintf("Cut %s", item.Name))
+	case CollectionItemPaste:
+		r.pasteItem(item)
+	case CollectionItemMoveUp:
+		r.moveItemAmongSiblings(item, -1)
+	case CollectionItemMoveDown:
+		r.moveItemAmongSiblings(item, 1)
+	case CollectionItemMoveToGroup:
+		r.handleMoveToGroup(item)
+	case CollectionItemRun:
+		r.handleRunGroup(item)
+	}
+}
+
+func (r *Root) handleImport(item *state.CollectionItem) {
+	group := r.groupForItem(item)
+	text := fmt.Sprintf("Items will be imported into %s", highlight(tview.Escape(group.Name)))
+
+	m := NewTransferModal("Import", text, importFormats, func(format string, path string) {
+		r.importItems(group, format, path)
+	}, r.hideCurrentModal)
+
+	r.showModal(m.Widget())
+}
+
+func (r *Root) handleExport(item *state.CollectionItem) {
+	group := r.groupForItem(item)
+	text := fmt.Sprintf("Export group %s", highlight(tview.Escape(group.Name)))
+
+	m := NewTransferModal("Export", text, exportFormats, func(format string, path string) {
+		r.exportItems(group, format, path)
+	}, r.hideCurrentModal)
+
+	r.showModal(m.Widget())
+}
+
+func (r *Root) importItems(group *state.CollectionItem, format string, path string) {
+	path, err := filepath.Abs(system.ExpandPath(path))
+	if err != nil {
+		r.showError(fmt.Sprintf("Invalid path: %s", err))
+		return
+	}
+
+	var imported *state.CollectionItem
+	var report *formats.Report
+
+	// directories are linked rather than read as a single file
+	if format == transferFormatHTTPDir {
+		imported, report, err = formats.OpenHTTPDirectory(path)
+		if err != nil {
+			r.showError(fmt.Sprintf("Could not open directory: %s", err))
+			return
+		}
+
+		r.attachImportedItem(group, imported, report)
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not read file: %s", err))
+		return
+	}
+
+	switch format {
+	case transferFormatPostman:
+		imported, report, err = formats.ImportPostman(data)
+	case transferFormatOpenAPI:
+		imported, report, err = formats.ImportOpenAPI(data, path)
+	case transferFormatHAR, transferFormatHARFull:
+		imported, report, err = formats.ImportHAR(data, path, format == transferFormatHARFull)
+	case transferFormatHTTP:
+		imported, report = formats.ImportHTTPFile(data, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	default:
+		err = fmt.Errorf("unsupported format %s", format)
+	}
+
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not import file: %s", err))
+		return
+	}
+
+	r.attachImportedItem(group, imported, report)
+}
+
+// attachImportedItem adds an imported item to a group and selects it.
+func (r *Root) attachImportedItem(group *state.CollectionItem, imported *state.CollectionItem, report *formats.Report) {
+	r.state.Checkpoint("", fmt.Sprintf("import %s", imported.Name))
+	imported.Parent = group
+	group.AddChild(imported)
+
+	r.state.Get().SelectedItem = imported
+	r.state.SetDirty()
+	r.collection.Reload()
+
+	r.showReport("Import", fmt.Sprintf("Imported %s.", imported.Name), report)
+}
+
+func (r *Root) exportItems(group *state.CollectionItem, format string, path string) {
+	var data []byte
+	var report *formats.Report
+	var err error
+
+	switch format {
+	case transferFormatPostman:
+		data, report, err = formats.ExportPostman(group)
+	case transferFormatHTTP:
+		data, report, err = formats.ExportHTTPFile(group)
+	default:
+		err = fmt.Errorf("unsupported format %s", format)
+	}
+
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not export group: %s", err))
+		return
+	}
+
+	// exported files may contain secrets, so only allow the current user to read them
+	if err := os.WriteFile(system.ExpandPath(path), data, 0600); err != nil {
+		r.showError(fmt.Sprintf("Could not write file: %s", err))
+		return
+	}
+
+	r.showReport("Export", fmt.Sprintf("Exported %s.", group.Name), report)
+}
+
+func (r *Root) handleExportHistory() {
+	text := fmt.Sprintf("Export %s requests sent in this session", highlight(fmt.Sprint(len(r.state.Get().History))))
+
+	m := NewTransferModal("Export History", text, historyFormats, r.exportHistory, r.hideCurrentModal)
+	r.showModal(m.Widget())
+}
+
+func (r *Root) exportHistory(format string, path string) {
+	var data []byte
+	var report *formats.Report
+	var err error
+
+	switch format {
+	case transferFormatHAR:
+		data, report, err = formats.ExportHAR(r.state.Get().History, r.buildMeta.Version)
+	default:
+		err = fmt.Errorf("unsupported format %s", format)
+	}
+
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not export history: %s", err))
+		return
 	}
+
+	// recorded requests may contain credentials, so only allow the current user to read them
+	if err := os.WriteFile(system.ExpandPath(path), data, 0600); err != nil {
+		r.showError(fmt.Sprintf("Could not write file: %s", err))
+		return
+	}
+
+	r.showReport("Export History", "Exported request history.", report)
+}
+
+func (r *Root) handleSwitchWorkspace() {
+	if r.workspaces == nil {
+		return
+	}
+
+	names, err := r.workspaces.List()
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not list workspaces: %s", err))
+		return
+	}
+
+	m := NewWorkspaceModal(r.workspaces.Current(), names, r.switchWorkspace, r.hideCurrentModal)
+	r.showModal(m.Widget())
+}
+
+func (r *Root) switchWorkspace(name string) {
+	r.hideCurrentModal()
+	if name == r.workspaces.Current() {
+		return
+	}
+
+	// suspend the ui in case the passphrase for the workspace's secrets needs to be entered on the terminal
+	var err error
+	GetApplication().Suspend(func() {
+		err = r.workspaces.Switch(name)
+	})
+
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not open workspace: %s", err))
+		return
+	}
+
+	r.restoreLayout()
+	r.collection.Reload()
+	r.content.Reload()
+}
+
+// syncItems updates the nearest group containing the item that was imported from an OpenAPI document.
+func (r *Root) syncItems(item *state.CollectionItem) {
+	var group *state.CollectionItem
+	candidates := append(item.Ancestors(), item)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		if _, ok := formats.OpenAPIDocumentPath(candidates[i]); ok {
+			group = candidates[i]
+			break
+		}
+	}
+
+	if group == nil {
+		r.showError("This item was not imported from an OpenAPI document.")
+		return
+	}
+
+	path, _ := formats.OpenAPIDocumentPath(group)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not read file: %s", err))
+		return
+	}
+
+	r.state.Checkpoint("", fmt.Sprintf("sync %s", group.Name))
+	report, err := formats.SyncOpenAPI(group, data)
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not sync group: %s", err))
+		return
+	}
+
+	// items removed during the sync are detached from the collection, so they can no longer be active or selected
+	st := r.state.Get()
+	if st.ActiveItem != nil && st.ActiveItem.Parent == nil && st.ActiveItem != st.Collection {
+		st.ActiveItem = nil
+	}
+
+	if st.SelectedItem != nil && st.SelectedItem.Parent == nil && st.SelectedItem != st.Collection {
+		st.SelectedItem = nil
+	}
+
+	st.EnsureDefaultItems()
+	r.state.SetDirty()
+	r.collection.Reload()
+	r.content.Reload()
+
+	r.showReport("Sync", fmt.Sprintf("Synced %s with %s.", group.Name, path), report)
+}
+
+// showReport replaces the current modal with one summarizing the outcome of an import or export.
+func (r *Root) showReport(title string, summary string, report *formats.Report) {
+	text := summary
+	if !report.Empty() {
+		text = fmt.Sprintf("%s The following could not be mapped:", text)
+
+		for i, w := range report.Warnings {
+			if i == maxReportWarnings {
+				text = fmt.Sprintf("%s\n...and %d more", text, len(report.Warnings)-maxReportWarnings)
+				break
+			}
+
+			text = fmt.Sprintf("%s\n- %s", text, w)
+		}
+	}
+
+	m := NewAlertModal(title, text, "OK", r.hideCurrentModal)
+	r.hideCurrentModal()
+	r.showModal(m.Widget())
+}
+
+// showError replaces the current modal with one showing an error message.
+func (r *Root) showError(text string) {
+	m := NewAlertModal("Error", text, "OK", r.hideCurrentModal)
+	r.hideCurrentModal()
+	r.showModal(m.Widget())
+}
+
+// groupForItem returns the item if it is a group, or the group that contains it otherwise.
+func (r *Root) groupForItem(item *state.CollectionItem) *state.CollectionItem {
+	if item.IsGroup || item.Parent == nil {
+		return item
+	}
+
+	return item.Parent
+}
+
+func (r *Root) handleEditGroupAuthentication(item *state.CollectionItem) {
+	group := r.groupForItem(item)
+
+	m := NewAuthModal(func(data auth.RequestAuthentication) {
+		r.state.Checkpoint("", fmt.Sprintf("edit authentication of %s", group.Name))
+		group.Authentication.Data = data
+		r.state.SetDirty()
+
+		// the active item may inherit authentication from this group
+		r.content.Reload()
+		r.hideCurrentModal()
+	}, r.hideCurrentModal)
+
+	m.Set(group)
+	r.showModal(m.Widget())
+}
+
+func (r *Root) handleEditGroupHeaders(item *state.CollectionItem) {
+	group := r.groupForItem(item)
+	title := fmt.Sprintf("Headers for %s", group.Name)
+
+	m := NewHeadersModal(title, func(headers map[string][]string, inherit bool) {
+		r.state.Checkpoint("", fmt.Sprintf("edit headers of %s", group.Name))
+		group.Headers = headers
+		group.InheritHeaders = inherit
+		r.state.SetDirty()
+
+		// the active item may inherit headers from this group
+		r.content.Reload()
+		r.hideCurrentModal()
+	}, r.hideCurrentModal)
+
+	m.Set(group.Headers, group.InheritHeaders)
+	r.showModal(m.Widget())
+}
+
+func (r *Root) handleEditGroupDocs(item *state.CollectionItem) {
+	group := r.groupForItem(item)
+	title := fmt.Sprintf("Docs for %s", group.Name)
+
+	m := NewDocsModal(title, func(tags []string, description string) {
+		r.state.Checkpoint("", fmt.Sprintf("edit docs of %s", group.Name))
+		group.Tags = tags
+		group.Description = description
+		r.state.SetDirty()
+
+		r.collection.Reload()
+		r.hideCurrentModal()
+	}, r.hideCurrentModal)
+
+	m.Set(group.Tags, group.Description)
+	r.showModal(m.Widget())
+}
+
+func (r *Root) handleEditGroupVariables(item *state.CollectionItem) {
+	group := r.groupForItem(item)
+	title := fmt.Sprintf("Variables for %s", group.Name)
+
+	m := NewVariablesModal(title, func(variables map[string]string, secret []string) {
+		r.state.Checkpoint("", fmt.Sprintf("edit variables of %s", group.Name))
+		group.Variables = nil
+		group.SecretVariables = nil
+		if len(variables) > 0 {
+			group.Variables = variables
+		}
+
+		for _, name := range secret {
+			group.SetSecretVariable(name, true)
+		}
+
+		r.state.SetDirty()
+
+		// the active item may use these variables
+		r.content.Reload()
+		r.hideCurrentModal()
+	}, r.hideCurrentModal)
+
+	m.Set(group.Variables, group.SecretVariables)
+	r.showModal(m.Widget())
+}
+
+// pasteItem moves the item that was previously cut into the group containing the given item.
+func (r *Root) pasteItem(item *state.CollectionItem) {
+	// the cut item may have since been deleted, or replaced by undoing a change
+	cut := r.cutItem
+	if cut == nil || !cut.IsDescendentOf(r.state.Get().Collection) {
+		r.cutItem = nil
+		util.ConsoleBell()
+		return
+	}
+
+	r.moveItem(cut, r.groupForItem(item))
+	r.cutItem = nil
+}
+
+func (r *Root) handleMoveToGroup(item *state.CollectionItem) {
+	if item.Parent == nil {
+		util.ConsoleBell()
+		return
+	}
+
+	// a group cannot be moved into itself or any of its descendents
+	var groups []*state.CollectionItem
+	for _, g := range r.state.Get().Groups() {
+		if g != item && !g.IsDescendentOf(item) {
+			groups = append(groups, g)
+		}
+	}
+
+	text := fmt.Sprintf("Move %s to another group", highlight(tview.Escape(item.Name)))
+	m := NewGroupModal("Move Item", text, groups, func(group *state.CollectionItem) {
+		r.hideCurrentModal()
+		r.moveItem(item, group)
+	}, r.hideCurrentModal)
+
+	r.showModal(m.Widget())
+}
+
+// moveItem moves an item to the end of a group and selects it.
+func (r *Root) moveItem(item *state.CollectionItem, group *state.CollectionItem) {
+	if item.Parent == group {
+		return
+	}
+
+	// check that the item can be moved before recording an undo step for it
+	if err := r.state.Get().ValidateMoveCollectionItem(item, group); err != nil {
+		r.showError(fmt.Sprintf("Could not move item: %s", err))
+		return
+	}
+
+	r.state.Checkpoint("", fmt.Sprintf("move %s to %s", item.Name, group.Name))
+	if err := r.state.Get().MoveCollectionItem(item, group); err != nil {
+		r.showError(fmt.Sprintf("Could not move item: %s", err))
+		return
+	}
+
+	r.state.Get().SelectedItem = item
+	r.state.SetDirty()
+	r.collection.Reload()
+	r.content.Reload()
+}
+
+// moveItemAmongSiblings moves an item up or down within its group.
+func (r *Root) moveItemAmongSiblings(item *state.CollectionItem, offset int) {
+	if item.Parent == nil {
+		util.ConsoleBell()
+		return
+	}
+
+	siblings := item.Parent.Children
+	if (offset < 0 && siblings[0] == item) || (offset > 0 && siblings[len(siblings)-1] == item) {
+		util.ConsoleBell()
+		return
+	}
+
+	r.state.Checkpoint("", fmt.Sprintf("reorder %s", item.Name))
+	item.Parent.MoveChild(item, offset)
+	r.state.SetDirty()
+	r.collection.Reload()
 }
 
 func (r *Root) handleRenameSelectedItem(item *state.CollectionItem) {
-	text := fmt.Sprintf("Current request name: [yellow]%s", item.Name)
+	text := fmt.Sprintf("Current request name: %s", highlight(tview.Escape(item.Name)))
 	m := NewTextInputModal("Rename Item", text, "New Name", r.renameSelectedItem, r.hideCurrentModal)
 
 	r.showModal(m.Widget())
@@ -219,24 +768,66 @@ func (r *Root) handleAddItem(item *state.CollectionItem) {
 		}
 	}
 
-	newItem := state.NewCollectionRequest(name, "GET", "", parent)
+	r.state.Checkpoint("", fmt.Sprintf("add %s", name))
+	newItem := newRequest(name, parent)
 	parent.AddChild(newItem)
 
 	r.state.Get().SelectedItem = newItem
 	r.state.Get().ActiveItem = newItem
+	r.state.SetDirty()
 	r.collection.Reload()
 	r.content.Reload()
 }
 
+// newRequest returns a new request with the configured default method and headers.
+func newRequest(name string, parent *state.CollectionItem) *state.CollectionItem {
+	cfg := config.Get().Request
+	method := cfg.Methods[0]
+	for _, m := range cfg.Methods {
+		if m == "GET" {
+			method = m
+		}
+	}
+
+	item := state.NewCollectionRequest(name, method, "", parent)
+	for k, v := range cfg.Headers {
+		item.AddHeader(k, v)
+	}
+
+	return item
+}
+
+func (r *Root) handleAddGroup(item *state.CollectionItem) {
+	parent := r.groupForItem(item)
+	text := fmt.Sprintf("Group will be created under %s", highlight(tview.Escape(groupPath(parent))))
+
+	m := NewTextInputModal("New Group", text, "Name", func(name string) {
+		r.addGroup(parent, name)
+	}, r.hideCurrentModal)
+
+	r.showModal(m.Widget())
+}
+
+func (r *Root) addGroup(parent *state.CollectionItem, name string) {
+	r.state.Checkpoint("", fmt.Sprintf("add group %s", name))
+	group := state.NewCollectionGroup(name, parent)
+	parent.AddChild(group)
+
+	r.state.Get().SelectedItem = group
+	r.state.SetDirty()
+	r.collection.Reload()
+	r.hideCurrentModal()
+}
+
 func (r *Root) handleDeleteSelectedItem(item *state.CollectionItem) {
-	text := fmt.Sprintf("Are you sure you want to delete [yellow]%s?", item.Name)
+	text := fmt.Sprintf("Are you sure you want to delete %s?", highlight(tview.Escape(item.Name)))
 	m := NewPromptModal("Delete Item", text, r.deleteSelectedItem, r.hideCurrentModal)
 
 	r.showModal(m.Widget())
 }
 
 func (r *Root) handleCloneSelectedItem(item *state.CollectionItem) {
-	text := fmt.Sprintf("Clone request [yellow]%s", item.Name)
+	text := fmt.Sprintf("Clone %s", highlight(tview.Escape(item.Name)))
 	m := NewTextInputModal("Clone Item", text, "Name", r.cloneSelectedItem, r.hideCurrentModal)
 
 	r.showModal(m.Widget())
@@ -249,6 +840,7 @@ func (r *Root) deleteSelectedItem() {
 	}
 
 	// remove this item from the collection
+	r.state.Checkpoint("", fmt.Sprintf("delete %s", item.Name))
 	r.state.Get().RemoveCollectionItem(item)
 
 	// find another item to select
@@ -258,7 +850,7 @@ func (r *Root) deleteSelectedItem() {
 
 	// if there are no other items to activate, create a new one first
 	if candidate == nil {
-		candidate = state.NewCollectionRequest("Unnamed", "GET", "", r.state.Get().Collection)
+		candidate = newRequest("Unnamed", r.state.Get().Collection)
 		r.state.Get().Collection.AddChild(candidate)
 	}
 
@@ -270,6 +862,7 @@ func (r *Root) deleteSelectedItem() {
 	}
 
 	r.state.Get().SelectedItem = candidate
+	r.state.SetDirty()
 
 	r.collection.Reload()
 	r.hideCurrentModal()
@@ -282,7 +875,9 @@ func (r *Root) renameSelectedItem(text string) {
 	}
 
 	// rename this item and reload our content
+	r.state.Checkpoint("", fmt.Sprintf("rename %s to %s", item.Name, text))
 	item.Name = text
+	r.state.SetDirty()
 	r.collection.Reload()
 	r.content.Reload()
 
@@ -295,18 +890,27 @@ func (r *Root) cloneSelectedItem(text string) {
 		return
 	}
 
-	// cloning a group item means we need to do a deep copy of all its children as well
-	if item.IsGroup {
-		// TODO
-	} else {
-		newItem := state.NewCollectionRequest(text, item.Method, item.URL, item.Parent)
-		item.Parent.InsertChildAfter(newItem, item)
+	// the root of the collection has no group to place the clone in
+	if item.Parent == nil {
+		util.ConsoleBell()
+		r.hideCurrentModal()
+		return
+	}
+
+	// cloning a group item copies all of its children as well
+	r.state.Checkpoint("", fmt.Sprintf("clone %s", item.Name))
+	newItem := item.Clone(item.Parent)
+	newItem.Name = text
+	item.Parent.InsertChildAfter(newItem, item)
 
-		// automatically select and activate the newly cloned item
+	// automatically select the newly cloned item, and activate it if it's a request
+	r.state.Get().SelectedItem = newItem
+	if !newItem.IsGroup {
 		r.state.Get().ActiveItem = newItem
-		r.state.Get().SelectedItem = newItem
 	}
 
+	r.state.SetDirty()
+
 	r.collection.Reload()
 	r.content.Reload()
 
@@ -321,7 +925,7 @@ func (r *Root) showSaveCurrentRequest() {
 		path = append(path, i.Name)
 	}
 
-	text := fmt.Sprintf("Request will be saved under [yellow]%s", strings.Join(path, " > "))
+	text := fmt.Sprintf("Request will be saved under %s", highlight(tview.Escape(strings.Join(path, " > "))))
 	m := NewTextInputModal("Save Request", text, "Name", r.handleSaveCurrentRequest, r.hideCurrentModal)
 	r.showModal(m.Widget())
 }
@@ -336,6 +940,7 @@ func (r *Root) handleSaveCurrentRequest(name string) {
 	leaf := path[len(path)-1]
 
 	// collect current request information and add it to the collection
+	r.state.Checkpoint("", fmt.Sprintf("save %s", name))
 	item := state.NewCollectionRequest(name, active.Method, active.URL, leaf)
 	item.RequestBody = active.RequestBody
 	item.Result = active.Result
@@ -348,10 +953,67 @@ func (r *Root) handleSaveCurrentRequest(name string) {
 }
 
 func (r *Root) handleCancelCurrentRequest() {
+	r.run = nil
 	r.network.CancelCurrent()
 	r.hideCurrentModal()
 }
 
+func (r *Root) handleRunGroup(item *state.CollectionItem) {
+	group := r.groupForItem(item)
+	text := fmt.Sprintf("Send the requests in %s that match a filter, such as tag:smoke. Leave the filter empty to "+
+		"send all requests.", highlight(tview.Escape(group.Name)))
+
+	m := NewTextInputModal("Run Group", text, "Filter", func(filter string) {
+		r.hideCurrentModal()
+		r.runGroup(group, state.ParseFilter(filter))
+	}, r.hideCurrentModal)
+
+	r.showModal(m.Widget())
+}
+
+// runGroup sends the requests in a group that match the filter one after another.
+func (r *Root) runGroup(group *state.CollectionItem, filter *state.Filter) {
+	requests := filter.Requests(group)
+	if len(requests) == 0 {
+		r.StatusBar.ShowMessage("No requests to run")
+		return
+	}
+
+	r.run = &collectionRun{
+		group:   group,
+		pending: requests,
+		total:   len(requests),
+	}
+
+	r.continueRun()
+}
+
+// continueRun sends the next request of the collection run in progress, or reports the outcome of the run once all
+// of its requests have been sent.
+func (r *Root) continueRun() {
+	run := r.run
+	r.hideCurrentModal()
+
+	if len(run.pending) == 0 {
+		r.run = nil
+		r.StatusBar.ShowMessage(fmt.Sprintf("Ran %d requests in %s, %d failed", run.total, run.group.Name, run.failed))
+		return
+	}
+
+	item := run.pending[0]
+	run.pending = run.pending[1:]
+
+	if err := r.network.SendRequest(item); err != nil {
+		r.run = nil
+		r.showError(fmt.Sprintf("Can't send %s: %s", item.Name, err.Error()))
+		return
+	}
+
+	text := fmt.Sprintf("Sending %s (%d of %d)...", tview.Escape(item.Name), run.total-len(run.pending), run.total)
+	m := NewAlertModal("Running", text, "Cancel", r.handleCancelCurrentRequest)
+	r.showModal(m.Widget())
+}
+
 func (r *Root) setCurrentRequest(item *state.CollectionItem) {
 	if r.state.Get().ActiveItem == item {
 		return
@@ -363,6 +1025,79 @@ func (r *Root) setCurrentRequest(item *state.CollectionItem) {
 	r.state.SetDirty()
 }
 
+// showFinder opens a modal to search for a request across the entire collection.
+func (r *Root) showFinder() {
+	m := NewFinderModal(r.state.Get().Collection, func(item *state.CollectionItem) {
+		r.hideCurrentModal()
+
+		// open the request the same way as activating it from the collection tree
+		r.state.Get().SelectedItem = item
+		r.handleCollectionItemAction(CollectionItemOpen, item)
+		r.collection.Reload()
+	}, r.hideCurrentModal)
+
+	r.showModal(m.Widget())
+}
+
+// undo reverts the most recent change to the collection.
+func (r *Root) undo() {
+	description, ok := r.state.Undo()
+	if !ok {
+		r.StatusBar.ShowMessage("Nothing to undo")
+		return
+	}
+
+	r.collection.Reload()
+	r.content.Reload()
+	r.StatusBar.ShowMessage(fmt.Sprintf("Undid: %s", description))
+}
+
+// redo reapplies the most recently undone change to the collection.
+func (r *Root) redo() {
+	description, ok := r.state.Redo()
+	if !ok {
+		r.StatusBar.ShowMessage("Nothing to redo")
+		return
+	}
+
+	r.collection.Reload()
+	r.content.Reload()
+	r.StatusBar.ShowMessage(fmt.Sprintf("Redid: %s", description))
+}
+
+// handleConflict prompts the user to resolve changes made to the app state by another instance of lull.
+func (r *Root) handleConflict() {
+	m := NewConflictModal(r.resolveConflict)
+	r.hideCurrentModal()
+	r.showModal(m.Widget())
+}
+
+func (r *Root) resolveConflict(resolution state.ConflictResolution) {
+	r.hideCurrentModal()
+
+	// suspend the ui in case the passphrase for secrets needs to be entered on the terminal
+	var err error
+	GetApplication().Suspend(func() {
+		err = r.state.Resolve(resolution)
+	})
+
+	if err != nil {
+		r.showError(fmt.Sprintf("Could not resolve conflict: %s", err))
+		return
+	}
+
+	r.collection.Reload()
+	r.content.Reload()
+	r.StatusBar.ShowMessage("Resolved conflict")
+}
+
+// handleExternalReload refreshes the collection and the active item after changes made outside of lull were loaded.
+func (r *Root) handleExternalReload() {
+	r.collection.Reload()
+	r.content.Reload()
+	r.StatusBar.ShowMessage("Reloaded changes made outside of lull")
+}
+
 func (r *Root) showModal(modal tview.Primitive) {
 	r.lastFocus = GetApplication().GetFocus()
 	r.pages.AddPage(rootPageModal, modal, true, true)
@@ -404,6 +1139,15 @@ func (r *Root) handleRequestFinished(item *state.CollectionItem, result *network
 		}
 
 		GetApplication().QueueUpdateDraw(func() {
+			// requests that fail during a collection run are counted, rather than interrupting the run
+			if r.run != nil {
+				r.run.failed++
+				r.content.Reload()
+				r.state.SetDirty()
+				r.continueRun()
+				return
+			}
+
 			m := NewAlertModal("Error", fmt.Sprintf("Could not send request. Error: %s", result.Error.Error()), "OK", r.hideCurrentModal)
 			r.hideCurrentModal()
 			r.showModal(m.Widget())
@@ -423,7 +1167,36 @@ func (r *Root) handleRequestFinished(item *state.CollectionItem, result *network
 		Duration:     result.EndTime.Sub(result.StartTime),
 	}
 
+	httpResult := item.Result
+
 	GetApplication().QueueUpdateDraw(func() {
+		// record a snapshot of the request body since the item may be edited later; this is done on the ui goroutine
+		// so that it does not race with edits to the item or the history
+		var body *state.RequestBody
+		if item.RequestBody != nil {
+			b := *item.RequestBody
+			body = &b
+		}
+
+		r.state.Get().AddHistory(&state.HistoryEntry{
+			Name:        item.Name,
+			Request:     result.Response.Request,
+			RequestBody: body,
+			Result:      httpResult,
+			StartTime:   result.StartTime,
+		})
+
+		if r.run != nil {
+			if result.Response.StatusCode >= http.StatusBadRequest {
+				r.run.failed++
+			}
+
+			r.content.Reload()
+			r.state.SetDirty()
+			r.continueRun()
+			return
+		}
+
 		r.hideCurrentModal()
 		r.content.Reload()
 		r.state.SetDirty()
@@ -2,15 +2,24 @@ package ui
 
 import (
 	"fmt"
-	"github.com/gdamore/tcell/v2"
 	"github.com/mbpolan/lull/internal/events"
+	"github.com/mbpolan/lull/internal/keys"
+	"github.com/mbpolan/lull/internal/theme"
 	"github.com/rivo/tview"
+	"time"
 	"unicode/utf8"
 )
 
+// statusMessageDuration is how long a message is shown in the status bar.
+const statusMessageDuration = 3 * time.Second
+
 // StatusBar presents informational components.
 type StatusBar struct {
-	flex *tview.Flex
+	flex           *tview.Flex
+	layout         *events.StatusBarContextChangeData
+	messageID      int
+	summaryID      int
+	showingMessage bool
 }
 
 // NewStatusBar returns an instance of StatusBar.
@@ -18,7 +27,7 @@ func NewStatusBar() *StatusBar {
 	s := new(StatusBar)
 	s.build()
 
-	events.Dispatcher().Subscribe(s, []events.Code{events.EventStatusBarContextChange})
+	events.Dispatcher().Subscribe(s, []events.Code{events.EventStatusBarContextChange, events.EventStatusBarSummary})
 
 	return s
 }
@@ -29,7 +38,51 @@ func (s *StatusBar) HandleEvent(code events.Code, payload events.Payload) {
 		if data := payload.Data.(*events.StatusBarContextChangeData); data != nil {
 			s.setLayoutFromData(data)
 		}
+	case events.EventStatusBarSummary:
+		if data := payload.Data.(*events.StatusBarSummaryData); data != nil {
+			s.showSummary(data.Text)
+		}
+	}
+}
+
+// ShowMessage temporarily replaces the contents of the status bar with a message.
+func (s *StatusBar) ShowMessage(text string) {
+	s.messageID++
+	id := s.messageID
+
+	s.showingMessage = true
+	s.flex.Clear()
+	s.addLabel(text)
+
+	time.AfterFunc(statusMessageDuration, func() {
+		GetApplication().QueueUpdateDraw(func() {
+			// another message may have been shown in the meantime
+			if s.messageID == id {
+				s.restoreLayout()
+			}
+		})
+	})
+}
+
+// showSummary temporarily shows a summary of the selected item. Summaries never replace other messages, such as those
+// confirming that a change was saved or undone. An empty summary removes the summary being shown, if any.
+func (s *StatusBar) showSummary(text string) {
+	summary := s.summaryID == s.messageID
+	if s.showingMessage && !summary {
+		return
+	}
+
+	if text == "" {
+		if s.showingMessage {
+			s.messageID++
+			s.restoreLayout()
+		}
+
+		return
 	}
+
+	s.ShowMessage(text)
+	s.summaryID = s.messageID
 }
 
 // Widget returns a primitive widget containing this component.
@@ -45,33 +98,51 @@ func (s *StatusBar) build() {
 }
 
 func (s *StatusBar) setLayoutFromData(layout *events.StatusBarContextChangeData) {
-	s.flex.Clear()
-	s.prefixCommonLabels()
+	s.layout = layout
+	s.messageID++
+	s.restoreLayout()
+}
 
-	for _, i := range layout.Fields {
-		s.addLabel(fmt.Sprintf("%s [%s]", i.Label, i.KeySequence))
+func (s *StatusBar) restoreLayout() {
+	s.showingMessage = false
+	s.flex.Clear()
+	if s.layout == nil {
+		s.suffixCommonLabels()
+		return
 	}
 
+	s.prefixCommonLabels()
+
+	s.addSequences(s.layout.Fields)
 	s.suffixCommonLabels()
 }
 
 func (s *StatusBar) prefixCommonLabels() {
 	s.addLabel("Navigate [↑↓←→]")
 	s.addLabel("Focus [⇥]")
+
+	if seq := keys.Get().Label(keys.Shrink, keys.Grow); seq != "" {
+		s.addLabel(fmt.Sprintf("Resize [%s]", seq))
+	}
 }
 
 func (s *StatusBar) suffixCommonLabels() {
-	s.addLabel("Save [⌃S]")
-	s.addLabel("Send [⌃G]")
-	s.addLabel("About [⌃A]")
+	s.addSequences(keys.Get().Sequences(keys.Save, keys.Send, keys.Find, keys.Undo, keys.Redo, keys.History,
+		keys.Workspaces, keys.About))
 	s.addLabel("Quit [⌃C]")
 }
 
+func (s *StatusBar) addSequences(sequences []events.StatusBarContextChangeSequence) {
+	for _, i := range sequences {
+		s.addLabel(fmt.Sprintf("%s [%s]", i.Label, i.KeySequence))
+	}
+}
+
 func (s *StatusBar) addLabel(text string) {
 	t := tview.NewTextView()
 	t.SetText(text)
-	t.SetTextColor(tcell.ColorWhite)
-	t.SetBackgroundColor(tcell.ColorBlue)
+	t.SetTextColor(theme.Color(theme.Get().StatusBar.Text))
+	t.SetBackgroundColor(theme.Color(theme.Get().StatusBar.Background))
 
 	// add the label and a spacer right after it
 	// TODO: can we do something with margins instead of adding an empty primitive?
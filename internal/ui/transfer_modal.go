@@ -0,0 +1,53 @@
+package ui
+
+import (
+	"github.com/rivo/tview"
+)
+
+type TransferModalAcceptHandler func(format string, path string)
+
+// TransferModal is a modal that prompts the user to choose a file format and a path to import from or export to.
+type TransferModal struct {
+	format   *tview.DropDown
+	path     *tview.InputField
+	onAccept TransferModalAcceptHandler
+	*BaseInputModal
+}
+
+// NewTransferModal returns a new modal with a title, information text, the supported formats and button handlers.
+func NewTransferModal(title string, text string, formats []string, accept TransferModalAcceptHandler, reject ModalRejectHandler) *TransferModal {
+	m := new(TransferModal)
+	m.BaseInputModal = NewBaseInputModal()
+	m.height = 7
+	m.onAccept = accept
+	m.onReject = reject
+	m.build(title, text, formats)
+
+	return m
+}
+
+// SetPath sets the text for the path.
+func (m *TransferModal) SetPath(path string) {
+	m.path.SetText(path)
+}
+
+func (m *TransferModal) build(title string, text string, formats []string) {
+	row := m.BaseInputModal.build(title, text, func() {
+		_, format := m.format.GetCurrentOption()
+		m.onAccept(format, m.path.GetText())
+	})
+
+	m.format = tview.NewDropDown()
+	m.format.SetLabel("Format ")
+	m.format.SetOptions(formats, nil)
+	m.format.SetCurrentOption(0)
+
+	m.path = tview.NewInputField()
+	m.path.SetLabel("Path ")
+
+	m.grid.AddItem(m.format, row, 0, 1, 2, 0, 0, true)
+	m.grid.AddItem(m.path, row+1, 0, 1, 2, 0, 0, false)
+
+	m.buildButtons(row+2, BaseInputModalButtonAll)
+	m.setupFocus([]tview.Primitive{m.format, m.path, m.ok, m.cancel})
+}
@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"fmt"
+	"github.com/mbpolan/lull/internal/config"
 	"github.com/mbpolan/lull/internal/events"
 	"github.com/mbpolan/lull/internal/state"
 	"github.com/mbpolan/lull/internal/util"
@@ -9,21 +11,21 @@ import (
 
 // URLBox is a view that contains an HTTP method, URL and other input components.
 type URLBox struct {
-	flex           *tview.Flex
-	method         *tview.DropDown
-	url            *tview.InputField
-	focusHolder    *tview.TextView
-	focusManager   *util.FocusManager
-	allowedMethods []string
-	sbSequences    []events.StatusBarContextChangeSequence
-	state          *state.Manager
+	flex         *tview.Flex
+	method       *tview.DropDown
+	url          *tview.InputField
+	focusHolder  *tview.TextView
+	focusManager *util.FocusManager
+	methods      []string
+	sbSequences  []events.StatusBarContextChangeSequence
+	state        *state.Manager
+	reloading    bool
 }
 
 // NewURLBox returns a new instance of URLBox.
 func NewURLBox(state *state.Manager) *URLBox {
 	u := new(URLBox)
 	u.state = state
-	u.allowedMethods = []string{"GET", "POST", "PUT", "DELETE", "PATCH"}
 	u.build()
 
 	// no additional key sequences supported by this component
@@ -48,7 +50,14 @@ func (u *URLBox) Reload() {
 		return
 	}
 
+	// ignore changes reported by the views while they are being populated
+	u.reloading = true
+	defer func() {
+		u.reloading = false
+	}()
+
 	u.flex.SetTitle(u.title())
+	u.setMethods()
 	u.method.SetCurrentOption(u.currentMethod())
 	u.url.SetText(item.URL)
 }
@@ -59,8 +68,10 @@ func (u *URLBox) Widget() tview.Primitive {
 }
 
 func (u *URLBox) build() {
-	curURL := u.currentURL()
-	curMethod := u.currentMethod()
+	u.reloading = true
+	defer func() {
+		u.reloading = false
+	}()
 
 	u.flex = tview.NewFlex()
 	u.flex.SetTitle(u.title())
@@ -68,11 +79,11 @@ func (u *URLBox) build() {
 	u.flex.SetDirection(tview.FlexColumn)
 
 	u.method = tview.NewDropDown()
-	u.method.SetOptions(u.allowedMethods, u.handleMethodChanged)
-	u.method.SetCurrentOption(curMethod)
+	u.setMethods()
+	u.method.SetCurrentOption(u.currentMethod())
 
 	u.url = tview.NewInputField()
-	u.url.SetText(curURL)
+	u.url.SetText(u.currentURL())
 	u.url.SetChangedFunc(u.handleURLChanged)
 
 	u.focusHolder = tview.NewTextView()
@@ -106,13 +117,32 @@ func (u *URLBox) currentURL() string {
 	return item.URL
 }
 
+// setMethods offers the configured methods along with the method of the active item, which may not be one of them if
+// the item was imported or the configuration changed since it was created.
+func (u *URLBox) setMethods() {
+	u.methods = append([]string{}, config.Get().Request.Methods...)
+
+	if item := u.state.Get().ActiveItem; item != nil && item.Method != "" {
+		listed := false
+		for _, m := range u.methods {
+			listed = listed || m == item.Method
+		}
+
+		if !listed {
+			u.methods = append(u.methods, item.Method)
+		}
+	}
+
+	u.method.SetOptions(u.methods, u.handleMethodChanged)
+}
+
 func (u *URLBox) currentMethod() int {
 	item := u.state.Get().ActiveItem
 	if item == nil {
 		return -1
 	}
 
-	for i, method := range u.allowedMethods {
+	for i, method := range u.methods {
 		if method == item.Method {
 			return i
 		}
@@ -123,20 +153,24 @@ func (u *URLBox) currentMethod() int {
 
 func (u *URLBox) handleMethodChanged(text string, index int) {
 	item := u.state.Get().ActiveItem
-	if item == nil {
+	if u.reloading || index < 0 || item == nil || item.Method == text {
 		return
 	}
 
+	u.state.Checkpoint("", fmt.Sprintf("change method of %s", item.Name))
 	item.Method = text
 	u.state.SetDirty()
+
+	events.Dispatcher().Post(events.EventCollectionItemChanged, u, item)
 }
 
 func (u *URLBox) handleURLChanged(text string) {
 	item := u.state.Get().ActiveItem
-	if item == nil {
+	if u.reloading || item == nil || item.URL == text {
 		return
 	}
 
+	u.state.Checkpoint(fmt.Sprintf("url:%s", item.UUID), fmt.Sprintf("edit URL of %s", item.Name))
 	item.URL = text
 	u.state.SetDirty()
 }
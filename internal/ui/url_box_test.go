@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"github.com/mbpolan/lull/internal/formats"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_URLBox_Reload_ImportedMethod(t *testing.T) {
+	group, _, err := formats.ImportOpenAPI([]byte(`
+openapi: 3.0.3
+info:
+  title: Pets
+paths:
+  /pets:
+    head:
+      tags: [pets]
+`), "pets.yaml")
+	assert.NoError(t, err)
+
+	item := group.Children[0].Children[0]
+	s := state.NewAppState()
+	s.Collection = group
+	s.ActiveItem = item
+	s.SelectedItem = item
+
+	m := state.NewStateManager(s, state.NewFileStorage(t.TempDir()+"/state.json"))
+	u := NewURLBox(m)
+	u.Reload()
+
+	_, method := u.method.GetCurrentOption()
+	_, undone := m.Undo()
+
+	assert.Equal(t, "HEAD", item.Method)
+	assert.Equal(t, "HEAD", method)
+	assert.False(t, undone)
+}
@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"fmt"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/rivo/tview"
+	"sort"
+	"strings"
+)
+
+type VariablesModalAcceptHandler func(variables map[string]string, secret []string)
+
+// VariablesModal is a modal that allows editing a set of variables, one per line in "name = value" format, along with
+// the names of those whose values are secret.
+type VariablesModal struct {
+	variables *tview.TextArea
+	secret    *tview.InputField
+	seen      map[string]bool
+	onAccept  VariablesModalAcceptHandler
+	*BaseInputModal
+}
+
+// NewVariablesModal returns a new instance of VariablesModal.
+func NewVariablesModal(title string, accept VariablesModalAcceptHandler, reject ModalRejectHandler) *VariablesModal {
+	m := new(VariablesModal)
+	m.BaseInputModal = NewBaseInputModal()
+	m.width = 75
+	m.height = 16
+	m.seen = map[string]bool{}
+	m.onAccept = accept
+	m.onReject = reject
+	m.build(title)
+
+	return m
+}
+
+// Set populates the modal with existing variables and the names of those that are secret.
+func (m *VariablesModal) Set(variables map[string]string, secret []string) {
+	names := make([]string, 0, len(variables))
+	for k := range variables {
+		names = append(names, k)
+		m.seen[k] = true
+	}
+
+	sort.Strings(names)
+
+	var lines []string
+	for _, k := range names {
+		lines = append(lines, fmt.Sprintf("%s = %s", k, variables[k]))
+	}
+
+	m.secret.SetText(strings.Join(secret, ", "))
+	m.variables.SetText(strings.Join(lines, "\n"), false)
+}
+
+func (m *VariablesModal) build(title string) {
+	row := m.BaseInputModal.build(title, "One variable per line, formatted as "+highlight("name = value"), func() {
+		variables := m.parseVariables()
+
+		// only variables that are still defined can be secret
+		var secret []string
+		for _, name := range parseTags(m.secret.GetText()) {
+			if _, ok := variables[name]; ok {
+				secret = append(secret, name)
+			}
+		}
+
+		m.onAccept(variables, secret)
+	})
+
+	m.variables = tview.NewTextArea()
+	m.variables.SetChangedFunc(m.suggestSecrets)
+
+	m.secret = tview.NewInputField()
+	m.secret.SetLabel("Secret ")
+	m.secret.SetPlaceholder("comma, separated")
+
+	m.grid.AddItem(m.variables, row, 0, 1, 2, 0, 0, true)
+	m.grid.AddItem(m.secret, row+1, 0, 1, 2, 0, 0, false)
+
+	m.buildButtons(row+2, BaseInputModalButtonAll)
+
+	// fixed height for the info text, secret names and buttons with the text area taking up the remaining space
+	m.grid.SetRows(1, -1, 1, m.ButtonHeight())
+
+	m.setupFocus([]tview.Primitive{m.variables, m.secret, m.ok, m.cancel})
+}
+
+// suggestSecrets marks new variables as secret if their names suggest that they hold secret values. Each variable is
+// only considered once, so that it can still be unmarked.
+func (m *VariablesModal) suggestSecrets() {
+	secret := parseTags(m.secret.GetText())
+	changed := false
+
+	for name := range m.parseVariables() {
+		if m.seen[name] {
+			continue
+		}
+
+		m.seen[name] = true
+		if state.SuggestSecretVariable(name) {
+			secret = append(secret, name)
+			changed = true
+		}
+	}
+
+	if changed {
+		m.secret.SetText(strings.Join(secret, ", "))
+	}
+}
+
+// parseVariables returns the variables entered in the text area. Lines without a name are ignored.
+func (m *VariablesModal) parseVariables() map[string]string {
+	variables := map[string]string{}
+
+	for _, line := range strings.Split(m.variables.GetText(), "\n") {
+		name, value, _ := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		variables[name] = strings.TrimSpace(value)
+	}
+
+	return variables
+}
@@ -0,0 +1,74 @@
+package ui
+
+import (
+	"github.com/rivo/tview"
+	"strings"
+)
+
+type WorkspaceModalAcceptHandler func(name string)
+
+// WorkspaceSwitcher lists the available workspaces and switches between them.
+type WorkspaceSwitcher interface {
+	// Current returns the name of the workspace that is currently open.
+	Current() string
+
+	// List returns the names of all available workspaces.
+	List() ([]string, error)
+
+	// Switch saves the current workspace and opens the workspace with the given name, creating it if needed.
+	Switch(name string) error
+}
+
+// WorkspaceModal is a modal that prompts the user to choose an existing workspace or to name a new one.
+type WorkspaceModal struct {
+	workspace *tview.DropDown
+	name      *tview.InputField
+	onAccept  WorkspaceModalAcceptHandler
+	*BaseInputModal
+}
+
+// NewWorkspaceModal returns a new modal listing the available workspaces, with the current one selected.
+func NewWorkspaceModal(current string, workspaces []string, accept WorkspaceModalAcceptHandler, reject ModalRejectHandler) *WorkspaceModal {
+	m := new(WorkspaceModal)
+	m.BaseInputModal = NewBaseInputModal()
+	m.height = 7
+	m.onAccept = accept
+	m.onReject = reject
+	m.build(current, workspaces)
+
+	return m
+}
+
+func (m *WorkspaceModal) build(current string, workspaces []string) {
+	text := "Choose a workspace, or enter a name to create a new one"
+	row := m.BaseInputModal.build("Workspaces", text, func() {
+		// prefer a new workspace if a name was entered
+		if name := strings.TrimSpace(m.name.GetText()); name != "" {
+			m.onAccept(name)
+			return
+		}
+
+		_, name := m.workspace.GetCurrentOption()
+		m.onAccept(name)
+	})
+
+	m.workspace = tview.NewDropDown()
+	m.workspace.SetLabel("Open ")
+	m.workspace.SetOptions(workspaces, nil)
+	m.workspace.SetCurrentOption(0)
+
+	for i, w := range workspaces {
+		if w == current {
+			m.workspace.SetCurrentOption(i)
+		}
+	}
+
+	m.name = tview.NewInputField()
+	m.name.SetLabel("New ")
+
+	m.grid.AddItem(m.workspace, row, 0, 1, 2, 0, 0, true)
+	m.grid.AddItem(m.name, row+1, 0, 1, 2, 0, 0, false)
+
+	m.buildButtons(row+2, BaseInputModalButtonAll)
+	m.setupFocus([]tview.Primitive{m.workspace, m.name, m.ok, m.cancel})
+}
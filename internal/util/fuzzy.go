@@ -0,0 +1,90 @@
+package util
+
+import (
+	"unicode"
+)
+
+const (
+	// fuzzyConsecutiveBonus is added for each matched character that directly follows the previous match.
+	fuzzyConsecutiveBonus = 8
+
+	// fuzzyBoundaryBonus is added for each matched character at the start of a word.
+	fuzzyBoundaryBonus = 3
+
+	// fuzzyMaxLeadingPenalty caps the penalty for unmatched characters before the first match.
+	fuzzyMaxLeadingPenalty = 10
+)
+
+// FuzzyMatch returns true if all characters in pattern appear in text in the same order, ignoring case, along with a
+// score for the match. Matches of consecutive characters, characters at the start of words and matches close to the
+// start of the text receive higher scores. An empty pattern matches any text with a score of zero.
+func FuzzyMatch(pattern string, text string) (int, bool) {
+	p := []rune(pattern)
+	if len(p) == 0 {
+		return 0, true
+	}
+
+	// try each occurrence of the first character as a starting point, keeping the best match
+	t := []rune(text)
+	best, found := 0, false
+	for start := range t {
+		if !equalFold(t[start], p[0]) {
+			continue
+		}
+
+		if score, ok := fuzzyMatchFrom(p, t, start); ok && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+
+	return best, found
+}
+
+// fuzzyMatchFrom greedily matches the pattern against the text beginning at the given index.
+func fuzzyMatchFrom(p []rune, t []rune, start int) (int, bool) {
+	score := 0
+	previous := -2
+	pi := 0
+
+	for ti := start; ti < len(t) && pi < len(p); ti++ {
+		if !equalFold(t[ti], p[pi]) {
+			continue
+		}
+
+		score++
+		if previous == ti-1 {
+			score += fuzzyConsecutiveBonus
+		}
+		if isWordStart(t, ti) {
+			score += fuzzyBoundaryBonus
+		}
+
+		previous = ti
+		pi++
+	}
+
+	if pi < len(p) {
+		return 0, false
+	}
+
+	return score - Min(start, fuzzyMaxLeadingPenalty), true
+}
+
+func equalFold(a rune, b rune) bool {
+	return unicode.ToLower(a) == unicode.ToLower(b)
+}
+
+// isWordStart returns true if the rune at index i begins a word, either after a separator or as an uppercase letter
+// following a lowercase one.
+func isWordStart(text []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+
+	prev := text[i-1]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+
+	return unicode.IsLower(prev) && unicode.IsUpper(text[i])
+}
@@ -0,0 +1,25 @@
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_FuzzyMatch_Subsequence(t *testing.T) {
+	_, ok := FuzzyMatch("gusr", "Get Users")
+
+	assert.True(t, ok)
+}
+
+func Test_FuzzyMatch_NoMatch(t *testing.T) {
+	_, ok := FuzzyMatch("xyz", "Get Users")
+
+	assert.False(t, ok)
+}
+
+func Test_FuzzyMatch_PrefersConsecutiveAndWordStarts(t *testing.T) {
+	contiguous, _ := FuzzyMatch("user", "Get user")
+	scattered, _ := FuzzyMatch("user", "update a server")
+
+	assert.Greater(t, contiguous, scattered)
+}
@@ -22,6 +22,15 @@ func Min[T constraints.Ordered](a, b T) T {
 	return b
 }
 
+// Max returns the greater of the two comparable values.
+func Max[T constraints.Ordered](a, b T) T {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
 // FormatDuration returns a human friendly string representing the given duration (ie: 1.23 s).
 func FormatDuration(t time.Duration) string {
 	if t < time.Second {
@@ -0,0 +1,73 @@
+package util
+
+import (
+	"fmt"
+	"github.com/mbpolan/lull/internal/theme"
+	"github.com/rivo/tview"
+	"regexp"
+	"strings"
+)
+
+var markdownHeading = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+var markdownListItem = regexp.MustCompile(`^(\s*)[-*+]\s+(.*)$`)
+var markdownInline = regexp.MustCompile("\\*\\*([^*]+)\\*\\*|`([^`]+)`|\\*([^*]+)\\*|\\b_([^_]+)_\\b|\\[([^\\]]+)\\]\\(([^)]+)\\)")
+
+// RenderMarkdown converts basic markdown into text with tview color tags. Headings, lists, block quotes, fenced code
+// blocks, bold and italic text, inline code and links are supported; anything else is shown as plain text.
+func RenderMarkdown(text string) string {
+	var lines []string
+	code := false
+	t := theme.Get()
+
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			code = !code
+			continue
+		}
+
+		if code {
+			lines = append(lines, theme.Colorize(t.Syntax.String, tview.Escape(line)))
+		} else if m := markdownHeading.FindStringSubmatch(line); m != nil {
+			lines = append(lines, fmt.Sprintf("[%s::b]%s[-::-]", t.Highlight, renderMarkdownInline(m[2])))
+		} else if m := markdownListItem.FindStringSubmatch(line); m != nil {
+			lines = append(lines, m[1]+"• "+renderMarkdownInline(m[2]))
+		} else if strings.HasPrefix(line, ">") {
+			lines = append(lines, theme.Colorize(t.Muted, "│ "+renderMarkdownInline(strings.TrimSpace(line[1:]))))
+		} else {
+			lines = append(lines, renderMarkdownInline(line))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// renderMarkdownInline converts emphasis, inline code and links within a single line of markdown.
+func renderMarkdownInline(line string) string {
+	var b strings.Builder
+	last := 0
+
+	for _, m := range markdownInline.FindAllStringSubmatchIndex(line, -1) {
+		b.WriteString(tview.Escape(line[last:m[0]]))
+		last = m[1]
+
+		group := func(i int) string {
+			return tview.Escape(line[m[2*i]:m[2*i+1]])
+		}
+
+		switch {
+		case m[2] >= 0:
+			b.WriteString("[::b]" + group(1) + "[::-]")
+		case m[4] >= 0:
+			b.WriteString(theme.Colorize(theme.Get().Syntax.String, group(2)))
+		case m[6] >= 0:
+			b.WriteString("[::i]" + group(3) + "[::-]")
+		case m[8] >= 0:
+			b.WriteString("[::i]" + group(4) + "[::-]")
+		default:
+			b.WriteString("[::u]" + group(5) + "[::-] (" + group(6) + ")")
+		}
+	}
+
+	b.WriteString(tview.Escape(line[last:]))
+	return b.String()
+}
@@ -0,0 +1,18 @@
+package util
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_RenderMarkdown_BlockElements(t *testing.T) {
+	text := RenderMarkdown("# Users\n- creates a user\n```\n[1]\n```")
+
+	assert.Equal(t, "[yellow::b]Users[-::-]\n• creates a user\n[green][1[][-]", text)
+}
+
+func Test_RenderMarkdown_InlineElements(t *testing.T) {
+	text := RenderMarkdown("**Do not** run `DELETE` on _prod_, see [docs](http://x)")
+
+	assert.Equal(t, "[::b]Do not[::-] run [green]DELETE[-] on [::i]prod[::-], see [::u]docs[::-] (http://x)", text)
+}
@@ -0,0 +1,100 @@
+package workspace
+
+import (
+	"fmt"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/system"
+	"github.com/pkg/errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SchemaVersion is the version of the workspace format written by this version of lull. It is recorded in the group
+// file at the root of the workspace, and must be incremented whenever a change is made to the format that requires
+// existing workspaces to be migrated.
+const SchemaVersion = 1
+
+// migration upgrades a workspace directory from one schema version to the next.
+type migration func(path string) error
+
+// migrations upgrade workspaces step by step, where the migration at index i upgrades a workspace from version i to
+// version i+1.
+var migrations = []migration{
+	migrateV0ToV1,
+}
+
+// schemaVersionOf returns the schema version of the workspace in a directory. Workspaces written before versioning was
+// introduced are version 0.
+func schemaVersionOf(path string) (int, error) {
+	var g struct {
+		Version int
+	}
+
+	if err := readJSON(filepath.Join(path, groupFileName), &g); err != nil {
+		return 0, err
+	}
+
+	return g.Version, nil
+}
+
+// migrate upgrades the workspace in a directory to the current schema version, first copying its files to backup
+// unless a backup of that version already exists. A NewerVersionError is returned if the workspace was written by a
+// newer version of lull.
+func migrate(path string, backup string) error {
+	version, err := schemaVersionOf(path)
+	if err != nil {
+		return err
+	} else if version > SchemaVersion {
+		return &state.NewerVersionError{Version: version, Supported: SchemaVersion}
+	} else if version == SchemaVersion {
+		return nil
+	}
+
+	backup = fmt.Sprintf("%s-v%d", backup, version)
+	if _, err := os.Stat(backup); os.IsNotExist(err) {
+		if err := copyWorkspace(path, backup); err != nil {
+			return errors.Wrap(err, "failed to back up workspace before migrating")
+		}
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v](path); err != nil {
+			return fmt.Errorf("failed to migrate workspace from version %d: %w", v, err)
+		}
+	}
+
+	return nil
+}
+
+// migrateV0ToV1 makes no changes, since version 1 only started recording the version in the root group file, which
+// is done when the workspace is next saved.
+func migrateV0ToV1(_ string) error {
+	return nil
+}
+
+// copyWorkspace copies the group and request files of a workspace to another directory.
+func copyWorkspace(path string, dest string) error {
+	return filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(p) != ".json" {
+			return err
+		}
+
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dest, rel)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return err
+		}
+
+		return system.WriteFileAtomic(target, data, 0644)
+	})
+}
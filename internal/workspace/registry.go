@@ -0,0 +1,95 @@
+package workspace
+
+import (
+	"github.com/pkg/errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultName is the name of the workspace that is stored in the legacy app state file rather than in a directory.
+const DefaultName = "default"
+
+// lastFileName is the name of the file that records the most recently used workspace.
+const lastFileName = ".last"
+
+// Registry manages named workspaces, each stored in its own directory under a common parent directory.
+type Registry struct {
+	path string
+}
+
+// NewRegistry returns a Registry for workspaces stored under the directory at path.
+func NewRegistry(path string) *Registry {
+	return &Registry{
+		path: path,
+	}
+}
+
+// List returns the names of all workspaces sorted by name, with the default workspace first.
+func (r *Registry) List() ([]string, error) {
+	names := []string{}
+
+	entries, err := os.ReadDir(r.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		if e.IsDir() && ValidateName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+
+	sort.Strings(names)
+	return append([]string{DefaultName}, names...), nil
+}
+
+// Dir returns the directory that stores the workspace with the given name.
+func (r *Registry) Dir(name string) (string, error) {
+	if err := ValidateName(name); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(r.path, name), nil
+}
+
+// Last returns the name of the most recently used workspace, or DefaultName if none was recorded.
+func (r *Registry) Last() string {
+	data, err := os.ReadFile(filepath.Join(r.path, lastFileName))
+	if err != nil {
+		return DefaultName
+	}
+
+	name := strings.TrimSpace(string(data))
+	if name != DefaultName && ValidateName(name) != nil {
+		return DefaultName
+	}
+
+	return name
+}
+
+// SetLast records the name of the most recently used workspace.
+func (r *Registry) SetLast(name string) error {
+	if err := os.MkdirAll(r.path, 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(r.path, lastFileName), []byte(name+"\n"), 0600)
+}
+
+// ValidateName returns an error if name cannot be used as the name of a workspace.
+func ValidateName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("workspace name cannot be empty")
+	case name == DefaultName:
+		return errors.Errorf("workspace name %s is reserved", DefaultName)
+	case strings.HasPrefix(name, "."):
+		return errors.New("workspace name cannot start with a period")
+	case strings.ContainsAny(name, `/\`):
+		return errors.New("workspace name cannot contain path separators")
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package workspace
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func Test_Registry_List(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "zeta"), 0700))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "alpha"), 0700))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, ".hidden"), 0700))
+
+	names, err := NewRegistry(dir).List()
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{DefaultName, "alpha", "zeta"}, names)
+}
+
+func Test_Registry_Last(t *testing.T) {
+	r := NewRegistry(filepath.Join(t.TempDir(), "workspaces"))
+	assert.Equal(t, DefaultName, r.Last())
+
+	assert.NoError(t, r.SetLast("payments"))
+	assert.Equal(t, "payments", r.Last())
+}
+
+func Test_ValidateName(t *testing.T) {
+	assert.NoError(t, ValidateName("payments-api"))
+	assert.Error(t, ValidateName(""))
+	assert.Error(t, ValidateName(DefaultName))
+	assert.Error(t, ValidateName("../escape"))
+	assert.Error(t, ValidateName(".hidden"))
+}
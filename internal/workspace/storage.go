@@ -0,0 +1,542 @@
+package workspace
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/system"
+	"github.com/pkg/errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// groupFileName is the name of the file within each group's directory that describes the group itself.
+const groupFileName = "_group.json"
+
+// lockFileName is the name of the file in the local directory that signals that an instance of lull is using the
+// workspace.
+const lockFileName = "lock"
+
+// layoutFileName is the name of the file in the local directory that stores the layout of the user interface.
+const layoutFileName = "layout.json"
+
+// backupDirName is the name of the directory in the local directory where a workspace is backed up before it is
+// migrated to a newer schema version. The version being migrated from is appended to it.
+const backupDirName = "backup"
+
+// legacyLayoutFileName is the name of the file in the workspace directory where the layout was previously stored.
+const legacyLayoutFileName = ".layout"
+
+// groupFile is the representation of a group on disk. Items lists the names of the group's children, in order. Version
+// is the schema version of the workspace, and is only recorded for the group at the root of the workspace.
+type groupFile struct {
+	Version         int `json:",omitempty"`
+	UUID            uuid.UUID
+	Name            string
+	Headers         map[string][]string
+	InheritHeaders  bool
+	Authentication  *state.ItemAuthentication
+	Variables       map[string]string `json:",omitempty"`
+	SecretVariables []string          `json:",omitempty"`
+	Tags            []string          `json:",omitempty"`
+	Description     string            `json:",omitempty"`
+	Source          string            `json:",omitempty"`
+	Items           []string
+}
+
+// requestFile is the representation of a request on disk.
+type requestFile struct {
+	UUID            uuid.UUID
+	Name            string
+	Method          string
+	URL             string
+	Headers         map[string][]string
+	InheritHeaders  bool
+	RequestBody     *state.RequestBody
+	Authentication  *state.ItemAuthentication
+	Variables       map[string]string `json:",omitempty"`
+	SecretVariables []string          `json:",omitempty"`
+	Tags            []string          `json:",omitempty"`
+	Description     string            `json:",omitempty"`
+	Source          string            `json:",omitempty"`
+}
+
+// Storage stores a collection as a directory tree, where each group is a directory and each request is a file. Only
+// the collection and the layout of the user interface are stored; runtime state such as the active and selected items
+// is not. The layout and lock belong to the user rather than the workspace, so they are kept in a local directory
+// outside the workspace, which is often under version control.
+type Storage struct {
+	path      string
+	localPath string
+}
+
+// NewStorage returns a Storage that reads and writes a workspace in the directory at path, keeping files that belong
+// to the user in the directory at localPath.
+func NewStorage(path string, localPath string) *Storage {
+	return &Storage{
+		path:      path,
+		localPath: localPath,
+	}
+}
+
+// Exists returns true if the directory contains a workspace.
+func (s *Storage) Exists() bool {
+	_, err := os.Stat(filepath.Join(s.path, groupFileName))
+	return err == nil
+}
+
+// Revision returns a hash of the names and contents of all files in the workspace that make up the collection.
+func (s *Storage) Revision() (string, error) {
+	if !s.Exists() {
+		return "", nil
+	}
+
+	h := sha256.New()
+	err := filepath.WalkDir(s.path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		rel, _ := filepath.Rel(s.path, path)
+		fmt.Fprintf(h, "%s\n%d\n", filepath.ToSlash(rel), len(data))
+		h.Write(data)
+		return nil
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
+
+// LockPath returns the path to the lock file in the local directory.
+func (s *Storage) LockPath() string {
+	return filepath.Join(s.localPath, lockFileName)
+}
+
+// Load reads the collection from the workspace directory. Workspaces written by older versions of lull are migrated
+// to the current schema version after being backed up to the local directory.
+func (s *Storage) Load() (*state.AppState, error) {
+	if err := migrate(s.path, filepath.Join(s.localPath, backupDirName)); err != nil {
+		return nil, err
+	}
+
+	collection, err := s.readGroup(s.path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &state.AppState{
+		Collection: collection,
+	}
+
+	// the layout is not essential, so fall back to the default layout if it cannot be read
+	var layout state.Layout
+	if err := readJSON(filepath.Join(s.localPath, layoutFileName), &layout); err == nil {
+		a.Layout = layout
+	} else if err := readJSON(filepath.Join(s.path, legacyLayoutFileName), &layout); err == nil {
+		a.Layout = layout
+	}
+
+	a.EnsureDefaultItems()
+	return a, nil
+}
+
+// Save writes the collection to the workspace directory. Files belonging to items that no longer exist in the
+// collection are removed. A workspace written by a newer version of lull is never overwritten.
+func (s *Storage) Save(a *state.AppState) error {
+	if version, err := schemaVersionOf(s.path); err == nil && version > SchemaVersion {
+		return &state.NewerVersionError{Version: version, Supported: SchemaVersion}
+	}
+
+	if err := s.writeGroup(s.path, a.Collection, SchemaVersion); err != nil {
+		return errors.Wrap(err, "failed to save workspace")
+	}
+
+	if err := os.MkdirAll(s.localPath, 0755); err != nil {
+		return errors.Wrap(err, "failed to save workspace layout")
+	} else if err := writeJSON(filepath.Join(s.localPath, layoutFileName), &a.Layout); err != nil {
+		return errors.Wrap(err, "failed to save workspace layout")
+	}
+
+	// the layout is no longer kept in the workspace directory
+	if err := os.Remove(filepath.Join(s.path, legacyLayoutFileName)); err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to remove previous workspace layout")
+	}
+
+	return nil
+}
+
+func (s *Storage) readGroup(path string, parent *state.CollectionItem) (*state.CollectionItem, error) {
+	var g groupFile
+	if err := readJSON(filepath.Join(path, groupFileName), &g); err != nil {
+		return nil, err
+	}
+
+	group := state.NewCollectionGroup(g.Name, parent)
+	group.UUID = g.UUID
+	group.InheritHeaders = g.InheritHeaders
+	group.Variables = g.Variables
+	group.SecretVariables = g.SecretVariables
+	group.Tags = g.Tags
+	group.Description = g.Description
+	group.Source = g.Source
+	if g.Headers != nil {
+		group.Headers = g.Headers
+	}
+	if g.Authentication != nil {
+		group.Authentication = *g.Authentication
+	}
+
+	names, err := s.itemNames(path, g.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range names {
+		var item *state.CollectionItem
+		if strings.HasSuffix(name, ".json") {
+			item, err = s.readRequest(filepath.Join(path, name), group)
+		} else {
+			item, err = s.readGroup(filepath.Join(path, name), group)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		group.AddChild(item)
+	}
+
+	return group, nil
+}
+
+// itemNames returns the names of the items in a group's directory. Items listed by the group come first and in the
+// listed order, followed by any other items found in the directory sorted by name.
+func (s *Storage) itemNames(path string, listed []string) ([]string, error) {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	found := map[string]bool{}
+	var extra []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() {
+			if _, err := os.Stat(filepath.Join(path, name, groupFileName)); err != nil {
+				continue
+			}
+		} else if name == groupFileName || filepath.Ext(name) != ".json" {
+			continue
+		}
+
+		found[name] = true
+		extra = append(extra, name)
+	}
+
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range listed {
+		if found[name] && !seen[name] {
+			names = append(names, name)
+			seen[name] = true
+		}
+	}
+
+	sort.Strings(extra)
+	for _, name := range extra {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+func (s *Storage) readRequest(path string, parent *state.CollectionItem) (*state.CollectionItem, error) {
+	var r requestFile
+	if err := readJSON(path, &r); err != nil {
+		return nil, err
+	}
+
+	item := state.NewCollectionRequest(r.Name, r.Method, r.URL, parent)
+	item.UUID = r.UUID
+	item.InheritHeaders = r.InheritHeaders
+	item.RequestBody = r.RequestBody
+	item.Variables = r.Variables
+	item.SecretVariables = r.SecretVariables
+	item.Tags = r.Tags
+	item.Description = r.Description
+	item.Source = r.Source
+	if r.Headers != nil {
+		item.Headers = r.Headers
+	}
+	if r.Authentication != nil {
+		item.Authentication = *r.Authentication
+	}
+
+	return item, nil
+}
+
+// writeGroup writes a group and its items to a directory. The version is recorded in the group's file unless it is
+// zero, as it is for all but the root group.
+func (s *Storage) writeGroup(path string, group *state.CollectionItem, version int) error {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return err
+	}
+
+	names := itemFileNames(group.Children, s.storedNames(path))
+	g := groupFile{
+		Version:         version,
+		UUID:            group.UUID,
+		Name:            group.Name,
+		Headers:         group.Headers,
+		InheritHeaders:  group.InheritHeaders,
+		Authentication:  &group.Authentication,
+		Variables:       group.Variables,
+		SecretVariables: group.SecretVariables,
+		Tags:            group.Tags,
+		Description:     group.Description,
+		Source:          group.Source,
+		Items:           names,
+	}
+
+	if err := writeJSON(filepath.Join(path, groupFileName), &g); err != nil {
+		return err
+	}
+
+	for i, c := range group.Children {
+		var err error
+		if c.IsGroup {
+			err = s.writeGroup(filepath.Join(path, names[i]), c, 0)
+		} else {
+			err = s.writeRequest(filepath.Join(path, names[i]), c)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.removeStale(path, names)
+}
+
+func (s *Storage) writeRequest(path string, item *state.CollectionItem) error {
+	r := requestFile{
+		UUID:            item.UUID,
+		Name:            item.Name,
+		Method:          item.Method,
+		URL:             item.URL,
+		Headers:         item.Headers,
+		InheritHeaders:  item.InheritHeaders,
+		RequestBody:     item.RequestBody,
+		Authentication:  &item.Authentication,
+		Variables:       item.Variables,
+		SecretVariables: item.SecretVariables,
+		Tags:            item.Tags,
+		Description:     item.Description,
+		Source:          item.Source,
+	}
+
+	return writeJSON(path, &r)
+}
+
+// removeStale removes request files and group directories that are no longer part of a group. Other files are left
+// untouched.
+func (s *Storage) removeStale(path string, names []string) error {
+	keep := map[string]bool{}
+	for _, n := range names {
+		keep[n] = true
+	}
+
+	existing, err := s.itemNames(path, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range existing {
+		if keep[name] {
+			continue
+		}
+
+		if strings.HasSuffix(name, ".json") {
+			err = os.Remove(filepath.Join(path, name))
+		} else {
+			err = removeGroupDir(filepath.Join(path, name))
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeGroupDir removes a group's directory along with its items. The directory itself is only removed if no other
+// files remain in it.
+func removeGroupDir(path string) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		p := filepath.Join(path, e.Name())
+		if e.IsDir() {
+			if _, err := os.Stat(filepath.Join(p, groupFileName)); err == nil {
+				if err := removeGroupDir(p); err != nil {
+					return err
+				}
+			}
+		} else if filepath.Ext(e.Name()) == ".json" {
+			if err := os.Remove(p); err != nil {
+				return err
+			}
+		}
+	}
+
+	if entries, err = os.ReadDir(path); err == nil && len(entries) == 0 {
+		return os.Remove(path)
+	}
+
+	return nil
+}
+
+// itemFileNames returns a stable file name for each item. Requests are stored as files and groups as directories,
+// named after the item. Items keep the name they are stored under for as long as it suits them, so that adding an item
+// never renames another. Items whose names would collide with another item's are disambiguated by their UUIDs.
+func itemFileNames(items []*state.CollectionItem, stored map[uuid.UUID]string) []string {
+	names := make([]string, len(items))
+	used := map[string]bool{}
+
+	// keep the names of items that were stored before, unless they have since been renamed
+	for idx, i := range items {
+		name, ok := stored[i.UUID]
+		if ok && !used[name] && (name == itemFileName(i, false) || name == itemFileName(i, true)) {
+			names[idx] = name
+			used[name] = true
+		}
+	}
+
+	for idx, i := range items {
+		if names[idx] != "" {
+			continue
+		}
+
+		name := itemFileName(i, false)
+		if used[name] {
+			name = itemFileName(i, true)
+		}
+
+		names[idx] = name
+		used[name] = true
+	}
+
+	return names
+}
+
+// storedNames returns the names of the items stored in a group's directory, keyed by their UUIDs. Items that cannot be
+// read are left out.
+func (s *Storage) storedNames(path string) map[uuid.UUID]string {
+	stored := map[uuid.UUID]string{}
+
+	names, err := s.itemNames(path, nil)
+	if err != nil {
+		return stored
+	}
+
+	for _, name := range names {
+		file := filepath.Join(path, name)
+		if !strings.HasSuffix(name, ".json") {
+			file = filepath.Join(file, groupFileName)
+		}
+
+		var item struct {
+			UUID uuid.UUID
+		}
+
+		if err := readJSON(file, &item); err == nil {
+			stored[item.UUID] = name
+		}
+	}
+
+	return stored
+}
+
+func itemFileName(item *state.CollectionItem, unique bool) string {
+	name := slug(item.Name)
+	if unique {
+		name = fmt.Sprintf("%s-%s", name, item.UUID.String()[:8])
+	}
+
+	if item.IsGroup {
+		return name
+	}
+
+	return name + ".json"
+}
+
+// slug returns a lowercase form of the name containing only letters, digits and dashes.
+func slug(name string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteRune('-')
+			dash = true
+		}
+	}
+
+	s := strings.TrimSuffix(b.String(), "-")
+	if s == "" {
+		return "item"
+	}
+
+	return s
+}
+
+func readJSON(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return errors.Wrapf(err, "invalid workspace file %s", path)
+	}
+
+	return nil
+}
+
+// writeJSON writes v as indented JSON, leaving the file untouched if its contents would not change.
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	data = append(data, '\n')
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return nil
+	}
+
+	return system.WriteFileAtomic(path, data, 0644)
+}
@@ -0,0 +1,208 @@
+package workspace
+
+import (
+	"github.com/mbpolan/lull/internal/state"
+	"github.com/mbpolan/lull/internal/state/auth"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestState() *state.AppState {
+	root := state.NewCollectionGroup("API", nil)
+	root.Authentication = state.ItemAuthentication{Data: auth.NewBearerAuthentication("token")}
+
+	users := state.NewCollectionGroup("Users", root)
+	users.InheritHeaders = true
+	users.AddHeader("Accept", "application/json")
+	root.AddChild(users)
+
+	list := state.NewCollectionRequest("List users", "GET", "https://example.com/users", users)
+	list.Authentication = state.ItemAuthentication{Data: auth.NewInheritedAuthentication()}
+	users.AddChild(list)
+
+	create := state.NewCollectionRequest("Create user", "POST", "https://example.com/users", users)
+	create.RequestBody = &state.RequestBody{Payload: `{"name": "bob"}`, ContentType: "application/json"}
+	users.AddChild(create)
+
+	// two requests with the same name
+	root.AddChild(state.NewCollectionRequest("Health", "GET", "https://example.com/health", root))
+	root.AddChild(state.NewCollectionRequest("Health", "GET", "https://example.com/ready", root))
+
+	return &state.AppState{Collection: root}
+}
+
+func Test_Storage_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir, t.TempDir())
+	original := newTestState()
+
+	assert.False(t, s.Exists())
+	assert.NoError(t, s.Save(original))
+	assert.True(t, s.Exists())
+	assert.FileExists(t, filepath.Join(dir, "users", "list-users.json"))
+
+	loaded, err := s.Load()
+	assert.NoError(t, err)
+
+	root := loaded.Collection
+	assert.Equal(t, original.Collection.UUID, root.UUID)
+	assert.Equal(t, original.Collection.Authentication, root.Authentication)
+	assert.Len(t, root.Children, 3)
+
+	users := root.Children[0]
+	assert.Equal(t, "Users", users.Name)
+	assert.True(t, users.InheritHeaders)
+	assert.Equal(t, map[string][]string{"Accept": {"application/json"}}, users.Headers)
+	assert.Equal(t, root, users.Parent)
+	assert.Equal(t, []string{"List users", "Create user"}, []string{users.Children[0].Name, users.Children[1].Name})
+	assert.True(t, users.Children[0].Authentication.Inherited())
+	assert.Equal(t, original.Collection.Children[0].Children[1].RequestBody, users.Children[1].RequestBody)
+
+	assert.Equal(t, "https://example.com/health", root.Children[1].URL)
+	assert.Equal(t, "https://example.com/ready", root.Children[2].URL)
+	assert.Equal(t, users.Children[0], loaded.ActiveItem)
+}
+
+func Test_Storage_SaveIsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir, t.TempDir())
+	st := newTestState()
+
+	assert.NoError(t, s.Save(st))
+	first, err := os.ReadFile(filepath.Join(dir, "users", "_group.json"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Save(st))
+	second, err := os.ReadFile(filepath.Join(dir, "users", "_group.json"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, string(first), string(second))
+	assert.NotContains(t, string(first), "SelectedItem")
+}
+
+func Test_Storage_RemovesStaleItems(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir, t.TempDir())
+	st := newTestState()
+	assert.NoError(t, s.Save(st))
+
+	// keep unrelated files around
+	readme := filepath.Join(dir, "users", "README.md")
+	assert.NoError(t, os.WriteFile(readme, []byte("docs"), 0644))
+
+	users := st.Collection.Children[0]
+	_ = users.RemoveChild(users.Children[1])
+	_ = st.Collection.RemoveChild(st.Collection.Children[0])
+	assert.NoError(t, s.Save(st))
+
+	assert.NoFileExists(t, filepath.Join(dir, "users", "list-users.json"))
+	assert.NoFileExists(t, filepath.Join(dir, "users", "_group.json"))
+	assert.FileExists(t, readme)
+}
+
+func Test_Storage_StableNames(t *testing.T) {
+	dir := t.TempDir()
+	s := NewStorage(dir, t.TempDir())
+	st := state.NewAppState()
+	first := state.NewCollectionRequest("Health", "GET", "https://example.com/health", st.Collection)
+	st.Collection.AddChild(first)
+	assert.NoError(t, s.Save(st))
+
+	// a request with the same name is added, which must not rename the existing file
+	second := state.NewCollectionRequest("Health", "GET", "https://example.com/ready", st.Collection)
+	st.Collection.AddChild(second)
+	assert.NoError(t, s.Save(st))
+
+	var stored struct {
+		URL string
+	}
+
+	assert.NoError(t, readJSON(filepath.Join(dir, "health.json"), &stored))
+	assert.Equal(t, "https://example.com/health", stored.URL)
+	assert.FileExists(t, filepath.Join(dir, "health-"+second.UUID.String()[:8]+".json"))
+}
+
+func Test_Storage_Layout(t *testing.T) {
+	dir, local := t.TempDir(), t.TempDir()
+	s := NewStorage(dir, local)
+	original := newTestState()
+	original.Layout.SetCollapsed(original.Collection.Children[0].UUID, true)
+	original.Layout.RequestPage = "headers"
+	original.Layout.CollectionWidth = 40
+
+	assert.NoError(t, s.Save(original))
+	assert.FileExists(t, filepath.Join(local, layoutFileName))
+	assert.NoFileExists(t, filepath.Join(dir, layoutFileName))
+	assert.NoFileExists(t, filepath.Join(dir, legacyLayoutFileName))
+
+	loaded, err := s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, original.Layout, loaded.Layout)
+	assert.Len(t, loaded.Collection.Children, 3)
+}
+
+func Test_Storage_LegacyLayout(t *testing.T) {
+	dir, local := t.TempDir(), t.TempDir()
+	s := NewStorage(dir, local)
+	assert.NoError(t, s.Save(newTestState()))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, legacyLayoutFileName), []byte(`{"RequestPage":"docs"}`), 0644))
+	assert.NoError(t, os.Remove(filepath.Join(local, layoutFileName)))
+
+	loaded, err := s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "docs", loaded.Layout.RequestPage)
+
+	// the layout moves out of the workspace directory once saved
+	assert.NoError(t, s.Save(loaded))
+	assert.NoFileExists(t, filepath.Join(dir, legacyLayoutFileName))
+	assert.FileExists(t, filepath.Join(local, layoutFileName))
+}
+
+func Test_Storage_LockOutsideWorkspace(t *testing.T) {
+	dir, local := t.TempDir(), filepath.Join(t.TempDir(), "local")
+	s := NewStorage(dir, local)
+
+	lock := state.NewLock(s.LockPath())
+	assert.NoError(t, lock.Acquire())
+	assert.FileExists(t, filepath.Join(local, lockFileName))
+	assert.NoError(t, lock.Release())
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func Test_Storage_MigratesOlderVersion(t *testing.T) {
+	dir, local := t.TempDir(), t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, groupFileName), []byte(`{"Name":"Old","Items":[]}`), 0644))
+
+	s := NewStorage(dir, local)
+	a, err := s.Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "Old", a.Collection.Name)
+	assert.FileExists(t, filepath.Join(local, backupDirName+"-v0", groupFileName))
+
+	assert.NoError(t, s.Save(a))
+	version, err := schemaVersionOf(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
+}
+
+func Test_Storage_NewerVersion(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, groupFileName)
+	data := []byte(`{"Version":999,"Name":"New","Items":[]}`)
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+
+	s := NewStorage(dir, t.TempDir())
+	_, err := s.Load()
+	assert.IsType(t, &state.NewerVersionError{}, err)
+
+	err = s.Save(state.NewAppState())
+	assert.IsType(t, &state.NewerVersionError{}, err)
+
+	saved, _ := os.ReadFile(path)
+	assert.Equal(t, data, saved)
+}